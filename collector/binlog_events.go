@@ -0,0 +1,190 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW BINLOG EVENTS` of the current binlog file.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	masterStatusQuery = `SHOW MASTER STATUS`
+	// binaryLogStatusQuery replaces masterStatusQuery from MySQL 8.2.
+	binaryLogStatusQuery = `SHOW BINARY LOG STATUS`
+	// binlogEventsQuery lists the first %d events of the binlog file %s.
+	binlogEventsQuery = `SHOW BINLOG EVENTS IN %s LIMIT %d`
+)
+
+var binlogEventsLimit = kingpin.Flag(
+	"collect.binlog_events.limit",
+	"Maximum number of events read from the start of the current binlog file.",
+).Default("10000").Int()
+
+// Metric descriptors.
+var (
+	binlogEventsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, binlog, "events_total"),
+		"Number of events by type in the current binlog file, up to collect.binlog_events.limit events. Resets when the binlog is rotated.",
+		[]string{"event_type"}, nil,
+	)
+)
+
+// quoteString quotes s as a MySQL string literal.
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
+
+// ScrapeBinlogEvents collects the types of the events of the current binlog
+// file.
+type ScrapeBinlogEvents struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeBinlogEvents) Name() string {
+	return "binlog_events"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeBinlogEvents) Help() string {
+	return "Collect the number of events by type in the current binlog file from SHOW BINLOG EVENTS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeBinlogEvents) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeBinlogEvents) Stability() string {
+	return StabilityExperimental
+}
+
+// ValidateConfig checks the event limit.
+func (ScrapeBinlogEvents) ValidateConfig() error {
+	if *binlogEventsLimit <= 0 {
+		err := fmt.Errorf("collect.binlog_events.limit must be positive, got %d", *binlogEventsLimit)
+		return newConfigError("limit", ReasonOutOfRange, err)
+	}
+	return nil
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (s ScrapeBinlogEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if err := s.ValidateConfig(); err != nil {
+		return newScrapeError(ErrConfig, err)
+	}
+
+	var logBin uint8
+	if err := db.QueryRowContext(ctx, logbinQuery).Scan(&logBin); err != nil {
+		return wrapDriverError(err)
+	}
+	if logBin == 0 {
+		level.Debug(logger).Log("msg", "Binary logging is disabled")
+		return nil
+	}
+
+	file, err := currentBinlogFile(ctx, db)
+	if err != nil {
+		return err
+	}
+	if file == "" {
+		return nil
+	}
+
+	eventRows, err := db.QueryContext(ctx, fmt.Sprintf(binlogEventsQuery, quoteString(file), *binlogEventsLimit))
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer eventRows.Close()
+
+	columns, err := eventRows.Columns()
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	eventType := columnIndex(columns, "Event_type")
+	if eventType == -1 {
+		return newScrapeError(ErrParse, fmt.Errorf("no Event_type column in %v", columns))
+	}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+
+	counts := map[string]float64{}
+	for rows := 0; eventRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		if err := eventRows.Scan(scanArgs...); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		counts[string(*scanArgs[eventType].(*sql.RawBytes))]++
+	}
+	if err := eventRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		ch <- prometheus.MustNewConstMetric(binlogEventsDesc, prometheus.CounterValue, counts[t], t)
+	}
+	return nil
+}
+
+// currentBinlogFile returns the binlog file the server currently writes to,
+// or "" if it reports none.
+func currentBinlogFile(ctx context.Context, db *sql.DB) (string, error) {
+	statusRows, err := db.QueryContext(ctx, masterStatusQuery)
+	if err != nil {
+		var statusErr error
+		if statusRows, statusErr = db.QueryContext(ctx, binaryLogStatusQuery); statusErr != nil {
+			return "", wrapDriverError(err)
+		}
+	}
+	defer statusRows.Close()
+
+	columns, err := statusRows.Columns()
+	if err != nil {
+		return "", wrapDriverError(err)
+	}
+	scanArgs := make([]interface{}, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	var file string
+	if statusRows.Next() {
+		if err := statusRows.Scan(scanArgs...); err != nil {
+			return "", newScrapeError(ErrParse, err)
+		}
+		file = columnValue(scanArgs, columns, "File")
+	}
+	return file, wrapDriverError(statusRows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapeBinlogEvents{}
+var _ ConfigValidator = ScrapeBinlogEvents{}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeBinlogEvents(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.binlog_events.limit=100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(logbinQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@log_bin"}).AddRow(1))
+	statusColumns := []string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}
+	mock.ExpectQuery(sanitizeQuery(masterStatusQuery)).
+		WillReturnRows(sqlmock.NewRows(statusColumns).AddRow("mysql-bin.000042", "1337", "", "", ""))
+	eventColumns := []string{"Log_name", "Pos", "Event_type", "Server_id", "End_log_pos", "Info"}
+	eventRows := sqlmock.NewRows(eventColumns).
+		AddRow("mysql-bin.000042", "4", "Format_desc", "1", "126", "Server ver: 8.0.35").
+		AddRow("mysql-bin.000042", "126", "Previous_gtids", "1", "157", "").
+		AddRow("mysql-bin.000042", "157", "Gtid", "1", "236", "SET @@SESSION.GTID_NEXT= '...'").
+		AddRow("mysql-bin.000042", "236", "Query", "1", "311", "BEGIN").
+		AddRow("mysql-bin.000042", "311", "Table_map", "1", "370", "table_id: 90 (app.orders)").
+		AddRow("mysql-bin.000042", "370", "Write_rows", "1", "420", "table_id: 90 flags: STMT_END_F").
+		AddRow("mysql-bin.000042", "420", "Xid", "1", "451", "COMMIT /* xid=12 */").
+		AddRow("mysql-bin.000042", "451", "Gtid", "1", "530", "SET @@SESSION.GTID_NEXT= '...'").
+		AddRow("mysql-bin.000042", "530", "Query", "1", "605", "BEGIN").
+		AddRow("mysql-bin.000042", "605", "Table_map", "1", "664", "table_id: 90 (app.orders)").
+		AddRow("mysql-bin.000042", "664", "Update_rows", "1", "730", "table_id: 90 flags: STMT_END_F").
+		AddRow("mysql-bin.000042", "730", "Xid", "1", "761", "COMMIT /* xid=13 */")
+	mock.ExpectQuery(sanitizeQuery("SHOW BINLOG EVENTS IN 'mysql-bin.000042' LIMIT 100")).WillReturnRows(eventRows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeBinlogEvents{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	expected := []MetricResult{
+		{labels: labelMap{"event_type": "Format_desc"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Gtid"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Previous_gtids"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Query"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Table_map"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Update_rows"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Write_rows"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_type": "Xid"}, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Binlog events are counted by type", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeBinlogEventsLogBinOff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(logbinQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@log_bin"}).AddRow(0))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeBinlogEvents{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	if len(metrics) != 0 {
+		t.Errorf("expected no metrics without binary logging, got %d", len(metrics))
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+// byteSizeUnits are the suffixes accepted by parseByteSize, in powers of 1024
+// like the size options of mysqld.
+var byteSizeUnits = map[byte]int64{
+	'k': 1 << 10,
+	'm': 1 << 20,
+	'g': 1 << 30,
+	't': 1 << 40,
+}
+
+// parseByteSize parses a non-negative number of bytes with an optional K, M,
+// G or T suffix, e.g. 256M.
+func parseByteSize(s string) (int64, error) {
+	number, multiplier := s, int64(1)
+	if n := len(s); n > 0 {
+		if m, ok := byteSizeUnits[strings.ToLower(s[n-1:])[0]]; ok {
+			number, multiplier = s[:n-1], m
+		}
+	}
+	value, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid byte size %q, expected a number of bytes with an optional K, M, G or T suffix", s)
+	}
+	if value > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("byte size %q overflows int64", s)
+	}
+	return value * multiplier, nil
+}
+
+// byteSize is a kingpin.Value holding a number of bytes.
+type byteSize int64
+
+func (b *byteSize) Set(s string) error {
+	value, err := parseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = byteSize(value)
+	return nil
+}
+
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+// byteSizeFlag binds a flag accepting sizes like 256M and returns the parsed
+// number of bytes.
+func byteSizeFlag(s kingpin.Settings) *int64 {
+	target := new(int64)
+	s.SetValue((*byteSize)(target))
+	return target
+}
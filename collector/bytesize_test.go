@@ -0,0 +1,64 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseByteSize(t *testing.T) {
+	convey.Convey("Byte sizes are parsed in powers of 1024", t, func() {
+		for input, want := range map[string]int64{
+			"0":    0,
+			"512":  512,
+			"2K":   2048,
+			"2k":   2048,
+			"256M": 256 << 20,
+			"3G":   3 << 30,
+			"1T":   1 << 40,
+		} {
+			got, err := parseByteSize(input)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldEqual, want)
+		}
+	})
+
+	convey.Convey("Invalid byte sizes are rejected", t, func() {
+		for _, input := range []string{"", "M", "-1K", "1.5G", "12X", "10 M", "9999999999T"} {
+			_, err := parseByteSize(input)
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestByteSizeFlag(t *testing.T) {
+	app := kingpin.New("test", "")
+	size := byteSizeFlag(app.Flag("size", "").Default("256M"))
+
+	convey.Convey("The flag default and value are parsed", t, func() {
+		_, err := app.Parse([]string{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(*size, convey.ShouldEqual, 256<<20)
+
+		_, err = app.Parse([]string{"--size=1G"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(*size, convey.ShouldEqual, 1<<30)
+
+		_, err = app.Parse([]string{"--size=lots"})
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
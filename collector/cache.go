@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parseCacheTTLs parses "<collector>=<duration>" entries into the time the
+// metrics of each collector are cached for.
+func parseCacheTTLs(entries []string) (map[string]time.Duration, error) {
+	return parseCollectorDurations("cache ttl", entries)
+}
+
+// parseMinIntervals parses "<collector>=<duration>" entries into the time
+// that has to pass between two scrapes of each collector.
+func parseMinIntervals(entries []string) (map[string]time.Duration, error) {
+	return parseCollectorDurations("min interval", entries)
+}
+
+func parseCollectorDurations(kind string, entries []string) (map[string]time.Duration, error) {
+	durations := map[string]time.Duration{}
+	for _, entry := range entries {
+		collector, value, ok := strings.Cut(entry, "=")
+		if !ok || collector == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected <collector>=<duration>", kind, entry)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected <collector>=<duration>", kind, entry)
+		}
+		durations[collector] = d
+	}
+	return durations, nil
+}
+
+// cacheNow returns the current time, replaced in tests.
+var cacheNow = time.Now
+
+// cachedMetrics are the metrics of the last successful scrape, and the
+// outcome of the last scrape.
+type cachedMetrics struct {
+	metrics []prometheus.Metric
+	expires time.Time
+	lastRun time.Time
+	err     error
+}
+
+// scrapeCache holds the metrics of cached collectors by target and collector.
+// Exporters are created per request, so the cache outlives them.
+var scrapeCache = struct {
+	sync.Mutex
+	entries map[string]cachedMetrics
+}{entries: map[string]cachedMetrics{}}
+
+// cachedScrape sends the metrics cached for key to ch if they are younger
+// than ttl. Otherwise it calls scrape and caches its metrics if it succeeds.
+// Within minInterval of the last call of scrape, scrape is not called again
+// even if it failed: the last metrics, or the last error, are replayed.
+// Concurrent misses for the same key each call scrape.
+func cachedScrape(key string, ttl, minInterval time.Duration, ch chan<- prometheus.Metric, scrape func(chan<- prometheus.Metric) error) error {
+	scrapeCache.Lock()
+	cached, ok := scrapeCache.entries[key]
+	scrapeCache.Unlock()
+	now := cacheNow()
+	if ok && (now.Before(cached.expires) || now.Before(cached.lastRun.Add(minInterval))) {
+		if cached.err != nil {
+			return cached.err
+		}
+		for _, m := range cached.metrics {
+			ch <- m
+		}
+		return nil
+	}
+
+	var metrics []prometheus.Metric
+	buffered := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for m := range buffered {
+			metrics = append(metrics, m)
+			ch <- m
+		}
+		close(done)
+	}()
+	err := scrape(buffered)
+	close(buffered)
+	<-done
+
+	entry := cachedMetrics{metrics: metrics, expires: now.Add(ttl), lastRun: now}
+	if err != nil {
+		// Failed scrapes are only remembered to hold back the next scrape.
+		if minInterval <= 0 {
+			return err
+		}
+		entry = cachedMetrics{lastRun: now, err: err}
+	}
+	scrapeCache.Lock()
+	scrapeCache.entries[key] = entry
+	scrapeCache.Unlock()
+	return err
+}
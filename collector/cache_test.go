@@ -0,0 +1,184 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseCacheTTLs(t *testing.T) {
+	convey.Convey("Cache ttls are parsed", t, func() {
+		ttls, err := parseCacheTTLs([]string{"info_schema.tables=30s", "heartbeat=0s"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(ttls, convey.ShouldResemble, map[string]time.Duration{
+			"info_schema.tables": 30 * time.Second,
+			"heartbeat":          0,
+		})
+
+		for _, entry := range []string{"info_schema.tables", "=30s", "info_schema.tables=soon", "info_schema.tables=-1s"} {
+			_, err := parseCacheTTLs([]string{entry})
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+// countingScraper counts its scrapes and sends the count as a metric.
+type countingScraper struct {
+	fakeScraper
+	scrapes *int
+}
+
+var countingScraperDesc = prometheus.NewDesc("mysql_counting_scrapes", "Number of scrapes.", nil, nil)
+
+func (s countingScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	*s.scrapes++
+	if s.err != nil {
+		return s.err
+	}
+	ch <- prometheus.MustNewConstMetric(countingScraperDesc, prometheus.GaugeValue, float64(*s.scrapes))
+	return nil
+}
+
+func TestCachedScraper(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.cache_ttl=counting=1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	now := time.Unix(1000, 0)
+	cacheNow = func() time.Time { return now }
+	defer func() { cacheNow = time.Now }()
+
+	scrapeCache.Lock()
+	scrapeCache.entries = map[string]cachedMetrics{}
+	scrapeCache.Unlock()
+
+	scrapes := 0
+	scraper := countingScraper{fakeScraper: fakeScraper{name: "counting"}, scrapes: &scrapes}
+	scrape := func(dsn string, scraper Scraper) ([]float64, error) {
+		e := New(context.Background(), dsn, []Scraper{scraper}, log.NewNopLogger())
+		ch := make(chan prometheus.Metric)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- e.cachedScraper(context.Background(), scraper, nil, ch, log.NewNopLogger())
+			close(ch)
+		}()
+		var values []float64
+		for m := range ch {
+			values = append(values, readMetric(m).value)
+		}
+		return values, <-errCh
+	}
+
+	convey.Convey("Metrics are replayed within the ttl", t, func() {
+		values, err := scrape("cache_test_a", scraper)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(values, convey.ShouldResemble, []float64{1})
+
+		now = now.Add(59 * time.Second)
+		values, err = scrape("cache_test_a", scraper)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(values, convey.ShouldResemble, []float64{1})
+		convey.So(scrapes, convey.ShouldEqual, 1)
+
+		// Another target has its own cache.
+		values, err = scrape("cache_test_b", scraper)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(values, convey.ShouldResemble, []float64{2})
+
+		now = now.Add(time.Second)
+		values, err = scrape("cache_test_a", scraper)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(values, convey.ShouldResemble, []float64{3})
+	})
+
+	convey.Convey("Failed scrapes are not cached", t, func() {
+		scrapes = 0
+		failing := countingScraper{fakeScraper: fakeScraper{name: "counting", err: errors.New("failed")}, scrapes: &scrapes}
+		for i := 0; i < 2; i++ {
+			_, err := scrape("cache_test_c", failing)
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+		convey.So(scrapes, convey.ShouldEqual, 2)
+	})
+}
+
+func TestMinIntervalScraper(t *testing.T) {
+	cacheTTLs := *exporterCacheTTL
+	*exporterCacheTTL = nil
+	*exporterMinInterval = []string{"counting=1m"}
+	defer func() {
+		*exporterCacheTTL = cacheTTLs
+		*exporterMinInterval = nil
+	}()
+
+	now := time.Unix(1000, 0)
+	cacheNow = func() time.Time { return now }
+	defer func() { cacheNow = time.Now }()
+
+	scrapeCache.Lock()
+	scrapeCache.entries = map[string]cachedMetrics{}
+	scrapeCache.Unlock()
+
+	scrapes := 0
+	scrape := func(dsn string, scraper Scraper) ([]float64, error) {
+		e := New(context.Background(), dsn, []Scraper{scraper}, log.NewNopLogger())
+		ch := make(chan prometheus.Metric)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- e.cachedScraper(context.Background(), scraper, nil, ch, log.NewNopLogger())
+			close(ch)
+		}()
+		var values []float64
+		for m := range ch {
+			values = append(values, readMetric(m).value)
+		}
+		return values, <-errCh
+	}
+
+	convey.Convey("Rapid scrapes only run the scraper once", t, func() {
+		scraper := countingScraper{fakeScraper: fakeScraper{name: "counting"}, scrapes: &scrapes}
+		for i := 0; i < 2; i++ {
+			values, err := scrape("min_interval_test_a", scraper)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(values, convey.ShouldResemble, []float64{1})
+		}
+		convey.So(scrapes, convey.ShouldEqual, 1)
+
+		now = now.Add(time.Minute)
+		values, err := scrape("min_interval_test_a", scraper)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(values, convey.ShouldResemble, []float64{2})
+	})
+
+	convey.Convey("Failed scrapes are not retried within the interval", t, func() {
+		scrapes = 0
+		failing := countingScraper{fakeScraper: fakeScraper{name: "counting", err: errors.New("failed")}, scrapes: &scrapes}
+		for i := 0; i < 2; i++ {
+			_, err := scrape("min_interval_test_b", failing)
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+		convey.So(scrapes, convey.ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+// Tunable flags.
+var (
+	exporterCircuitBreakerFailures = kingpin.Flag(
+		"exporter.circuit_breaker_failures",
+		"Number of consecutive failed scrapes after which a collector is skipped for exporter.circuit_breaker_cooldown, 0 never skips collectors.",
+	).Default("0").Int()
+	exporterCircuitBreakerCooldown = kingpin.Flag(
+		"exporter.circuit_breaker_cooldown",
+		"Time a collector is skipped after exporter.circuit_breaker_failures consecutive failed scrapes.",
+	).Default("5m").Duration()
+)
+
+// circuitBreaker is the failure state of a scraper of a target.
+type circuitBreaker struct {
+	failures int
+	// openUntil is the end of the cooldown, zero while the circuit is
+	// closed.
+	openUntil time.Time
+	// probing is set while the single run after the cooldown is in flight.
+	probing bool
+}
+
+// circuitBreakers holds the state of exporter.circuit_breaker_failures by
+// target and scraper, which outlives the Exporters created per request.
+var circuitBreakers = struct {
+	sync.Mutex
+	entries map[string]*circuitBreaker
+}{entries: map[string]*circuitBreaker{}}
+
+// circuitAllow reports whether the scraper of key may run at now. Once the
+// cooldown is over, a single run is let through to probe the scraper; until
+// it is recorded with circuitRecord, further runs are skipped.
+func circuitAllow(key string, now time.Time) bool {
+	if *exporterCircuitBreakerFailures <= 0 {
+		return true
+	}
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+	b, ok := circuitBreakers.entries[key]
+	if !ok || b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// circuitRecord records the outcome of a run of the scraper of key and
+// returns whether its circuit is open. A success closes the circuit. The
+// circuit opens after exporter.circuit_breaker_failures consecutive failures,
+// and again right away if the probe after the cooldown fails.
+func circuitRecord(key string, failed bool, now time.Time) bool {
+	threshold := *exporterCircuitBreakerFailures
+	if threshold <= 0 {
+		return false
+	}
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+	if !failed {
+		delete(circuitBreakers.entries, key)
+		return false
+	}
+	b, ok := circuitBreakers.entries[key]
+	if !ok {
+		b = &circuitBreaker{}
+		circuitBreakers.entries[key] = b
+	}
+	b.failures++
+	if b.probing || b.failures >= threshold {
+		b.openUntil = now.Add(*exporterCircuitBreakerCooldown)
+		b.probing = false
+		return true
+	}
+	return false
+}
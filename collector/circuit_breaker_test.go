@@ -0,0 +1,156 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// flakyScraper is a Scraper that counts its runs and fails while failing is
+// set.
+type flakyScraper struct {
+	runs    *int32
+	failing *atomic.Value
+}
+
+func (flakyScraper) Name() string { return "flaky" }
+
+func (flakyScraper) Help() string { return "Flaky scraper" }
+
+func (flakyScraper) Version() float64 { return 5.1 }
+
+func (s flakyScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	atomic.AddInt32(s.runs, 1)
+	if s.failing.Load().(bool) {
+		return errors.New("table is gone")
+	}
+	return nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--exporter.circuit_breaker_failures=3",
+		"--exporter.circuit_breaker_cooldown=50ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	var runs int32
+	failing := &atomic.Value{}
+	failing.Store(true)
+	scraper := flakyScraper{runs: &runs, failing: failing}
+	e := New(context.Background(), "circuit", []Scraper{scraper}, log.NewNopLogger())
+	defer func() {
+		circuitBreakers.Lock()
+		delete(circuitBreakers.entries, e.key+"\xff"+scraper.Name())
+		circuitBreakers.Unlock()
+	}()
+
+	scrape := func() float64 {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			e.scrapeDB(context.Background(), db, ch)
+			close(ch)
+		}()
+		for range ch {
+		}
+		m := &dto.Metric{}
+		if err := targetMetricsOf(e.key).scraperCircuitOpen.WithLabelValues("collect.flaky").Write(m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetGauge().GetValue()
+	}
+
+	convey.Convey("Three consecutive failures open the circuit", t, func() {
+		convey.So(scrape(), convey.ShouldEqual, 0)
+		convey.So(scrape(), convey.ShouldEqual, 0)
+		convey.So(scrape(), convey.ShouldEqual, 1)
+		convey.So(atomic.LoadInt32(&runs), convey.ShouldEqual, 3)
+
+		convey.Convey("The scraper is skipped during the cooldown", func() {
+			convey.So(scrape(), convey.ShouldEqual, 1)
+			convey.So(atomic.LoadInt32(&runs), convey.ShouldEqual, 3)
+
+			convey.Convey("A failed probe after the cooldown opens the circuit again", func() {
+				time.Sleep(60 * time.Millisecond)
+				convey.So(scrape(), convey.ShouldEqual, 1)
+				convey.So(atomic.LoadInt32(&runs), convey.ShouldEqual, 4)
+				convey.So(scrape(), convey.ShouldEqual, 1)
+				convey.So(atomic.LoadInt32(&runs), convey.ShouldEqual, 4)
+
+				convey.Convey("A successful probe closes the circuit", func() {
+					failing.Store(false)
+					time.Sleep(60 * time.Millisecond)
+					convey.So(scrape(), convey.ShouldEqual, 0)
+					convey.So(scrape(), convey.ShouldEqual, 0)
+					convey.So(atomic.LoadInt32(&runs), convey.ShouldEqual, 6)
+				})
+			})
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--exporter.circuit_breaker_failures=1",
+		"--exporter.circuit_breaker_cooldown=1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	const key = "probe\xffflaky"
+	defer func() {
+		circuitBreakers.Lock()
+		delete(circuitBreakers.entries, key)
+		circuitBreakers.Unlock()
+	}()
+
+	now := time.Now()
+	convey.Convey("Only a single run probes the scraper after the cooldown", t, func() {
+		convey.So(circuitRecord(key, true, now), convey.ShouldBeTrue)
+		convey.So(circuitAllow(key, now.Add(time.Second)), convey.ShouldBeFalse)
+		convey.So(circuitAllow(key, now.Add(time.Minute)), convey.ShouldBeTrue)
+		convey.So(circuitAllow(key, now.Add(time.Minute)), convey.ShouldBeFalse)
+		convey.So(circuitRecord(key, false, now.Add(time.Minute)), convey.ShouldBeFalse)
+		convey.So(circuitAllow(key, now.Add(time.Minute)), convey.ShouldBeTrue)
+	})
+}
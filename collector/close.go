@@ -0,0 +1,53 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by scrapes after Close.
+var ErrClosed = errors.New("collector is closed")
+
+// closed is set by Close.
+var closed = struct {
+	sync.RWMutex
+	closed bool
+}{}
+
+func isClosed() bool {
+	closed.RLock()
+	defer closed.RUnlock()
+	return closed.closed
+}
+
+// Close releases the resources kept across scrapes, which outlive the
+// Exporters created per request: the pools and keep-warm loops of
+// exporter.keepalive_interval, the statements of exporter.prepared_statements
+// and the metrics of exporter.cache_ttl. Scrapes after Close fail with
+// ErrClosed.
+func Close() error {
+	closed.Lock()
+	closed.closed = true
+	closed.Unlock()
+
+	CloseWarmDBs()
+	err := preparedStatements.Close()
+
+	scrapeCache.Lock()
+	scrapeCache.entries = map[string]cachedMetrics{}
+	scrapeCache.Unlock()
+	return err
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestClose(t *testing.T) {
+	defer func() {
+		closed.Lock()
+		closed.closed = false
+		closed.Unlock()
+	}()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	parseStmtCacheFlags(t, true)
+	defer parseStmtCacheFlags(t, false)
+	mock.ExpectPrepare(sanitizeQuery(versionQuery)).WillBeClosed()
+	if _, err := preparedStatements.prepare(context.Background(), db, versionQuery); err != nil {
+		t.Fatal(err)
+	}
+	err = cachedScrape("close", time.Minute, 0, make(chan prometheus.Metric), func(chan<- prometheus.Metric) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(context.Background(), "close", nil, log.NewNopLogger())
+	convey.Convey("Close releases the resources kept across scrapes", t, func() {
+		convey.So(Close(), convey.ShouldBeNil)
+		convey.So(preparedStatements.stmts, convey.ShouldBeEmpty)
+		convey.So(scrapeCache.entries, convey.ShouldBeEmpty)
+
+		convey.Convey("Scrapes fail with ErrClosed", func() {
+			_, err := e.connectAndScrape(context.Background(), make(chan prometheus.Metric))
+			convey.So(err, convey.ShouldEqual, ErrClosed)
+			convey.So(collectUp(e), convey.ShouldEqual, 0)
+
+			_, err = openWarmDB("close", time.Minute, log.NewNopLogger())
+			convey.So(err, convey.ShouldEqual, ErrClosed)
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
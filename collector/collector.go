@@ -15,12 +15,17 @@ package collector
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"errors"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -29,6 +34,8 @@ const (
 	namespace = "mysql"
 	// Math constant for picoseconds to seconds.
 	picoSeconds = 1e12
+	// Number of rows between context checks in row loops.
+	ctxCheckInterval = 100
 	// Query to check whether user/table/client stats are enabled.
 	userstatCheckQuery = `SHOW GLOBAL VARIABLES WHERE Variable_Name='userstat'
 		OR Variable_Name='userstat_running'`
@@ -36,6 +43,42 @@ const (
 
 var logRE = regexp.MustCompile(`.+\.(\d+)$`)
 
+var (
+	invalidMetricNameRE   = regexp.MustCompile(`[^a-z0-9_]+`)
+	repeatedUnderscoresRE = regexp.MustCompile(`__+`)
+	invalidMetricCharRE   = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// validPrometheusName turns a name returned by the server into a metric name
+// by replacing every invalid character with an underscore and lowercasing
+// it. The global status and variables and innodb_metrics scrapers keep
+// using it, so that the names of their existing series do not change.
+func validPrometheusName(s string) string {
+	return strings.ToLower(invalidMetricCharRE.ReplaceAllString(s, "_"))
+}
+
+// sanitizeMetricName turns a name returned by the server, e.g. of a status
+// variable, into a valid metric name for new scrapers. It is lowercased,
+// invalid characters and repeated underscores are replaced by a single
+// underscore, and a leading digit is prefixed with an underscore.
+func sanitizeMetricName(s string) string {
+	s = invalidMetricNameRE.ReplaceAllString(strings.ToLower(s), "_")
+	s = repeatedUnderscoresRE.ReplaceAllString(s, "_")
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "_" + s
+	}
+	return s
+}
+
+// quoteIdent quotes a database, table or column name for use in a query
+// according to exporter.identifier_quoting, doubling embedded quotes.
+func quoteIdent(s string) string {
+	if *exporterIdentifierQuoting == "ansi" {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
 func newDesc(subsystem, name, help string) *prometheus.Desc {
 	return prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, subsystem, name),
@@ -43,12 +86,83 @@ func newDesc(subsystem, name, help string) *prometheus.Desc {
 	)
 }
 
-func parseStatus(data sql.RawBytes) (float64, bool) {
-	dataString := strings.ToLower(string(data))
-	switch dataString {
-	case "yes", "on":
+// checkCtx returns the context error every ctxCheckInterval rows, so that
+// loops over many rows stop soon after the scrape is cancelled.
+func checkCtx(ctx context.Context, row int) error {
+	if row%ctxCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// errNoScalarRow is returned by queryScalar when the query returned no row
+// without NULL values.
+var errNoScalarRow = errors.New("query returned no row without NULL values")
+
+// queryScalar scans the first row of query without NULL values into dest,
+// skipping rows with NULL values, and closes the rows. It returns
+// errNoScalarRow if there is no such row.
+func queryScalar(ctx context.Context, db *sql.DB, query string, dest ...interface{}) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(dest))
+	for i := range values {
+		values[i] = new(interface{})
+	}
+	for rows.Next() {
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		null := false
+		for _, v := range values {
+			if *v.(*interface{}) == nil {
+				null = true
+			}
+		}
+		if null {
+			continue
+		}
+		return rows.Scan(dest...)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return errNoScalarRow
+}
+
+// queryScalarFloat returns the single value of the first row of query
+// without NULL values.
+func queryScalarFloat(ctx context.Context, db *sql.DB, query string) (float64, error) {
+	var value float64
+	err := queryScalar(ctx, db, query, &value)
+	return value, err
+}
+
+// rowLimitReached reports whether row, counted from 0, is beyond
+// exporter.max_rows. Collectors stop reading rows once it returns true, it
+// then logs a warning and sends mysql_exporter_scrape_truncated for the
+// collector.
+func rowLimitReached(row int, collector string, ch chan<- prometheus.Metric, logger log.Logger) bool {
+	maxRows := *exporterMaxRows
+	if maxRows <= 0 || row < maxRows {
+		return false
+	}
+	level.Warn(logger).Log("msg", "Truncating scrape after exporter.max_rows rows", "max_rows", maxRows)
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeTruncated, prometheus.GaugeValue, 1, "collect."+collector)
+	return true
+}
+
+// parseBoolMetric maps the boolean strings of MySQL status fields and
+// variables, case-insensitively, to 1 or 0. It returns false for other values.
+func parseBoolMetric(s string) (float64, bool) {
+	switch strings.ToLower(s) {
+	case "yes", "on", "1":
 		return 1, true
-	case "no", "off", "disabled":
+	case "no", "off", "disabled", "0":
 		return 0, true
 	// SHOW SLAVE STATUS Slave_IO_Running can return "Connecting" which is a non-running state.
 	case "connecting":
@@ -59,6 +173,13 @@ func parseStatus(data sql.RawBytes) (float64, bool) {
 	case "non-primary", "disconnected":
 		return 0, true
 	}
+	return 0, false
+}
+
+func parseStatus(data sql.RawBytes) (float64, bool) {
+	if value, ok := parseBoolMetric(string(data)); ok {
+		return value, true
+	}
 	if ts, err := time.Parse("Jan 02 15:04:05 2006 MST", string(data)); err == nil {
 		return float64(ts.Unix()), true
 	}
@@ -73,6 +194,17 @@ func parseStatus(data sql.RawBytes) (float64, bool) {
 	return value, err == nil
 }
 
+// withServerTimestamp stamps m with a time of the server, in seconds since the
+// epoch, when exporter.use_server_timestamps is set. Otherwise Prometheus
+// uses the time of the scrape.
+func withServerTimestamp(m prometheus.Metric, seconds float64) prometheus.Metric {
+	if !*exporterServerTimestamps {
+		return m
+	}
+	sec, frac := math.Modf(seconds)
+	return prometheus.NewMetricWithTimestamp(time.Unix(int64(sec), int64(frac*1e9)), m)
+}
+
 func parsePrivilege(data sql.RawBytes) (float64, bool) {
 	if bytes.Equal(data, []byte("Y")) {
 		return 1, true
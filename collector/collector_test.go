@@ -14,10 +14,17 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"strings"
+	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/smartystreets/goconvey/convey"
 )
 
 type labelMap map[string]string
@@ -54,3 +61,146 @@ func sanitizeQuery(q string) string {
 	q = strings.Replace(q, "*", "\\*", -1)
 	return q
 }
+
+func TestParseBoolMetric(t *testing.T) {
+	convey.Convey("Boolean strings are mapped to 1 and 0", t, func() {
+		for _, tt := range []struct {
+			in    string
+			value float64
+			ok    bool
+		}{
+			{"Yes", 1, true},
+			{"NO", 0, true},
+			{"on", 1, true},
+			{"Off", 0, true},
+			{"1", 1, true},
+			{"0", 0, true},
+			{"Connecting", 0, true},
+			{"Primary", 1, true},
+			{"non-Primary", 0, true},
+			{"maybe", 0, false},
+			{"2", 0, false},
+			{"", 0, false},
+		} {
+			value, ok := parseBoolMetric(tt.in)
+			convey.So(ok, convey.ShouldEqual, tt.ok)
+			convey.So(value, convey.ShouldEqual, tt.value)
+		}
+	})
+
+	convey.Convey("parseStatus falls back to numbers", t, func() {
+		value, ok := parseStatus([]byte("ON"))
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(value, convey.ShouldEqual, 1)
+		value, ok = parseStatus([]byte("42"))
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(value, convey.ShouldEqual, 42)
+	})
+}
+
+func TestSanitizeMetricName(t *testing.T) {
+	convey.Convey("Names from the server become valid metric names", t, func() {
+		for _, tt := range []struct {
+			in, out string
+		}{
+			{"Com_stmt_execute", "com_stmt_execute"},
+			{"Ssl_cipher", "ssl_cipher"},
+			{"wsrep_flow_control_paused%", "wsrep_flow_control_paused_"},
+			{"Innodb_buffer_pool_pages.data", "innodb_buffer_pool_pages_data"},
+			{"cachetable: size -- current", "cachetable_size_current"},
+			{"a__b", "a_b"},
+			{"2pc_commits", "_2pc_commits"},
+			{"Täble", "t_ble"},
+		} {
+			convey.So(sanitizeMetricName(tt.in), convey.ShouldEqual, tt.out)
+			convey.So(model.IsValidMetricName(model.LabelValue("mysql_"+sanitizeMetricName(tt.in))), convey.ShouldBeTrue)
+		}
+	})
+}
+
+func TestValidPrometheusName(t *testing.T) {
+	convey.Convey("Names of existing series are kept", t, func() {
+		for _, tt := range []struct {
+			in, out string
+		}{
+			{"Com_stmt_execute", "com_stmt_execute"},
+			{"wsrep_flow_control_paused%", "wsrep_flow_control_paused_"},
+			{"cachetable: size -- current", "cachetable__size____current"},
+			{"a__b", "a__b"},
+		} {
+			convey.So(validPrometheusName(tt.in), convey.ShouldEqual, tt.out)
+		}
+	})
+}
+
+func TestQuoteIdent(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+
+	convey.Convey("Identifiers are quoted according to exporter.identifier_quoting", t, func() {
+		for _, tt := range []struct {
+			mode, in, out string
+		}{
+			{"backtick", "heartbeat", "`heartbeat`"},
+			{"backtick", "my`table", "`my``table`"},
+			{"backtick", `my"table`, "`my\"table`"},
+			{"ansi", "heartbeat", `"heartbeat"`},
+			{"ansi", `my"table`, `"my""table"`},
+			{"ansi", "my`table", "\"my`table\""},
+		} {
+			_, err := kingpin.CommandLine.Parse([]string{"--exporter.identifier_quoting=" + tt.mode})
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(quoteIdent(tt.in), convey.ShouldEqual, tt.out)
+		}
+	})
+}
+
+func TestQueryScalar(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	const query = "SELECT @@max_connections"
+	columns := []string{"@@max_connections"}
+
+	convey.Convey("One row", t, func() {
+		mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(sqlmock.NewRows(columns).AddRow("151"))
+		value, err := queryScalarFloat(context.Background(), db, query)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(value, convey.ShouldEqual, 151)
+	})
+
+	convey.Convey("No row", t, func() {
+		mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(sqlmock.NewRows(columns))
+		_, err := queryScalarFloat(context.Background(), db, query)
+		convey.So(errors.Is(err, errNoScalarRow), convey.ShouldBeTrue)
+	})
+
+	convey.Convey("Only NULL rows", t, func() {
+		mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(sqlmock.NewRows(columns).AddRow(nil))
+		_, err := queryScalarFloat(context.Background(), db, query)
+		convey.So(errors.Is(err, errNoScalarRow), convey.ShouldBeTrue)
+	})
+
+	convey.Convey("The first row without NULL values of several rows", t, func() {
+		rows := sqlmock.NewRows([]string{"name", "value"}).
+			AddRow("a", nil).
+			AddRow("b", "2").
+			AddRow("c", "3")
+		mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows).RowsWillBeClosed()
+		var (
+			name  string
+			value float64
+		)
+		err := queryScalar(context.Background(), db, query, &name, &value)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(name, convey.ShouldEqual, "b")
+		convey.So(value, convey.ShouldEqual, 2)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Reasons of ConfigErrors, for tools acting on invalid configurations.
+const (
+	// ReasonInvalid is a value that cannot be parsed or is malformed.
+	ReasonInvalid = "invalid"
+	// ReasonOutOfRange is a value outside the allowed range, e.g. negative.
+	ReasonOutOfRange = "out_of_range"
+	// ReasonConflict is a value that cannot be combined with another flag.
+	ReasonConflict = "conflict"
+	// ReasonMissing is a value that requires another setting missing.
+	ReasonMissing = "missing"
+)
+
+// ConfigError is an invalid flag reported by ValidateConfiguration. Flags of
+// scrapers are identified by the scraper and the arg below its
+// collect.<name>. prefix, exporter flags by the flag name as Arg without a
+// Scraper.
+type ConfigError struct {
+	Scraper string
+	Arg     string
+	Reason  string
+	Err     error
+}
+
+// newConfigError returns the error of arg of the scraper being validated,
+// whose name ValidateConfiguration fills in.
+func newConfigError(arg, reason string, err error) *ConfigError {
+	return &ConfigError{Arg: arg, Reason: reason, Err: err}
+}
+
+// Flag returns the name of the invalid flag, e.g.
+// collect.perf_schema.eventsstatements.limit.
+func (e *ConfigError) Flag() string {
+	if e.Scraper == "" || e.Arg == "" {
+		return e.Arg
+	}
+	return "collect." + e.Scraper + "." + e.Arg
+}
+
+func (e *ConfigError) Error() string {
+	if e.Scraper == "" {
+		return e.Err.Error()
+	}
+	return e.Scraper + ": " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON serializes the error with its flag and message.
+func (e *ConfigError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Scraper string `json:"scraper,omitempty"`
+		Arg     string `json:"arg,omitempty"`
+		Flag    string `json:"flag,omitempty"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}{e.Scraper, e.Arg, e.Flag(), e.Reason, e.Err.Error()})
+}
+
+// ConfigErrors are all errors of a configuration, returned by ValidateConfig
+// of scrapers with several invalid flags.
+type ConfigErrors []*ConfigError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// scraperConfigErrors returns the errors of ValidateConfig of a scraper as
+// ConfigErrors of the scraper. Plain errors have no arg.
+func scraperConfigErrors(scraper string, err error) []error {
+	var configErrs ConfigErrors
+	if !errors.As(err, &configErrs) {
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			configErr = newConfigError("", ReasonInvalid, err)
+		}
+		configErrs = ConfigErrors{configErr}
+	}
+	errs := make([]error, 0, len(configErrs))
+	for _, configErr := range configErrs {
+		configErr.Scraper = scraper
+		errs = append(errs, configErr)
+	}
+	return errs
+}
+
+// ConfigErrorsJSON serializes the errors of ValidateConfiguration as a JSON
+// array of objects with the scraper, arg, flag, reason and message of each.
+func ConfigErrorsJSON(errs []error) ([]byte, error) {
+	configErrs := make(ConfigErrors, 0, len(errs))
+	for _, err := range errs {
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			configErr = newConfigError("", ReasonInvalid, err)
+		}
+		configErrs = append(configErrs, configErr)
+	}
+	return json.Marshal(configErrs)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestConfigErrors(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.eventsstatements.limit=-1",
+		"--collect.perf_schema.eventsstatements.digest_text_limit=-1",
+		"--collect.binlog_events.limit=0",
+		"--collect.heartbeat.recency_window=500ms",
+		"--exporter.scrape_retries=-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := ValidateConfiguration([]Scraper{ScrapeBinlogEvents{}, ScrapeHeartbeat{}, ScrapePerfEventsStatements{}})
+
+	convey.Convey("All invalid flags are collected with their flags and reasons", t, func() {
+		type field struct{ scraper, arg, flag, reason string }
+		var fields []field
+		for _, err := range errs {
+			var configErr *ConfigError
+			convey.So(errors.As(err, &configErr), convey.ShouldBeTrue)
+			fields = append(fields, field{configErr.Scraper, configErr.Arg, configErr.Flag(), configErr.Reason})
+		}
+		convey.So(fields, convey.ShouldResemble, []field{
+			{"", "exporter.scrape_retries", "exporter.scrape_retries", ReasonOutOfRange},
+			{"binlog_events", "limit", "collect.binlog_events.limit", ReasonOutOfRange},
+			{"heartbeat", "recency_window", "collect.heartbeat.recency_window", ReasonOutOfRange},
+			{"perf_schema.eventsstatements", "limit", "collect.perf_schema.eventsstatements.limit", ReasonOutOfRange},
+			{"perf_schema.eventsstatements", "digest_text_limit", "collect.perf_schema.eventsstatements.digest_text_limit", ReasonOutOfRange},
+		})
+		convey.So(errorClass(errs[2]), convey.ShouldEqual, ErrConfig)
+	})
+
+	convey.Convey("The errors serialize to JSON", t, func() {
+		out, err := ConfigErrorsJSON(errs[:2])
+		convey.So(err, convey.ShouldBeNil)
+		var decoded []map[string]string
+		convey.So(json.Unmarshal(out, &decoded), convey.ShouldBeNil)
+		convey.So(decoded, convey.ShouldResemble, []map[string]string{
+			{
+				"arg":     "exporter.scrape_retries",
+				"flag":    "exporter.scrape_retries",
+				"reason":  ReasonOutOfRange,
+				"message": "exporter.scrape_retries must not be negative, got -1",
+			},
+			{
+				"scraper": "binlog_events",
+				"arg":     "limit",
+				"flag":    "collect.binlog_events.limit",
+				"reason":  ReasonOutOfRange,
+				"message": "collect.binlog_events.limit must be positive, got 0",
+			},
+		})
+
+		out, err = ConfigErrorsJSON(nil)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(out), convey.ShouldEqual, "[]")
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// maxDeltaSeries bounds the number of series whose previous value is
+	// kept, further series get no delta.
+	maxDeltaSeries = 10000
+	// deltaStaleAfter is how long the previous value of a series that is
+	// not scraped anymore is kept.
+	deltaStaleAfter = time.Hour
+)
+
+// deltaNow returns the current time, replaced in tests.
+var deltaNow = time.Now
+
+// deltaSeries is the value of a series in the previous scrape.
+type deltaSeries struct {
+	value float64
+	seen  time.Time
+}
+
+// deltaState keeps the previous value of delta-tracked series by target,
+// scraper, name and label values. Exporters are created per request, so the
+// state outlives them.
+var deltaState = struct {
+	sync.Mutex
+	series map[string]deltaSeries
+}{series: map[string]deltaSeries{}}
+
+// metricValue returns the value of a counter, gauge or untyped metric.
+func metricValue(pb *dto.Metric) (float64, bool) {
+	switch {
+	case pb.Counter != nil:
+		return pb.Counter.GetValue(), true
+	case pb.Gauge != nil:
+		return pb.Gauge.GetValue(), true
+	case pb.Untyped != nil:
+		return pb.Untyped.GetValue(), true
+	}
+	return 0, false
+}
+
+// addDeltaMetrics reads metrics from in until it is closed and sends them to
+// out. For metrics of the tracked descriptors it also sends a
+// <name>_per_scrape_delta gauge with the change since the previous scrape of
+// the same series, from the second scrape of the series on.
+func addDeltaMetrics(in <-chan prometheus.Metric, out chan<- prometheus.Metric, key string, tracked []*prometheus.Desc) {
+	deltaDescs := make(map[*prometheus.Desc]*prometheus.Desc, len(tracked))
+	for _, desc := range tracked {
+		deltaDescs[desc] = nil
+	}
+
+	now := deltaNow()
+	for m := range in {
+		out <- m
+		deltaDesc, ok := deltaDescs[m.Desc()]
+		if !ok {
+			continue
+		}
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+		value, ok := metricValue(pb)
+		if !ok {
+			continue
+		}
+		fqName, help, err := descNameHelp(m.Desc())
+		if err != nil {
+			continue
+		}
+		names := make([]string, 0, len(pb.GetLabel()))
+		values := make([]string, 0, len(pb.GetLabel()))
+		for _, lp := range pb.GetLabel() {
+			names = append(names, lp.GetName())
+			values = append(values, lp.GetValue())
+		}
+		if deltaDesc == nil {
+			deltaDesc = prometheus.NewDesc(fqName+"_per_scrape_delta", "Change since the previous scrape of: "+help, names, nil)
+			deltaDescs[m.Desc()] = deltaDesc
+		}
+
+		seriesKey := key + "\xff" + fqName + "\xff" + strings.Join(values, "\xff")
+		deltaState.Lock()
+		previous, seen := deltaState.series[seriesKey]
+		if seen || len(deltaState.series) < maxDeltaSeries {
+			deltaState.series[seriesKey] = deltaSeries{value: value, seen: now}
+		}
+		deltaState.Unlock()
+		if seen {
+			out <- prometheus.MustNewConstMetric(deltaDesc, prometheus.GaugeValue, value-previous.value, values...)
+		}
+	}
+
+	deltaState.Lock()
+	for seriesKey, series := range deltaState.series {
+		if now.Sub(series.seen) > deltaStaleAfter {
+			delete(deltaState.series, seriesKey)
+		}
+	}
+	deltaState.Unlock()
+}
+
+// deltaMetrics returns a channel whose metrics are sent to ch together with
+// the deltas of the tracked descriptors, and a function closing it that
+// returns once all metrics were forwarded.
+func deltaMetrics(ch chan<- prometheus.Metric, key string, tracked []*prometheus.Desc) (chan<- prometheus.Metric, func()) {
+	in := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		addDeltaMetrics(in, ch, key, tracked)
+		close(done)
+	}()
+	return in, func() {
+		close(in)
+		<-done
+	}
+}
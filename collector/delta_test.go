@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeltaMetrics(t *testing.T) {
+	now := time.Unix(1000, 0)
+	deltaNow = func() time.Time { return now }
+	defer func() { deltaNow = time.Now }()
+
+	deltaState.Lock()
+	deltaState.series = map[string]deltaSeries{}
+	deltaState.Unlock()
+
+	tracked := prometheus.NewDesc("mysql_test_total", "Test counter.", []string{"server_id"}, nil)
+	other := prometheus.NewDesc("mysql_test_other", "Untracked gauge.", nil, nil)
+	scrape := func(values map[string]float64) map[string]MetricResult {
+		out := make(chan prometheus.Metric)
+		got := map[string]MetricResult{}
+		done := make(chan struct{})
+		go func() {
+			for m := range out {
+				name, _, err := descNameHelp(m.Desc())
+				if err != nil {
+					t.Error(err)
+				}
+				r := readMetric(m)
+				got[name+"/"+r.labels["server_id"]] = r
+			}
+			close(done)
+		}()
+		ch, closeDeltas := deltaMetrics(out, "delta_test", []*prometheus.Desc{tracked})
+		for serverID, value := range values {
+			ch <- prometheus.MustNewConstMetric(tracked, prometheus.CounterValue, value, serverID)
+		}
+		ch <- prometheus.MustNewConstMetric(other, prometheus.GaugeValue, 1)
+		closeDeltas()
+		close(out)
+		<-done
+		return got
+	}
+
+	convey.Convey("Deltas are exported from the second scrape on", t, func() {
+		got := scrape(map[string]float64{"1": 10})
+		convey.So(got, convey.ShouldHaveLength, 2)
+
+		now = now.Add(time.Minute)
+		got = scrape(map[string]float64{"1": 25, "2": 3})
+		convey.So(got, convey.ShouldHaveLength, 4)
+		convey.So(got["mysql_test_total_per_scrape_delta/1"], convey.ShouldResemble,
+			MetricResult{labels: labelMap{"server_id": "1"}, value: 15, metricType: dto.MetricType_GAUGE})
+		convey.So(got, convey.ShouldNotContainKey, "mysql_test_total_per_scrape_delta/2")
+		convey.So(got, convey.ShouldNotContainKey, "mysql_test_other_per_scrape_delta/")
+	})
+
+	convey.Convey("Series not scraped anymore are evicted", t, func() {
+		now = now.Add(deltaStaleAfter + time.Minute)
+		scrape(map[string]float64{"1": 30})
+		deltaState.Lock()
+		defer deltaState.Unlock()
+		convey.So(deltaState.series, convey.ShouldHaveLength, 1)
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DSNProvider returns the DSN of the server to scrape. Exporters created
+// with NewWithDSNProvider call it before every scrape, so that providers can
+// e.g. regenerate expiring IAM auth tokens or pick up rotated secrets.
+type DSNProvider interface {
+	DSN(ctx context.Context) (string, error)
+}
+
+// StaticDSN is a DSNProvider always returning the same DSN.
+type StaticDSN string
+
+// DSN implements DSNProvider.
+func (d StaticDSN) DSN(context.Context) (string, error) {
+	return string(d), nil
+}
+
+// mysqlDriver opens the connections of scrapes, it is replaced in tests.
+var mysqlDriver driver.Driver = &mysql.MySQLDriver{}
+
+// addDSNParams adds the session settings of the exporter flags to dsn,
+// default to having a lock timeout.
+func addDSNParams(dsn string) string {
+	dsnParams := []string{fmt.Sprintf(timeoutParam, *exporterLockTimeout)}
+
+	if *exporterConnectTimeout > 0 {
+		dsnParams = append(dsnParams, fmt.Sprintf(connectTimeoutParam, *exporterConnectTimeout))
+	}
+	if *slowLogFilter {
+		dsnParams = append(dsnParams, sessionSettingsParam)
+	}
+	if *exporterSessionTimeZone != "" {
+		dsnParams = append(dsnParams, fmt.Sprintf(timeZoneParam, url.QueryEscape("'"+*exporterSessionTimeZone+"'")))
+	}
+	if *exporterCharset != "" {
+		dsnParams = append(dsnParams, fmt.Sprintf(charsetParam, url.QueryEscape(*exporterCharset)))
+	}
+
+	if strings.Contains(dsn, "?") {
+		dsn = dsn + "&"
+	} else {
+		dsn = dsn + "?"
+	}
+	return dsn + strings.Join(dsnParams, "&")
+}
+
+// dsnKey identifies the server of dsn in caches and shared scrapes. It
+// leaves out the password, which changes with every rotated token.
+func dsnKey(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return dsn
+	}
+	cfg.Passwd = ""
+	return cfg.FormatDSN()
+}
+
+// scrapeTargetKey is the context key of the key of the scraped target.
+type scrapeTargetKey struct{}
+
+// withScrapeTarget returns a context of a scrape of the target identified by
+// key, the key of the Exporter.
+func withScrapeTarget(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, scrapeTargetKey{}, key)
+}
+
+// scrapeTarget returns the key of the target scraped with ctx, so that
+// scrapers keeping state across scrapes keep it per target, or "" outside of
+// an Exporter.
+func scrapeTarget(ctx context.Context) string {
+	key, _ := ctx.Value(scrapeTargetKey{}).(string)
+	return key
+}
+
+// withProviderDSN returns a copy of the exporter connecting to the current
+// DSN of its DSNProvider, if it has one.
+func (e *Exporter) withProviderDSN(ctx context.Context) (*Exporter, error) {
+	if e.dsnProvider == nil {
+		return e, nil
+	}
+	dsn, err := e.dsnProvider.DSN(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving dsn: %w", err)
+	}
+	resolved := *e
+	resolved.dsn = addDSNParams(dsn)
+	resolved.key = dsnKey(resolved.dsn)
+	return &resolved, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// rotatingDSNProvider returns its DSNs in turn, like a provider generating a
+// new auth token for every scrape.
+type rotatingDSNProvider struct {
+	dsns  []string
+	calls int
+}
+
+func (p *rotatingDSNProvider) DSN(context.Context) (string, error) {
+	if p.calls >= len(p.dsns) {
+		return "", errors.New("no more tokens")
+	}
+	dsn := p.dsns[p.calls]
+	p.calls++
+	return dsn, nil
+}
+
+// collectUp runs a collection of e and returns its mysql_up value.
+func collectUp(e *Exporter) float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		e.Collect(ch)
+		close(ch)
+	}()
+	up := -1.0
+	for m := range ch {
+		if m.Desc() == mysqlUp {
+			up = readMetric(m).value
+		}
+	}
+	return up
+}
+
+func TestNewWithDSNProvider(t *testing.T) {
+	provider := &rotatingDSNProvider{dsns: []string{"dsn_provider_token1", "dsn_provider_token2"}}
+	var mocks []sqlmock.Sqlmock
+	for _, dsn := range provider.dsns {
+		// The exporter connects with the session settings added to the DSN.
+		mockDB, mock, err := sqlmock.NewWithDSN(addDSNParams(dsn))
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer mockDB.Close()
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		mocks = append(mocks, mock)
+		mysqlDriver = mockDB.Driver()
+	}
+	defer func() { mysqlDriver = &mysql.MySQLDriver{} }()
+
+	e := NewWithDSNProvider(context.Background(), provider, nil, log.NewNopLogger())
+	convey.Convey("Every scrape connects with the current DSN", t, func() {
+		convey.So(collectUp(e), convey.ShouldEqual, 1)
+		convey.So(collectUp(e), convey.ShouldEqual, 1)
+		convey.So(provider.calls, convey.ShouldEqual, 2)
+		for _, mock := range mocks {
+			convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+		}
+	})
+
+	convey.Convey("The target is down without a DSN", t, func() {
+		convey.So(collectUp(e), convey.ShouldEqual, 0)
+	})
+}
+
+func TestNewWithDB(t *testing.T) {
+	parseStmtCacheFlags(t, false)
+	defer parseStmtCacheFlags(t, false)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	mock.ExpectQuery(sanitizeQuery(stmtCacheHeartbeatQuery)).WillReturnRows(heartbeatRows())
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewWithDB(context.Background(), db, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger()))
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			values[family.GetName()] = m.GetGauge().GetValue()
+		}
+	}
+
+	convey.Convey("The exporter scrapes the injected pool and leaves it open", t, func() {
+		convey.So(values["mysql_up"], convey.ShouldEqual, 1)
+		convey.So(values, convey.ShouldContainKey, "mysql_heartbeat_stored_timestamp_seconds")
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+		convey.So(db.PingContext(context.Background()), convey.ShouldBeNil)
+	})
+}
+
+func TestDSNKey(t *testing.T) {
+	convey.Convey("The key leaves out the password", t, func() {
+		convey.So(dsnKey("user:token1@tcp(db1:3306)/"), convey.ShouldEqual, dsnKey("user:token2@tcp(db1:3306)/"))
+		convey.So(dsnKey("user:token1@tcp(db1:3306)/"), convey.ShouldNotEqual, dsnKey("user:token1@tcp(db2:3306)/"))
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,6 +34,29 @@ const (
 	engineInnodbStatusQuery = `SHOW ENGINE INNODB STATUS`
 )
 
+// innodbDeadlockTimeLayouts are the formats of the time a deadlock was
+// detected, used by 5.6 and later and by 5.5.
+var innodbDeadlockTimeLayouts = []struct {
+	re     *regexp.Regexp
+	layout string
+}{
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`), "2006-01-02 15:04:05"},
+	{regexp.MustCompile(`^\d{6} [ \d]\d:\d{2}:\d{2}`), "060102 15:04:05"},
+}
+
+// parseInnodbDeadlockTime parses the first line of the LATEST DETECTED
+// DEADLOCK section. The time is in the time zone of the server, which is
+// assumed to match the one of the exporter.
+func parseInnodbDeadlockTime(line string) (time.Time, bool) {
+	for _, l := range innodbDeadlockTimeLayouts {
+		if match := l.re.FindString(line); match != "" {
+			t, err := time.ParseInLocation(l.layout, strings.Replace(match, "  ", " 0", 1), time.Local)
+			return t, err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
 // ScrapeEngineInnodbStatus scrapes from `SHOW ENGINE INNODB STATUS`.
 type ScrapeEngineInnodbStatus struct{}
 
@@ -72,7 +96,24 @@ func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<
 	rQueries, _ := regexp.Compile(`(\d+) queries inside InnoDB, (\d+) queries in queue`)
 	rViews, _ := regexp.Compile(`(\d+) read views open inside InnoDB`)
 
+	// The section is only present once a deadlock was detected.
+	inDeadlock := false
 	for _, line := range strings.Split(statusCol, "\n") {
+		if strings.TrimSpace(line) == "LATEST DETECTED DEADLOCK" {
+			inDeadlock = true
+			continue
+		}
+		if inDeadlock && !strings.HasPrefix(line, "---") {
+			inDeadlock = false
+			if t, ok := parseInnodbDeadlockTime(line); ok {
+				ch <- prometheus.MustNewConstMetric(
+					newDesc(innodb, "deadlock_timestamp_seconds", "Time of the latest deadlock detected by InnoDB."),
+					prometheus.GaugeValue,
+					float64(t.Unix()),
+				)
+			}
+			continue
+		}
 		if data := rQueries.FindStringSubmatch(line); data != nil {
 			value, _ := strconv.ParseFloat(data[1], 64)
 			ch <- prometheus.MustNewConstMetric(
@@ -0,0 +1,122 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW ENGINE INNODB MUTEX`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const engineInnodbMutexQuery = `SHOW ENGINE INNODB MUTEX`
+
+// Tunable flags.
+var (
+	innodbMutexMinWaits = kingpin.Flag(
+		"collect.engine_innodb_mutex.min_waits",
+		"Skip mutexes with fewer OS waits to limit cardinality.",
+	).Default("0").Uint64()
+)
+
+// innodbMutexOSWaitsRE matches the Status column, e.g. "os_waits=12".
+var innodbMutexOSWaitsRE = regexp.MustCompile(`^os_waits=(\d+)$`)
+
+// Metric descriptors.
+var (
+	innodbMutexOSWaitsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "mutex_os_waits"),
+		"Number of times a thread waited on an InnoDB mutex or rw-lock through the operating system.",
+		[]string{"name"}, nil,
+	)
+)
+
+// ScrapeEngineInnodbMutex scrapes from `SHOW ENGINE INNODB MUTEX`.
+type ScrapeEngineInnodbMutex struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeEngineInnodbMutex) Name() string {
+	return "engine_innodb_mutex"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeEngineInnodbMutex) Help() string {
+	return "Collect OS waits by mutex from SHOW ENGINE INNODB MUTEX"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeEngineInnodbMutex) Version() float64 {
+	return 5.5
+}
+
+// Stability of the Scraper.
+func (ScrapeEngineInnodbMutex) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeEngineInnodbMutex) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, engineInnodbMutexQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Instances of a mutex, e.g. one per buffer pool, share a name.
+	var (
+		names   []string
+		osWaits = map[string]uint64{}
+	)
+	for rows.Next() {
+		var typeCol, nameCol, statusCol string
+		if err := rows.Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return err
+		}
+		match := innodbMutexOSWaitsRE.FindStringSubmatch(statusCol)
+		if match == nil {
+			level.Debug(logger).Log("msg", "Skipping InnoDB mutex with unknown status", "name", nameCol, "status", statusCol)
+			continue
+		}
+		waits, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			level.Debug(logger).Log("msg", "Skipping InnoDB mutex with unparsable os_waits", "name", nameCol, "status", statusCol)
+			continue
+		}
+		if _, ok := osWaits[nameCol]; !ok {
+			names = append(names, nameCol)
+		}
+		osWaits[nameCol] += waits
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if osWaits[name] < *innodbMutexMinWaits {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(innodbMutexOSWaitsDesc, prometheus.CounterValue, float64(osWaits[name]), name)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeEngineInnodbMutex{}
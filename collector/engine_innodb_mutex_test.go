@@ -0,0 +1,76 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeEngineInnodbMutex(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.engine_innodb_mutex.min_waits=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Type", "Name", "Status"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("InnoDB", "&buf_pool->mutex", "os_waits=3").
+		AddRow("InnoDB", "&log_sys->mutex", "os_waits=1").
+		AddRow("InnoDB", "&buf_pool->mutex", "os_waits=4").
+		AddRow("InnoDB", "rwlock: dict0dict.cc:1184", "waits=7").
+		AddRow("InnoDB", "&dict_sys->mutex", "os_waits=").
+		AddRow("InnoDB", "&trx_sys->mutex", "os_waits=2")
+	mock.ExpectQuery(sanitizeQuery(engineInnodbMutexQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeEngineInnodbMutex{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"name": "&buf_pool->mutex"}, value: 7, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"name": "&trx_sys->mutex"}, value: 2, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -16,6 +16,7 @@ package collector
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-kit/log"
@@ -178,3 +179,70 @@ END OF INNODB MONITOR OUTPUT
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeEngineInnodbStatusDeadlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	sample := `
+=====================================
+2023-05-10 12:40:02 0x7f1c2c0a1700 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2023-05-10 12:34:56 0x7f1c2c0a1700
+*** (1) TRANSACTION:
+TRANSACTION 5405, ACTIVE 12 sec starting index read
+mysql tables in use 1, locked 1
+*** (2) TRANSACTION:
+TRANSACTION 5406, ACTIVE 8 sec starting index read
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 5410
+	`
+	columns := []string{"Type", "Name", "Status"}
+	rows := sqlmock.NewRows(columns).AddRow("InnoDB", "", sample)
+	mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeEngineInnodbStatus{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	convey.Convey("The time of the latest deadlock is collected", t, func() {
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: float64(time.Date(2023, 5, 10, 12, 34, 56, 0, time.Local).Unix()), metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestParseInnodbDeadlockTime(t *testing.T) {
+	convey.Convey("Deadlock times of all versions are parsed", t, func() {
+		for line, expected := range map[string]time.Time{
+			"2023-05-10 12:34:56 0x7f1c2c0a1700": time.Date(2023, 5, 10, 12, 34, 56, 0, time.Local),
+			"2014-03-11 15:34:51 7f0c6c0b4700":   time.Date(2014, 3, 11, 15, 34, 51, 0, time.Local),
+			"130815  9:25:11":                    time.Date(2013, 8, 15, 9, 25, 11, 0, time.Local),
+			"130815 13:25:11":                    time.Date(2013, 8, 15, 13, 25, 11, 0, time.Local),
+		} {
+			got, ok := parseInnodbDeadlockTime(line)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(got.Equal(expected), convey.ShouldBeTrue)
+		}
+		_, ok := parseInnodbDeadlockTime("*** (1) TRANSACTION:")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
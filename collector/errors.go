@@ -0,0 +1,156 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+
+	MySQL "github.com/go-sql-driver/mysql"
+)
+
+// ErrorClass tells apart scrape errors that are worth retrying from those
+// that need to be fixed.
+type ErrorClass int
+
+const (
+	// ErrQuery is a query rejected or failed by the server.
+	ErrQuery ErrorClass = iota
+	// ErrConnection is a transient connection problem.
+	ErrConnection
+	// ErrParse is a result that could not be converted to a metric.
+	ErrParse
+	// ErrConfig is a problem with the exporter or server configuration,
+	// e.g. missing grants or a wrong table name.
+	ErrConfig
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrQuery:
+		return "query"
+	case ErrConnection:
+		return "connection"
+	case ErrParse:
+		return "parse"
+	case ErrConfig:
+		return "config"
+	}
+	return "ErrorClass(" + strconv.Itoa(int(c)) + ")"
+}
+
+// ScrapeError is an error returned by a Scraper together with its class.
+type ScrapeError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ScrapeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// newScrapeError wraps err with the given class, nil stays nil.
+func newScrapeError(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ScrapeError{Class: class, Err: err}
+}
+
+// MySQL error codes classified as configuration errors.
+var mysqlConfigErrors = map[uint16]bool{
+	1044: true, // ER_DBACCESS_DENIED_ERROR
+	1045: true, // ER_ACCESS_DENIED_ERROR
+	1049: true, // ER_BAD_DB_ERROR
+	1142: true, // ER_TABLEACCESS_DENIED_ERROR
+	1143: true, // ER_COLUMNACCESS_DENIED_ERROR
+	1146: true, // ER_NO_SUCH_TABLE
+	1227: true, // ER_SPECIFIC_ACCESS_DENIED_ERROR
+}
+
+// Grants likely missing for the MySQL access denied error codes.
+var mysqlPermissionErrors = map[uint16]string{
+	1045: "a valid user and password for the exporter host",         // ER_ACCESS_DENIED_ERROR
+	1142: "SELECT on the tables read by the collector",              // ER_TABLEACCESS_DENIED_ERROR
+	1227: "the privilege named in the error, e.g. PROCESS or SUPER", // ER_SPECIFIC_ACCESS_DENIED_ERROR
+}
+
+// permissionDenied reports whether err is an access denied error and returns
+// the grant that is likely needed.
+func permissionDenied(err error) (string, bool) {
+	var mysqlErr *MySQL.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return "", false
+	}
+	grant, ok := mysqlPermissionErrors[mysqlErr.Number]
+	return grant, ok
+}
+
+// MySQL error codes classified as connection errors.
+var mysqlConnectionErrors = map[uint16]bool{
+	1040: true, // ER_CON_COUNT_ERROR
+	1053: true, // ER_SERVER_SHUTDOWN
+	1152: true, // ER_ABORTING_CONNECTION
+	1159: true, // ER_NET_READ_INTERRUPTED
+	1161: true, // ER_NET_WRITE_INTERRUPTED
+	1927: true, // ER_CONNECTION_KILLED
+}
+
+// wrapDriverError classifies an error returned by database/sql or the MySQL
+// driver. Errors that are already a ScrapeError are returned unchanged.
+func wrapDriverError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var scrapeErr *ScrapeError
+	if errors.As(err, &scrapeErr) {
+		return err
+	}
+	return newScrapeError(classifyDriverError(err), err)
+}
+
+func classifyDriverError(err error) ErrorClass {
+	var mysqlErr *MySQL.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch {
+		case mysqlConfigErrors[mysqlErr.Number]:
+			return ErrConfig
+		case mysqlConnectionErrors[mysqlErr.Number]:
+			return ErrConnection
+		}
+		return ErrQuery
+	}
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, MySQL.ErrInvalidConn) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return ErrConnection
+	}
+	return ErrQuery
+}
+
+// errorClass returns the class of an error returned by a Scraper.
+func errorClass(err error) ErrorClass {
+	var scrapeErr *ScrapeError
+	if errors.As(err, &scrapeErr) {
+		return scrapeErr.Class
+	}
+	return classifyDriverError(err)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestWrapDriverError(t *testing.T) {
+	convey.Convey("Driver errors are classified", t, func() {
+		convey.So(wrapDriverError(nil), convey.ShouldBeNil)
+
+		for _, tt := range []struct {
+			err   error
+			class ErrorClass
+		}{
+			{&MySQL.MySQLError{Number: 1045, Message: "Access denied"}, ErrConfig},
+			{&MySQL.MySQLError{Number: 1146, Message: "Table doesn't exist"}, ErrConfig},
+			{&MySQL.MySQLError{Number: 1040, Message: "Too many connections"}, ErrConnection},
+			{&MySQL.MySQLError{Number: 1064, Message: "You have an error in your SQL syntax"}, ErrQuery},
+			{driver.ErrBadConn, ErrConnection},
+			{fmt.Errorf("ping: %w", MySQL.ErrInvalidConn), ErrConnection},
+			{context.DeadlineExceeded, ErrConnection},
+			{errors.New("something else"), ErrQuery},
+		} {
+			err := wrapDriverError(tt.err)
+			var scrapeErr *ScrapeError
+			convey.So(errors.As(err, &scrapeErr), convey.ShouldBeTrue)
+			convey.So(scrapeErr.Class, convey.ShouldEqual, tt.class)
+			convey.So(errors.Is(err, tt.err), convey.ShouldBeTrue)
+			convey.So(err.Error(), convey.ShouldEqual, tt.err.Error())
+		}
+
+		parseErr := newScrapeError(ErrParse, errors.New("bad float"))
+		convey.So(wrapDriverError(parseErr), convey.ShouldEqual, parseErr)
+	})
+}
+
+func TestScrapeHeartbeatParseError(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", "not a server_id")
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger())
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Unscannable rows are parse errors", t, func() {
+		var scrapeErr *ScrapeError
+		convey.So(errors.As(err, &scrapeErr), convey.ShouldBeTrue)
+		convey.So(scrapeErr.Class, convey.ShouldEqual, ErrParse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeDBCountsErrorsByClass(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	exporter := New(
+		context.Background(),
+		dsn,
+		[]Scraper{
+			fakeScraper{name: "denied", err: &MySQL.MySQLError{Number: 1142, Message: "SELECT command denied"}},
+			fakeScraper{name: "parse", err: newScrapeError(ErrParse, errors.New("bad value"))},
+		},
+		log.NewNopLogger(),
+	)
+	deniedBefore := testutil.ToFloat64(targetMetricsOf(exporter.key).scrapeErrors.WithLabelValues("collect.denied", "config"))
+	parseBefore := testutil.ToFloat64(targetMetricsOf(exporter.key).scrapeErrors.WithLabelValues("collect.parse", "parse"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Scraper errors are counted by class", t, func() {
+		convey.So(testutil.ToFloat64(targetMetricsOf(exporter.key).scrapeErrors.WithLabelValues("collect.denied", "config")), convey.ShouldEqual, deniedBefore+1)
+		convey.So(testutil.ToFloat64(targetMetricsOf(exporter.key).scrapeErrors.WithLabelValues("collect.parse", "parse")), convey.ShouldEqual, parseBefore+1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -16,10 +16,10 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"regexp"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +44,11 @@ const (
 	// See: https://github.com/go-sql-driver/mysql#system-variables
 	sessionSettingsParam = `log_slow_filter=%27tmp_table_on_disk,filesort_on_disk%27`
 	timeoutParam         = `lock_wait_timeout=%d`
+	timeZoneParam        = `time_zone=%s`
+	// The driver uses the timeout param when dialing the server only.
+	connectTimeoutParam = `timeout=%s`
+	// The driver issues SET NAMES for the charset param.
+	charsetParam = `charset=%s`
 )
 
 var (
@@ -60,6 +65,102 @@ var (
 		"exporter.log_slow_filter",
 		"Add a log_slow_filter to avoid slow query logging of scrapes. NOTE: Not supported by Oracle MySQL.",
 	).Default("false").Bool()
+	exporterConnectTimeout = kingpin.Flag(
+		"exporter.connect_timeout",
+		"Timeout for establishing a connection to the server, independent of the scrape timeout. 0 uses the OS default.",
+	).Default("5s").Duration()
+	exporterSessionTimeZone = kingpin.Flag(
+		"exporter.session_time_zone",
+		"Set the session time_zone of every connection, e.g. '+00:00'. The server default is used when empty.",
+	).Default("").String()
+	exporterCharset = kingpin.Flag(
+		"exporter.charset",
+		"Set the character set of every connection with SET NAMES. The driver default is used when empty.",
+	).Default("").String()
+	exporterScrapeRetries = kingpin.Flag(
+		"exporter.scrape_retries",
+		"Number of times a collector is retried after a connection error.",
+	).Default("0").Int()
+	exporterScrapeRetryBackoff = kingpin.Flag(
+		"exporter.scrape_retry_backoff",
+		"Time to wait before the first retry of a collector, doubled for every further retry.",
+	).Default("100ms").Duration()
+	exporterCollectorSlowThreshold = kingpin.Flag(
+		"exporter.collector_slow_threshold",
+		"Soft deadline after which a collector is reported in mysql_exporter_scrape_slow while it keeps running, 0 disables the metric.",
+	).Default("0s").Duration()
+	exporterCollectorTimeout = kingpin.Flag(
+		"exporter.collector_timeout",
+		"Hard deadline after which a collector is cancelled, 0 leaves collectors to the scrape deadline.",
+	).Default("0s").Duration()
+	exporterMaxRows = kingpin.Flag(
+		"exporter.max_rows",
+		"Maximum number of rows a collector scanning many rows, e.g. info_schema.tables, reads per scrape, 0 reads all.",
+	).Default("0").Int()
+	exporterMaxOpenConns = kingpin.Flag(
+		"exporter.max_open_conns",
+		"Maximum number of open connections to the server per scrape.",
+	).Default("1").Int()
+	exporterMaxIdleConns = kingpin.Flag(
+		"exporter.max_idle_conns",
+		"Maximum number of idle connections per scrape.",
+	).Default("1").Int()
+	exporterConnMaxLifetime = kingpin.Flag(
+		"exporter.conn_max_lifetime",
+		"Maximum time a connection may be reused.",
+	).Default("1m").Duration()
+	exporterServerNameQuery = kingpin.Flag(
+		"exporter.server_name_query",
+		"Query returning a single string used as the server_name label of all collector metrics.",
+	).Default("").String()
+	exporterReadOnlySafe = kingpin.Flag(
+		"exporter.read_only_safe",
+		"Skip collectors that may write to the server or change its state, e.g. on read-only replicas.",
+	).Default("false").Bool()
+	exporterIncludeExperimental = kingpin.Flag(
+		"exporter.include_experimental",
+		"Run collectors whose metrics are experimental and may still change.",
+	).Default("false").Bool()
+	exporterVersionLabel = kingpin.Flag(
+		"exporter.version_label",
+		"Add the minimum server version of a collector as min_version label to its collector_success and collector_duration_seconds metrics.",
+	).Default("false").Bool()
+	exporterIdentifierQuoting = kingpin.Flag(
+		"exporter.identifier_quoting",
+		"How to quote database and table names built from flags: backtick, or ansi for double quotes on servers and proxies requiring ANSI_QUOTES.",
+	).Default("backtick").Enum("backtick", "ansi")
+	exporterQueryComment = kingpin.Flag(
+		"exporter.query_comment",
+		"Comment prepended to every query of the exporter to identify it in slow logs and proxies, e.g. '/* mysqld_exporter */'. Disabled when empty.",
+	).Default("/* mysqld_exporter */").String()
+	exporterShareScrapes = kingpin.Flag(
+		"exporter.share_concurrent_scrapes",
+		"Let concurrent collections of the same target with the same collectors share a single scrape instead of each querying the server.",
+	).Default("true").Bool()
+	exporterServerTimestamps = kingpin.Flag(
+		"exporter.use_server_timestamps",
+		"Stamp metrics that carry a time of the server, e.g. heartbeat, with that time instead of leaving the scrape time to Prometheus.",
+	).Default("false").Bool()
+	exporterCacheTTL = kingpin.Flag(
+		"exporter.cache_ttl",
+		"Cache the metrics of a collector for a duration, in the form <collector>=<duration>. Can be repeated.",
+	).Strings()
+	exporterMinInterval = kingpin.Flag(
+		"exporter.min_interval",
+		"Scrape a collector at most once per duration, in the form <collector>=<duration>, replaying its last metrics in between. Can be repeated.",
+	).Strings()
+	exporterDropLabels = kingpin.Flag(
+		"exporter.drop_labels",
+		"Drop a label from the metrics of a collector, in the form <collector>=<label>, or of all collectors with *=<label>. Series that collide are merged. Can be repeated.",
+	).Strings()
+	exporterKeepaliveInterval = kingpin.Flag(
+		"exporter.keepalive_interval",
+		"Keep the connections of each target open across scrapes and ping them at this interval, so that scrapes do not pay for connecting. 0 opens new connections for every scrape.",
+	).Default("0s").Duration()
+	exporterMaxSeries = kingpin.Flag(
+		"exporter.max_series",
+		"Maximum number of metrics of a collector per scrape, in the form <collector>=<n>. Further metrics are discarded. Can be repeated.",
+	).Strings()
 )
 
 // metric definition
@@ -81,106 +182,799 @@ var (
 		"Collector time duration.",
 		[]string{"collector"}, nil,
 	)
+	mysqlScrapeDeadlineSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "scrape_deadline_seconds"),
+		"Time left until the deadline of the scrape when it started, from the Prometheus scrape timeout minus the timeout offset.",
+		nil, nil,
+	)
+	mysqlEnabledCollectors = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "enabled_collectors"),
+		"mysqld_exporter: Collectors enabled for the scrape, always 1.",
+		[]string{"collector"}, nil,
+	)
+	mysqlScrapePermissionDenied = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "scrape_permission_denied"),
+		"mysqld_exporter: Whether the collector was denied access by the server.",
+		[]string{"collector"}, nil,
+	)
+	mysqlScrapeSlow = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "scrape_slow"),
+		"mysqld_exporter: Whether the collector ran longer than exporter.collector_slow_threshold.",
+		[]string{"collector"}, nil,
+	)
+	mysqlScrapeTruncated = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "scrape_truncated"),
+		"mysqld_exporter: Whether the collector stopped after exporter.max_rows rows.",
+		[]string{"collector"}, nil,
+	)
+	mysqlSeriesCapped = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "series_capped"),
+		"mysqld_exporter: Whether metrics of the collector were discarded after exporter.max_series metrics.",
+		[]string{"collector"}, nil,
+	)
+	mysqlDBPoolOpenConnections = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "db_pool_open_connections"),
+		"Number of open connections of the scrape connection pool after the scrape.",
+		nil, nil,
+	)
+	mysqlDBPoolInUse = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "db_pool_in_use"),
+		"Number of connections of the scrape connection pool in use after the scrape.",
+		nil, nil,
+	)
+	mysqlDBPoolIdle = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "db_pool_idle"),
+		"Number of idle connections of the scrape connection pool after the scrape.",
+		nil, nil,
+	)
+	mysqlDBPoolWaitCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "db_pool_wait_count"),
+		"Number of times collectors waited for a connection during the scrape.",
+		nil, nil,
+	)
+	mysqlDBPoolWaitDuration = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "db_pool_wait_duration_seconds"),
+		"Total time collectors waited for a connection during the scrape.",
+		nil, nil,
+	)
 )
 
+// targetMetrics are the metrics of a target kept across scrapes for the
+// lifetime of the process. Exporters are created per request, so they are
+// kept by the key of the target, and a collection only sends the metrics of
+// its own target.
+type targetMetrics struct {
+	scraperSkippedVersion    *prometheus.CounterVec
+	scrapeErrors             *prometheus.CounterVec
+	scrapeRetries            *prometheus.CounterVec
+	lastScrapeSucceeded      *prometheus.GaugeVec
+	lastScrapeErrorTimestamp *prometheus.GaugeVec
+	scraperCircuitOpen       *prometheus.GaugeVec
+	queries                  *prometheus.CounterVec
+	pingFailures             prometheus.Counter
+}
+
+func newTargetMetrics() *targetMetrics {
+	return &targetMetrics{
+		scraperSkippedVersion: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "scraper_skipped_version_total",
+				Help:      "mysqld_exporter: Number of times a collector was skipped because the server version is too old.",
+			},
+			[]string{"collector"},
+		),
+		scrapeErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "scrape_error_total",
+				Help:      "mysqld_exporter: Number of collector errors by error class.",
+			},
+			[]string{"collector", "class"},
+		),
+		scrapeRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "scrape_retries_total",
+				Help:      "mysqld_exporter: Number of times a collector was retried after a connection error.",
+			},
+			[]string{"collector"},
+		),
+		lastScrapeSucceeded: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "last_scrape_succeeded",
+				Help:      "mysqld_exporter: Whether the last run of a collector succeeded.",
+			},
+			[]string{"collector"},
+		),
+		lastScrapeErrorTimestamp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "last_scrape_error_timestamp_seconds",
+				Help:      "mysqld_exporter: Time of the last failed run of a collector.",
+			},
+			[]string{"collector"},
+		),
+		scraperCircuitOpen: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "scraper_circuit_open",
+				Help:      "mysqld_exporter: Whether a collector is skipped after repeated failures.",
+			},
+			[]string{"collector"},
+		),
+		queries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "queries_total",
+				Help:      "mysqld_exporter: Number of statements a collector sent to the server.",
+			},
+			[]string{"collector"},
+		),
+		pingFailures: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: exporter,
+				Name:      "ping_failures_total",
+				Help:      "mysqld_exporter: Number of failed pings before a scrape, including pings that succeeded when retried.",
+			},
+		),
+	}
+}
+
+// collect sends the metrics of the target.
+func (m *targetMetrics) collect(ch chan<- prometheus.Metric) {
+	m.scraperSkippedVersion.Collect(ch)
+	m.scrapeErrors.Collect(ch)
+	m.scrapeRetries.Collect(ch)
+	m.lastScrapeSucceeded.Collect(ch)
+	m.lastScrapeErrorTimestamp.Collect(ch)
+	m.scraperCircuitOpen.Collect(ch)
+	m.queries.Collect(ch)
+	m.pingFailures.Collect(ch)
+}
+
+// targetMetricsByKey holds the metrics of each target by Exporter key.
+var targetMetricsByKey = struct {
+	sync.Mutex
+	targets map[string]*targetMetrics
+}{targets: map[string]*targetMetrics{}}
+
+// targetMetricsOf returns the metrics of the target of key, created on first
+// use.
+func targetMetricsOf(key string) *targetMetrics {
+	targetMetricsByKey.Lock()
+	defer targetMetricsByKey.Unlock()
+	m, ok := targetMetricsByKey.targets[key]
+	if !ok {
+		m = newTargetMetrics()
+		targetMetricsByKey.targets[key] = m
+	}
+	return m
+}
+
 // Verify if Exporter implements prometheus.Collector
 var _ prometheus.Collector = (*Exporter)(nil)
 
+// ValidateConfiguration checks the exporter flags and the flags of scrapers
+// implementing ConfigValidator without connecting to the server. It returns
+// all errors found, each a *ConfigError naming the invalid flag.
+func ValidateConfiguration(scrapers []Scraper) []error {
+	var errs []error
+	if _, err := parseDropLabels(*exporterDropLabels); err != nil {
+		errs = append(errs, newConfigError("exporter.drop_labels", ReasonInvalid, err))
+	}
+	if _, err := parseCacheTTLs(*exporterCacheTTL); err != nil {
+		errs = append(errs, newConfigError("exporter.cache_ttl", ReasonInvalid, err))
+	}
+	if _, err := parseMinIntervals(*exporterMinInterval); err != nil {
+		errs = append(errs, newConfigError("exporter.min_interval", ReasonInvalid, err))
+	}
+	if _, err := parseMaxSeries(*exporterMaxSeries); err != nil {
+		errs = append(errs, newConfigError("exporter.max_series", ReasonInvalid, err))
+	}
+	if err := validateQueryComment(*exporterQueryComment); err != nil {
+		errs = append(errs, newConfigError("exporter.query_comment", ReasonInvalid, err))
+	}
+	if err := validateRoute(*exporterRouteTo, hostgroups); err != nil {
+		errs = append(errs, err)
+	}
+	if *exporterScrapeRetries < 0 {
+		err := fmt.Errorf("exporter.scrape_retries must not be negative, got %d", *exporterScrapeRetries)
+		errs = append(errs, newConfigError("exporter.scrape_retries", ReasonOutOfRange, err))
+	}
+	if *exporterCircuitBreakerFailures < 0 {
+		err := fmt.Errorf("exporter.circuit_breaker_failures must not be negative, got %d", *exporterCircuitBreakerFailures)
+		errs = append(errs, newConfigError("exporter.circuit_breaker_failures", ReasonOutOfRange, err))
+	}
+	if *exporterCircuitBreakerFailures > 0 && *exporterCircuitBreakerCooldown <= 0 {
+		err := fmt.Errorf("exporter.circuit_breaker_cooldown must be positive, got %s", *exporterCircuitBreakerCooldown)
+		errs = append(errs, newConfigError("exporter.circuit_breaker_cooldown", ReasonOutOfRange, err))
+	}
+	for _, scraper := range scrapers {
+		v, ok := scraper.(ConfigValidator)
+		if !ok {
+			continue
+		}
+		if err := v.ValidateConfig(); err != nil {
+			errs = append(errs, scraperConfigErrors(scraper.Name(), err)...)
+		}
+	}
+	return errs
+}
+
 // Exporter collects MySQL metrics. It implements prometheus.Collector.
 type Exporter struct {
-	ctx      context.Context
-	logger   log.Logger
-	dsn      string
-	scrapers []Scraper
+	ctx          context.Context
+	logger       log.Logger
+	dsn          string
+	key          string
+	dsnProvider  DSNProvider
+	db           *sql.DB
+	scrapers     []Scraper
+	dropLabels   map[string][]string
+	cacheTTLs    map[string]time.Duration
+	minIntervals map[string]time.Duration
+	maxSeries    map[string]int
 }
 
 // New returns a new MySQL exporter for the provided DSN.
 func New(ctx context.Context, dsn string, scrapers []Scraper, logger log.Logger) *Exporter {
-	// Setup extra params for the DSN, default to having a lock timeout.
-	dsnParams := []string{fmt.Sprintf(timeoutParam, *exporterLockTimeout)}
+	e := newExporter(ctx, scrapers, logger)
+	e.dsn = addDSNParams(dsn)
+	e.key = dsnKey(e.dsn)
+	return e
+}
 
-	if *slowLogFilter {
-		dsnParams = append(dsnParams, sessionSettingsParam)
-	}
+// NewWithDSNProvider returns a new MySQL exporter connecting to the DSN
+// returned by provider at the start of every scrape.
+func NewWithDSNProvider(ctx context.Context, provider DSNProvider, scrapers []Scraper, logger log.Logger) *Exporter {
+	e := newExporter(ctx, scrapers, logger)
+	e.dsnProvider = provider
+	return e
+}
 
-	if strings.Contains(dsn, "?") {
-		dsn = dsn + "&"
-	} else {
-		dsn = dsn + "?"
+// NewWithDB returns a new MySQL exporter scraping db, e.g. the pool of a
+// program embedding the exporter. The pool belongs to the caller: the
+// exporter neither closes it nor applies the exporter.max_open_conns and
+// similar flags, and exporter.keepalive_interval does not apply. Statements
+// are neither counted in mysql_exporter_queries_total nor prefixed with
+// exporter.query_comment, which are added by the connections the exporter
+// opens itself.
+func NewWithDB(ctx context.Context, db *sql.DB, scrapers []Scraper, logger log.Logger) *Exporter {
+	e := newExporter(ctx, scrapers, logger)
+	e.db = db
+	e.key = fmt.Sprintf("db:%p", db)
+	return e
+}
+
+// newExporter returns an exporter without DSN for the exporter flags.
+func newExporter(ctx context.Context, scrapers []Scraper, logger log.Logger) *Exporter {
+	dropLabels, err := parseDropLabels(*exporterDropLabels)
+	if err != nil {
+		level.Error(logger).Log("msg", "Ignoring labels to drop", "err", err)
+	}
+	cacheTTLs, err := parseCacheTTLs(*exporterCacheTTL)
+	if err != nil {
+		level.Error(logger).Log("msg", "Ignoring cache ttls", "err", err)
+	}
+	minIntervals, err := parseMinIntervals(*exporterMinInterval)
+	if err != nil {
+		level.Error(logger).Log("msg", "Ignoring min intervals", "err", err)
 	}
-	dsn += strings.Join(dsnParams, "&")
+	maxSeries, err := parseMaxSeries(*exporterMaxSeries)
+	if err != nil {
+		level.Error(logger).Log("msg", "Ignoring max series", "err", err)
+	}
+
+	// Run scrapers in order of priority, see scrapeDB.
+	scrapers = append([]Scraper(nil), scrapers...)
+	SortScrapers(scrapers)
 
 	return &Exporter{
-		ctx:      ctx,
-		logger:   logger,
-		dsn:      dsn,
-		scrapers: scrapers,
+		ctx:          ctx,
+		logger:       logger,
+		scrapers:     scrapers,
+		dropLabels:   dropLabels,
+		cacheTTLs:    cacheTTLs,
+		minIntervals: minIntervals,
+		maxSeries:    maxSeries,
 	}
 }
 
 // Describe implements prometheus.Collector.
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
-	ch <- mysqlUp
-	ch <- mysqlScrapeDurationSeconds
-	ch <- mysqlScrapeCollectorSuccess
+	describe := func(desc *prometheus.Desc, labels ...string) {
+		renamed, err := renameDesc(desc, labels)
+		if err != nil {
+			level.Error(e.logger).Log("msg", "Error renaming descriptor", "desc", desc, "err", err)
+			renamed = desc
+		}
+		ch <- renamed
+	}
+	describeVec := func(vec prometheus.Collector, labels ...string) {
+		descs := make(chan *prometheus.Desc, 1)
+		vec.Describe(descs)
+		close(descs)
+		for desc := range descs {
+			describe(desc, labels...)
+		}
+	}
+	describe(mysqlUp)
+	describe(mysqlScrapeDurationSeconds, "collector")
+	describe(mysqlScrapeCollectorSuccess, "collector")
+	describe(mysqlScrapeDeadlineSeconds)
+	describe(mysqlEnabledCollectors, "collector")
+	describe(mysqlScrapePermissionDenied, "collector")
+	describe(mysqlScrapeSlow, "collector")
+	describe(mysqlScrapeTruncated, "collector")
+	describe(mysqlSeriesCapped, "collector")
+	describe(mysqlDBPoolOpenConnections)
+	describe(mysqlDBPoolInUse)
+	describe(mysqlDBPoolIdle)
+	describe(mysqlDBPoolWaitCount)
+	describe(mysqlDBPoolWaitDuration)
+	m := newTargetMetrics()
+	describeVec(m.scraperSkippedVersion, "collector")
+	describeVec(m.scrapeErrors, "collector", "class")
+	describeVec(m.scrapeRetries, "collector")
+	describeVec(m.lastScrapeSucceeded, "collector")
+	describeVec(m.lastScrapeErrorTimestamp, "collector")
+	describeVec(m.scraperCircuitOpen, "collector")
+	describeVec(m.queries, "collector")
+	describeVec(m.pingFailures)
 }
 
 // Collect implements prometheus.Collector.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	// Rules see the metrics as exposed, so they are applied last.
+	if len(relabelConfigs) > 0 {
+		relabeled := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func(out chan<- prometheus.Metric) {
+			relabelMetrics(relabeled, out, relabelConfigs)
+			close(done)
+		}(ch)
+		defer func() {
+			close(relabeled)
+			<-done
+		}()
+		ch = relabeled
+	}
+	if metricNamespace != namespace {
+		renamed := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func(out chan<- prometheus.Metric) {
+			renameMetrics(renamed, out)
+			close(done)
+		}(ch)
+		defer func() {
+			close(renamed)
+			<-done
+		}()
+		ch = renamed
+	}
+	if len(constLabels) > 0 {
+		var closeLabeled func()
+		ch, closeLabeled = labelMetrics(ch, constLabels)
+		defer closeLabeled()
+	}
+	sendScrapeDeadline(e.ctx, ch)
+	sendEnabledCollectors(e.scrapers, ch)
 	up := e.scrape(e.ctx, ch)
 	ch <- prometheus.MustNewConstMetric(mysqlUp, prometheus.GaugeValue, up)
 }
 
 // scrape collects metrics from the target, returns an up metric value.
 func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) float64 {
+	if !hasScrapeHooks() {
+		up, _ := e.sharedConnectAndScrape(ctx, ch)
+		return up
+	}
+	runPreScrapeHooks(ctx)
+	up, err := e.sharedConnectAndScrape(ctx, ch)
+	runPostScrapeHooks(ctx, err)
+	return up
+}
+
+// sharedConnectAndScrape runs connectAndScrape, sharing it with concurrent
+// collections of the same target and collectors when
+// exporter.share_concurrent_scrapes is set.
+func (e *Exporter) sharedConnectAndScrape(ctx context.Context, ch chan<- prometheus.Metric) (float64, error) {
+	resolved, err := e.withProviderDSN(ctx)
+	if err != nil {
+		level.Error(e.logger).Log("msg", "Error getting DSN", "err", err)
+		return 0.0, err
+	}
+	e = resolved
+	// The key of an exporter with a DSNProvider is only known now. Every
+	// collection sends the current metrics of the target, also when it
+	// shared the scrape of another collection.
+	defer targetMetricsOf(e.key).collect(ch)
+	if !*exporterShareScrapes {
+		return e.connectAndScrape(ctx, ch)
+	}
+	key := e.key
+	for _, scraper := range e.scrapers {
+		key += "\xff" + scraper.Name()
+	}
+	up, waiters, err := sharedScrape(ctx, key, ch, e.connectAndScrape)
+	if waiters > 0 {
+		level.Debug(e.logger).Log("msg", "Shared scrape with concurrent collections", "target", e.getTargetFromDsn(), "waiters", waiters)
+	}
+	return up, err
+}
+
+// connectAndScrape opens a connection to the target and scrapes it. It returns
+// the up metric value and the aggregate error of the collection cycle.
+func (e *Exporter) connectAndScrape(ctx context.Context, ch chan<- prometheus.Metric) (float64, error) {
+	if isClosed() {
+		level.Error(e.logger).Log("msg", "Error opening connection to database", "err", ErrClosed)
+		return 0.0, ErrClosed
+	}
 	var err error
 	scrapeTime := time.Now()
-	db, err := sql.Open("mysql", e.dsn)
+	var db *sql.DB
+	switch interval := *exporterKeepaliveInterval; {
+	case e.db != nil:
+		// The pool belongs to the caller, only its statements are closed.
+		db = e.db
+	case interval > 0:
+		// The pool and its statements outlive the scrape, they are closed
+		// when the pool is replaced or evicted, or by Close.
+		db, err = openWarmDB(e.dsn, interval, e.logger)
+	default:
+		db, err = openCountingDB(mysqlDriver, e.dsn)
+	}
 	if err != nil {
 		level.Error(e.logger).Log("msg", "Error opening connection to database", "err", err)
-		return 0.0
+		return 0.0, err
 	}
-	defer db.Close()
+	if e.db == nil && *exporterKeepaliveInterval <= 0 {
+		defer db.Close()
 
-	// By design exporter should use maximum one connection per request.
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-	// Set max lifetime for a connection.
-	db.SetConnMaxLifetime(1 * time.Minute)
+		// By default exporter should use maximum one connection per request.
+		db.SetMaxOpenConns(*exporterMaxOpenConns)
+		db.SetMaxIdleConns(*exporterMaxIdleConns)
+		// Set max lifetime for a connection.
+		db.SetConnMaxLifetime(*exporterConnMaxLifetime)
+	}
+	if e.db != nil || *exporterKeepaliveInterval <= 0 {
+		defer preparedStatements.closeDB(db)
+	}
 
-	if err := db.PingContext(ctx); err != nil {
+	if err := pingDB(ctx, db, e.db == nil, targetMetricsOf(e.key).pingFailures, e.logger); err != nil {
 		level.Error(e.logger).Log("msg", "Error pinging mysqld", "err", err)
-		return 0.0
+		return 0.0, err
 	}
 
 	ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "connection")
 
+	err = e.scrapeDB(ctx, db, ch)
+	sendDBPoolStats(db.Stats(), ch)
+	return 1.0, err
+}
+
+// pingDB checks that the server is reachable before the scrapers run. After
+// a failed ping the ping is retried once, so that a connection the server
+// closed, e.g. after wait_timeout, does not fail the whole scrape. With
+// resetIdle the idle connections of the pool are discarded before the retry.
+// Pools passed to NewWithDB are not reset, as resetting them would replace
+// the idle limit of their owner with exporter.max_idle_conns. Failed pings
+// are counted in failures.
+func pingDB(ctx context.Context, db *sql.DB, resetIdle bool, failures prometheus.Counter, logger log.Logger) error {
+	err := db.PingContext(ctx)
+	if err == nil {
+		return nil
+	}
+	failures.Inc()
+	level.Debug(logger).Log("msg", "Retrying failed ping", "reset_idle", resetIdle, "err", err)
+	if resetIdle {
+		db.SetMaxIdleConns(0)
+		db.SetMaxIdleConns(*exporterMaxIdleConns)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		failures.Inc()
+		return err
+	}
+	return nil
+}
+
+// sendScrapeDeadline sends the time left until the deadline of ctx, if it
+// has one.
+func sendScrapeDeadline(ctx context.Context, ch chan<- prometheus.Metric) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeDeadlineSeconds, prometheus.GaugeValue, time.Until(deadline).Seconds())
+}
+
+// sendEnabledCollectors sends a metric for each enabled scraper, without
+// querying the server, so that differences between instances can be found.
+func sendEnabledCollectors(scrapers []Scraper, ch chan<- prometheus.Metric) {
+	for _, scraper := range scrapers {
+		ch <- prometheus.MustNewConstMetric(mysqlEnabledCollectors, prometheus.GaugeValue, 1, "collect."+scraper.Name())
+	}
+}
+
+// sendDBPoolStats sends the statistics of the connection pool of a scrape.
+func sendDBPoolStats(stats sql.DBStats, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(mysqlDBPoolOpenConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(mysqlDBPoolInUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(mysqlDBPoolIdle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(mysqlDBPoolWaitCount, prometheus.GaugeValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(mysqlDBPoolWaitDuration, prometheus.GaugeValue, stats.WaitDuration.Seconds())
+}
+
+// scrapeDB runs all scrapers supported by the server version against db.
+// Scrapers prioritized before DefaultScraperPriority run one after another
+// before the others run concurrently. Every scraper reports its success and
+// duration, even when it fails. The errors of all failed scrapers are
+// returned together.
+func (e *Exporter) scrapeDB(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	ctx = withScrapeTarget(ctx, e.key)
+	if *exporterServerNameQuery != "" {
+		var serverName string
+		if err := db.QueryRowContext(ctx, *exporterServerNameQuery).Scan(&serverName); err != nil {
+			level.Error(e.logger).Log("msg", "Error resolving server name", "target", e.getTargetFromDsn(), "err", err)
+		} else {
+			var closeLabeled func()
+			ch, closeLabeled = labelMetrics(ch, map[string]string{"server_name": serverName})
+			defer closeLabeled()
+		}
+	}
+
 	version := getMySQLVersion(db, e.logger)
-	var wg sync.WaitGroup
-	defer wg.Wait()
+	metrics := targetMetricsOf(e.key)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs scrapeErrors
+	)
 	for _, scraper := range e.scrapers {
 		if version < scraper.Version() {
+			level.Debug(e.logger).Log("msg", "Skipping scraper not supported by server version", "scraper", scraper.Name(), "version", version, "required", scraper.Version())
+			metrics.scraperSkippedVersion.WithLabelValues("collect." + scraper.Name()).Inc()
+			continue
+		}
+		if m, ok := scraper.(StateMutator); ok && *exporterReadOnlySafe && m.MutatesState() {
+			level.Debug(e.logger).Log("msg", "Skipping scraper changing server state in read only safe mode", "scraper", scraper.Name())
+			continue
+		}
+		if st, ok := scraper.(Stabilizer); ok && !*exporterIncludeExperimental && st.Stability() == StabilityExperimental {
+			level.Debug(e.logger).Log("msg", "Skipping experimental scraper", "scraper", scraper.Name())
+			continue
+		}
+		if !circuitAllow(e.key+"\xff"+scraper.Name(), time.Now()) {
+			level.Debug(e.logger).Log("msg", "Skipping scraper after repeated failures", "scraper", scraper.Name(), "cooldown", *exporterCircuitBreakerCooldown)
+			metrics.scraperCircuitOpen.WithLabelValues("collect." + scraper.Name()).Set(1)
 			continue
 		}
 
 		wg.Add(1)
-		go func(scraper Scraper) {
+		run := func(scraper Scraper) {
 			defer wg.Done()
 			label := "collect." + scraper.Name()
+			selfCh := ch
+			if *exporterVersionLabel {
+				var closeLabeled func()
+				selfCh, closeLabeled = labelMetrics(ch, map[string]string{
+					"min_version": strconv.FormatFloat(scraper.Version(), 'f', -1, 64),
+				})
+				defer closeLabeled()
+			}
 			scrapeTime := time.Now()
 			collectorSuccess := 1.0
-			if err := scraper.Scrape(ctx, db, ch, log.With(e.logger, "scraper", scraper.Name())); err != nil {
-				level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "err", err)
+			ctx := ctx
+			if timeout := *exporterCollectorTimeout; timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			ctx = withQueryCollector(ctx, label)
+			err := e.scrapeWithDropLabels(ctx, scraper, db, ch)
+			if err != nil {
+				class := errorClass(err)
+				metrics.scrapeErrors.WithLabelValues(label, class.String()).Inc()
+				metrics.lastScrapeErrorTimestamp.WithLabelValues(label).SetToCurrentTime()
 				collectorSuccess = 0.0
+				// Missing privileges only disable the collector, the scrape
+				// as a whole still succeeds.
+				if grant, ok := permissionDenied(err); ok {
+					level.Warn(e.logger).Log("msg", "Permission denied for scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "grant", grant, "err", err)
+					selfCh <- prometheus.MustNewConstMetric(mysqlScrapePermissionDenied, prometheus.GaugeValue, 1, label)
+				} else {
+					level.Error(e.logger).Log("msg", "Error from scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "class", class, "err", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("%s: %w", label, err))
+					mu.Unlock()
+				}
+			}
+			if *exporterCircuitBreakerFailures > 0 {
+				open := 0.0
+				if circuitRecord(e.key+"\xff"+scraper.Name(), collectorSuccess == 0, time.Now()) {
+					level.Warn(e.logger).Log("msg", "Skipping scraper after repeated failures", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "cooldown", *exporterCircuitBreakerCooldown)
+					open = 1
+				}
+				metrics.scraperCircuitOpen.WithLabelValues(label).Set(open)
+			}
+			if threshold := *exporterCollectorSlowThreshold; threshold > 0 {
+				slow := 0.0
+				if time.Since(scrapeTime) > threshold {
+					slow = 1
+				}
+				selfCh <- prometheus.MustNewConstMetric(mysqlScrapeSlow, prometheus.GaugeValue, slow, label)
+			}
+			metrics.lastScrapeSucceeded.WithLabelValues(label).Set(collectorSuccess)
+			selfCh <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label)
+			selfCh <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
+			runScraperHooks(e.logger, scraper.Name(), err, time.Since(scrapeTime))
+		}
+		// Scrapers are sorted by priority, so prioritized scrapers finish
+		// before any other takes a connection of the pool.
+		if scraperPriority(scraper) < DefaultScraperPriority {
+			run(scraper)
+		} else {
+			go run(scraper)
+		}
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// scrapeWithDropLabels runs the scraper, removing the labels configured in
+// exporter.drop_labels from its metrics and forwarding at most the number of
+// metrics configured in exporter.max_series.
+func (e *Exporter) scrapeWithDropLabels(ctx context.Context, scraper Scraper, db *sql.DB, ch chan<- prometheus.Metric) error {
+	logger := scraperLogger(e.logger, scraper)
+	if max := e.maxSeries[scraper.Name()]; max > 0 {
+		out := ch
+		var closeCapped func() bool
+		ch, closeCapped = capMetrics(out, max)
+		defer func() {
+			capped := 0.0
+			if closeCapped() {
+				level.Warn(logger).Log("msg", "Discarding metrics after exporter.max_series metrics", "max_series", max)
+				capped = 1
+			}
+			out <- prometheus.MustNewConstMetric(mysqlSeriesCapped, prometheus.GaugeValue, capped, "collect."+scraper.Name())
+		}()
+	}
+	if tracker, ok := scraper.(DeltaTracker); ok {
+		var closeDeltas func()
+		ch, closeDeltas = deltaMetrics(ch, e.key+"\xff"+scraper.Name(), tracker.DeltaTracked())
+		defer closeDeltas()
+	}
+	labels := e.dropLabels[scraper.Name()]
+	if all := e.dropLabels[allCollectors]; len(all) > 0 {
+		// Scrapers run concurrently, so append to a copy.
+		labels = append(all[:len(all):len(all)], labels...)
+	}
+	if len(labels) == 0 {
+		return e.cachedScraper(ctx, scraper, db, ch, logger)
+	}
+
+	scraperCh := make(chan prometheus.Metric)
+	dropErr := make(chan error)
+	go func() {
+		dropErr <- dropMetricLabels(scraperCh, ch, labels)
+	}()
+	err := e.cachedScraper(ctx, scraper, db, scraperCh, logger)
+	close(scraperCh)
+	if err := <-dropErr; err != nil {
+		level.Warn(logger).Log("msg", "Error dropping labels", "err", err)
+	}
+	return err
+}
+
+// cachedScraper runs the scraper, replaying its metrics for the duration
+// configured in exporter.cache_ttl instead if it succeeded before, or for
+// the duration configured in exporter.min_interval after any run.
+func (e *Exporter) cachedScraper(ctx context.Context, scraper Scraper, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	ttl, minInterval := e.cacheTTLs[scraper.Name()], e.minIntervals[scraper.Name()]
+	if ttl <= 0 && minInterval <= 0 {
+		return retryScraper(ctx, scraper, db, ch, logger)
+	}
+	return cachedScrape(e.key+"\xff"+scraper.Name(), ttl, minInterval, ch, func(ch chan<- prometheus.Metric) error {
+		return retryScraper(ctx, scraper, db, ch, logger)
+	})
+}
+
+// retryScraper runs the scraper, retrying it up to exporter.scrape_retries
+// times after connection errors. The metrics of failed attempts are
+// discarded, so that retries do not send duplicate metrics.
+func retryScraper(ctx context.Context, scraper Scraper, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	retries := *exporterScrapeRetries
+	if retries <= 0 {
+		return runScraper(ctx, scraper, db, ch, logger)
+	}
+
+	backoff := *exporterScrapeRetryBackoff
+	for attempt := 0; ; attempt++ {
+		var metrics []prometheus.Metric
+		buffered := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func() {
+			for m := range buffered {
+				metrics = append(metrics, m)
 			}
-			ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label)
-			ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
-		}(scraper)
+			close(done)
+		}()
+		err := runScraper(ctx, scraper, db, buffered, logger)
+		close(buffered)
+		<-done
+
+		if err == nil || attempt == retries || errorClass(err) != ErrConnection || ctx.Err() != nil {
+			for _, m := range metrics {
+				ch <- m
+			}
+			return err
+		}
+
+		level.Warn(logger).Log("msg", "Retrying scraper after connection error", "attempt", attempt+1, "backoff", backoff, "err", err)
+		targetMetricsOf(scrapeTarget(ctx)).scrapeRetries.WithLabelValues("collect." + scraper.Name()).Inc()
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
 	}
-	return 1.0
+}
+
+// runScraper runs the scraper, pinning a ConnScraper or SessionIniter to its
+// own connection.
+func runScraper(ctx context.Context, scraper Scraper, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	connScraper, pinned := scraper.(ConnScraper)
+	var init []string
+	if initer, ok := scraper.(SessionIniter); ok {
+		init = initer.SessionInit()
+	}
+	if !pinned && len(init) == 0 {
+		return scraper.Scrape(ctx, db, ch, logger)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer conn.Close()
+	if len(init) > 0 {
+		// Returning driver.ErrBadConn makes database/sql discard the
+		// connection together with the session settings of the scraper.
+		// Connections without them go back to the pool.
+		defer conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	for _, query := range init {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return wrapDriverError(err)
+		}
+	}
+	if pinned {
+		return connScraper.ScrapeConn(ctx, conn, ch, logger)
+	}
+	return scrapeSession(ctx, scraper, conn, ch, logger)
 }
 
 func (e *Exporter) getTargetFromDsn() string {
+	// The target of a pool passed to NewWithDB is unknown.
+	if e.db != nil {
+		return ""
+	}
 	// Get target from DSN.
 	dsnConfig, err := mysql.ParseDSN(e.dsn)
 	if err != nil {
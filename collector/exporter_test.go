@@ -16,12 +16,22 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"net"
 	"os"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	"github.com/smartystreets/goconvey/convey"
 )
@@ -85,3 +95,861 @@ func TestGetMySQLVersion(t *testing.T) {
 		convey.So(getMySQLVersion(db, logger), convey.ShouldBeBetweenOrEqual, 5.6, 11.0)
 	})
 }
+
+// fakeScraper is a Scraper that returns err without querying the database.
+type fakeScraper struct {
+	name    string
+	version float64
+	err     error
+}
+
+func (s fakeScraper) Name() string { return s.name }
+
+func (fakeScraper) Help() string { return "Fake scraper" }
+
+func (s fakeScraper) Version() float64 {
+	if s.version == 0 {
+		return 5.1
+	}
+	return s.version
+}
+
+func (s fakeScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	return s.err
+}
+
+func TestScrapeDBCollectorDuration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	exporter := New(
+		context.Background(),
+		dsn,
+		[]Scraper{
+			fakeScraper{name: "ok"},
+			fakeScraper{name: "failing", err: errors.New("boom")},
+		},
+		log.NewNopLogger(),
+	)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	durations := map[string]bool{}
+	success := map[string]float64{}
+	for m := range ch {
+		got := readMetric(m)
+		switch m.Desc() {
+		case mysqlScrapeDurationSeconds:
+			durations[got.labels["collector"]] = true
+		case mysqlScrapeCollectorSuccess:
+			success[got.labels["collector"]] = got.value
+		}
+	}
+
+	convey.Convey("Duration is reported for every scraper", t, func() {
+		convey.So(durations, convey.ShouldResemble, map[string]bool{"collect.ok": true, "collect.failing": true})
+		convey.So(success, convey.ShouldResemble, map[string]float64{"collect.ok": 1, "collect.failing": 0})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestGetMySQLVersionMock(t *testing.T) {
+	tests := []struct {
+		version string
+		want    float64
+	}{
+		{"8.0.33", 8.0},
+		{"5.7.42-log", 5.7},
+		{"10.6.12-MariaDB-1:10.6.12+maria~ubu2004-log", 10.6},
+		{"garbage", 999},
+	}
+	for _, tt := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow(tt.version))
+		if got := getMySQLVersion(db, log.NewNopLogger()); got != tt.want {
+			t.Errorf("getMySQLVersion(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+		db.Close()
+	}
+}
+
+func TestScrapeDBSkipsByVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("5.5.62-log"))
+
+	exporter := New(
+		context.Background(),
+		dsn,
+		[]Scraper{
+			fakeScraper{name: "old"},
+			fakeScraper{name: "too_new", version: 5.6},
+		},
+		log.NewNopLogger(),
+	)
+	skippedBefore := testutil.ToFloat64(targetMetricsOf(exporter.key).scraperSkippedVersion.WithLabelValues("collect.too_new"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	ran := map[string]bool{}
+	for m := range ch {
+		if m.Desc() == mysqlScrapeCollectorSuccess {
+			ran[readMetric(m).labels["collector"]] = true
+		}
+	}
+
+	convey.Convey("Scrapers newer than the server are skipped", t, func() {
+		convey.So(ran, convey.ShouldResemble, map[string]bool{"collect.old": true})
+		convey.So(testutil.ToFloat64(targetMetricsOf(exporter.key).scraperSkippedVersion.WithLabelValues("collect.too_new")), convey.ShouldEqual, skippedBefore+1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeDBLastScrapeState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	scrape := func(scraper Scraper) {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			New(context.Background(), dsn, []Scraper{scraper}, log.NewNopLogger()).scrapeDB(context.Background(), db, ch)
+			close(ch)
+		}()
+		for range ch {
+		}
+	}
+
+	metrics := targetMetricsOf(dsnKey(addDSNParams(dsn)))
+	convey.Convey("The last scrape state is kept across scrapes", t, func() {
+		scrape(fakeScraper{name: "last_state", err: errors.New("failed")})
+		convey.So(testutil.ToFloat64(metrics.lastScrapeSucceeded.WithLabelValues("collect.last_state")), convey.ShouldEqual, 0)
+		failedAt := testutil.ToFloat64(metrics.lastScrapeErrorTimestamp.WithLabelValues("collect.last_state"))
+		convey.So(failedAt, convey.ShouldBeGreaterThan, 0)
+
+		scrape(fakeScraper{name: "last_state"})
+		convey.So(testutil.ToFloat64(metrics.lastScrapeSucceeded.WithLabelValues("collect.last_state")), convey.ShouldEqual, 1)
+		convey.So(testutil.ToFloat64(metrics.lastScrapeErrorTimestamp.WithLabelValues("collect.last_state")), convey.ShouldEqual, failedAt)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCollectTargetMetrics(t *testing.T) {
+	var mocks []sqlmock.Sqlmock
+	for _, target := range []string{"probe_target_a", "probe_target_b"} {
+		// The exporter connects with the session settings added to the DSN.
+		mockDB, mock, err := sqlmock.NewWithDSN(addDSNParams(target))
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer mockDB.Close()
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		mocks = append(mocks, mock)
+		mysqlDriver = mockDB.Driver()
+	}
+	defer func() { mysqlDriver = &MySQL.MySQLDriver{} }()
+
+	// probe gathers the exporter metrics of a collection of target, like
+	// the /probe handler.
+	probe := func(target string, scraper Scraper) map[string]float64 {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(New(context.Background(), target, []Scraper{scraper}, log.NewNopLogger()))
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]float64{}
+		for _, family := range families {
+			for _, m := range family.GetMetric() {
+				switch family.GetName() {
+				case "mysql_exporter_last_scrape_succeeded":
+					values[family.GetName()] = m.GetGauge().GetValue()
+				case "mysql_exporter_scrape_error_total":
+					values[family.GetName()] = m.GetCounter().GetValue()
+				}
+			}
+		}
+		return values
+	}
+
+	convey.Convey("A collection only exposes the scrape state of its target", t, func() {
+		convey.So(probe("probe_target_a", fakeScraper{name: "probe_state", err: errors.New("failed")}), convey.ShouldResemble, map[string]float64{
+			"mysql_exporter_last_scrape_succeeded": 0,
+			"mysql_exporter_scrape_error_total":    1,
+		})
+		convey.So(probe("probe_target_b", fakeScraper{name: "probe_state"}), convey.ShouldResemble, map[string]float64{
+			"mysql_exporter_last_scrape_succeeded": 1,
+		})
+		for _, mock := range mocks {
+			convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+		}
+	})
+}
+
+func TestScrapeDBVersionLabel(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.version_label"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	scraper := fakeScraper{name: "heartbeat", version: ScrapeHeartbeat{}.Version()}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := New(context.Background(), dsn, []Scraper{scraper}, log.NewNopLogger()).scrapeDB(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var got []MetricResult
+	for m := range ch {
+		got = append(got, readMetric(m))
+	}
+	convey.Convey("Collector metrics are labeled with the scraper version", t, func() {
+		convey.So(got, convey.ShouldHaveLength, 2)
+		for _, m := range got {
+			convey.So(m.labels, convey.ShouldResemble, labelMap{"collector": "collect.heartbeat", "min_version": "5.1"})
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeDBPermissionDenied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).
+		WillReturnError(&MySQL.MySQLError{Number: 1142, Message: "SELECT command denied to user 'exporter'@'localhost' for table 'heartbeat'"})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger()).scrapeDB(context.Background(), db, ch); err != nil {
+			t.Errorf("scrape failed on permission denied: %s", err)
+		}
+		close(ch)
+	}()
+
+	var denied, success []MetricResult
+	for m := range ch {
+		switch m.Desc() {
+		case mysqlScrapePermissionDenied:
+			denied = append(denied, readMetric(m))
+		case mysqlScrapeCollectorSuccess:
+			success = append(success, readMetric(m))
+		}
+	}
+	convey.Convey("Permission denied is reported without failing the scrape", t, func() {
+		convey.So(denied, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"collector": "collect.heartbeat"}, value: 1, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(success, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"collector": "collect.heartbeat"}, value: 0, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// mutatingScraper is a fakeScraper declaring that it changes server state.
+type mutatingScraper struct {
+	fakeScraper
+	scraped *bool
+}
+
+func (mutatingScraper) MutatesState() bool { return true }
+
+func (s mutatingScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	*s.scraped = true
+	return nil
+}
+
+func TestScrapeDBReadOnlySafe(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.read_only_safe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	scraped := false
+	scrapers := []Scraper{
+		mutatingScraper{fakeScraper: fakeScraper{name: "mutating"}, scraped: &scraped},
+		fakeScraper{name: "reading"},
+	}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := New(context.Background(), dsn, scrapers, log.NewNopLogger()).scrapeDB(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var collectors []string
+	for m := range ch {
+		if m.Desc() == mysqlScrapeCollectorSuccess {
+			collectors = append(collectors, readMetric(m).labels["collector"])
+		}
+	}
+	convey.Convey("Scrapers changing server state are skipped", t, func() {
+		convey.So(scraped, convey.ShouldBeFalse)
+		convey.So(collectors, convey.ShouldResemble, []string{"collect.reading"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// experimentalScraper is a fakeScraper with experimental metrics.
+type experimentalScraper struct{ fakeScraper }
+
+func (experimentalScraper) Stability() string { return StabilityExperimental }
+
+func TestScrapeDBExperimental(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+	for _, tt := range []struct {
+		name       string
+		args       []string
+		collectors []string
+	}{
+		{"Experimental scrapers are skipped by default", nil, []string{"collect.stable"}},
+		{"Experimental scrapers run when included", []string{"--exporter.include_experimental"}, []string{"collect.experimental", "collect.stable"}},
+	} {
+		if _, err := kingpin.CommandLine.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+		scrapers := []Scraper{
+			experimentalScraper{fakeScraper{name: "experimental"}},
+			fakeScraper{name: "stable"},
+		}
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := New(context.Background(), dsn, scrapers, log.NewNopLogger()).scrapeDB(context.Background(), db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		var collectors []string
+		for m := range ch {
+			if m.Desc() == mysqlScrapeCollectorSuccess {
+				collectors = append(collectors, readMetric(m).labels["collector"])
+			}
+		}
+		sort.Strings(collectors)
+		convey.Convey(tt.name, t, func() {
+			convey.So(collectors, convey.ShouldResemble, tt.collectors)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestSendDBPoolStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	getMySQLVersion(db, log.NewNopLogger())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		sendDBPoolStats(db.Stats(), ch)
+		close(ch)
+	}()
+
+	got := map[*prometheus.Desc]float64{}
+	for m := range ch {
+		got[m.Desc()] = readMetric(m).value
+	}
+	convey.Convey("Pool stats are exported", t, func() {
+		convey.So(got, convey.ShouldResemble, map[*prometheus.Desc]float64{
+			mysqlDBPoolOpenConnections: 1,
+			mysqlDBPoolInUse:           0,
+			mysqlDBPoolIdle:            1,
+			mysqlDBPoolWaitCount:       0,
+			mysqlDBPoolWaitDuration:    0,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// connOrderScraper is a fakeScraper recording when it got a connection,
+// after waiting for delay.
+type connOrderScraper struct {
+	fakeScraper
+	priority int
+	delay    time.Duration
+	mu       *sync.Mutex
+	order    *[]string
+}
+
+func (s connOrderScraper) Priority() int { return s.priority }
+
+func (s connOrderScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	time.Sleep(s.delay)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	s.mu.Lock()
+	*s.order = append(*s.order, s.name)
+	s.mu.Unlock()
+	return nil
+}
+
+func TestScrapeDBPriority(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	scraper := func(name string, priority int, delay time.Duration) Scraper {
+		return connOrderScraper{fakeScraper: fakeScraper{name: name}, priority: priority, delay: delay, mu: &mu, order: &order}
+	}
+	// The prioritized scrapers are slower to ask for the connection.
+	scrapers := []Scraper{
+		scraper("global_status", DefaultScraperPriority, 0),
+		scraper("slave_status", DefaultScraperPriority, 0),
+		scraper("heartbeat", 10, 20*time.Millisecond),
+		scraper("server_clock", 20, 10*time.Millisecond),
+	}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := New(context.Background(), dsn, scrapers, log.NewNopLogger()).scrapeDB(context.Background(), db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Prioritized scrapers get the single connection first, in order of priority", t, func() {
+		convey.So(order, convey.ShouldHaveLength, 4)
+		convey.So(order[:2], convey.ShouldResemble, []string{"heartbeat", "server_clock"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// sleepingScraper is a fakeScraper taking sleep to finish unless cancelled.
+type sleepingScraper struct {
+	fakeScraper
+	sleep time.Duration
+}
+
+func (s sleepingScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	select {
+	case <-time.After(s.sleep):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestScrapeDBSlowCollectors(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--exporter.collector_slow_threshold=50ms",
+		"--exporter.collector_timeout=500ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	scrapers := []Scraper{
+		sleepingScraper{fakeScraper: fakeScraper{name: "fast"}},
+		sleepingScraper{fakeScraper: fakeScraper{name: "slow"}, sleep: 100 * time.Millisecond},
+		sleepingScraper{fakeScraper: fakeScraper{name: "stuck"}, sleep: time.Hour},
+	}
+	ch := make(chan prometheus.Metric)
+	go func() {
+		New(context.Background(), dsn, scrapers, log.NewNopLogger()).scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	slow, success := map[string]float64{}, map[string]float64{}
+	for m := range ch {
+		got := readMetric(m)
+		switch m.Desc() {
+		case mysqlScrapeSlow:
+			slow[got.labels["collector"]] = got.value
+		case mysqlScrapeCollectorSuccess:
+			success[got.labels["collector"]] = got.value
+		}
+	}
+
+	convey.Convey("Slow collectors finish but are reported", t, func() {
+		convey.So(slow, convey.ShouldResemble, map[string]float64{"collect.fast": 0, "collect.slow": 1, "collect.stuck": 1})
+		convey.So(success, convey.ShouldResemble, map[string]float64{"collect.fast": 1, "collect.slow": 1, "collect.stuck": 0})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPingDBRetriesStaleConnection(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	// sqlmock cannot reopen its only connection once the pool discards it,
+	// so hold another one for the duration of the test.
+	held, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	mock.ExpectPing().WillReturnError(MySQL.ErrInvalidConn)
+	mock.ExpectPing()
+
+	failures := prometheus.NewCounter(prometheus.CounterOpts{Name: "ping_failures_total"})
+	err = pingDB(context.Background(), db, true, failures, log.NewNopLogger())
+	convey.Convey("A stale connection is retried once", t, func() {
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(testutil.ToFloat64(failures), convey.ShouldEqual, 1)
+	})
+
+	mock.ExpectPing().WillReturnError(MySQL.ErrInvalidConn)
+	mock.ExpectPing().WillReturnError(MySQL.ErrInvalidConn)
+	err = pingDB(context.Background(), db, true, failures, log.NewNopLogger())
+	convey.Convey("The target is unreachable if the retry fails", t, func() {
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(testutil.ToFloat64(failures), convey.ShouldEqual, 3)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPingDBKeepsCallerPool(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectPing().WillReturnError(MySQL.ErrInvalidConn)
+	mock.ExpectPing()
+
+	err = pingDB(context.Background(), db, false, prometheus.NewCounter(prometheus.CounterOpts{Name: "ping_failures_total"}), log.NewNopLogger())
+	convey.Convey("The idle connections of a caller-owned pool are kept", t, func() {
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(db.Stats().MaxIdleClosed, convey.ShouldEqual, 0)
+		convey.So(db.Stats().Idle, convey.ShouldEqual, 1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestSendScrapeDeadline(t *testing.T) {
+	scrapeDeadline := func(ctx context.Context) []float64 {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			sendScrapeDeadline(ctx, ch)
+			close(ch)
+		}()
+		var got []float64
+		for m := range ch {
+			got = append(got, readMetric(m).value)
+		}
+		return got
+	}
+
+	convey.Convey("The deadline is only exported with a scrape timeout", t, func() {
+		convey.So(scrapeDeadline(context.Background()), convey.ShouldBeEmpty)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		got := scrapeDeadline(ctx)
+		convey.So(got, convey.ShouldHaveLength, 1)
+		convey.So(got[0], convey.ShouldBeBetweenOrEqual, 9, 10)
+	})
+}
+
+func TestSendEnabledCollectors(t *testing.T) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		sendEnabledCollectors([]Scraper{ScrapeHeartbeat{}, ScrapeKeyCache{}}, ch)
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"collector": "collect.heartbeat"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"collector": "collect.myisam.key_cache"}, value: 1, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Only the enabled collectors are exported", t, func() {
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+}
+
+func TestNewSessionParams(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--exporter.session_time_zone=+00:00",
+		"--exporter.charset=utf8mb4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{
+		"--exporter.session_time_zone=",
+		"--exporter.charset=",
+	})
+
+	convey.Convey("Session settings are passed to the driver", t, func() {
+		// The driver runs SET time_zone and SET NAMES for these params on
+		// every new connection.
+		cfg, err := MySQL.ParseDSN(New(context.Background(), dsn, nil, log.NewNopLogger()).dsn)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.Params["time_zone"], convey.ShouldEqual, "'+00:00'")
+		convey.So(cfg.Params["charset"], convey.ShouldEqual, "utf8mb4")
+		convey.So(cfg.Params["lock_wait_timeout"], convey.ShouldEqual, "2")
+		convey.So(cfg.Timeout, convey.ShouldEqual, 5*time.Second)
+	})
+}
+
+func TestNewConnectTimeout(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.connect_timeout=0s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	convey.Convey("A zero connect timeout is not passed to the driver", t, func() {
+		cfg, err := MySQL.ParseDSN(New(context.Background(), dsn, nil, log.NewNopLogger()).dsn)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.Timeout, convey.ShouldEqual, time.Duration(0))
+	})
+}
+
+func TestScrapeRetriesConnectionErrors(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--exporter.scrape_retries=2",
+		"--exporter.scrape_retry_backoff=1ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{
+		"--exporter.scrape_retries=0",
+		"--exporter.scrape_retry_backoff=100ms",
+	})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	query := sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")
+	mock.ExpectQuery(query).WillReturnError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(columns).AddRow("1487597613.001320", "1487598113.448042", 1))
+
+	exporter := New(context.Background(), dsn, nil, log.NewNopLogger())
+	retries := targetMetricsOf(exporter.key).scrapeRetries.WithLabelValues("collect.heartbeat")
+	retriesBefore := testutil.ToFloat64(retries)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		ctx := withScrapeTarget(context.Background(), exporter.key)
+		if err := exporter.scrapeWithDropLabels(ctx, ScrapeHeartbeat{}, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	var stored int
+	for m := range ch {
+		if m.Desc() == HeartbeatStoredDesc {
+			stored++
+		}
+	}
+
+	convey.Convey("A connection error is retried", t, func() {
+		convey.So(stored, convey.ShouldEqual, 1)
+		convey.So(testutil.ToFloat64(retries), convey.ShouldEqual, retriesBefore+1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeRetriesStopOnCancel(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--exporter.scrape_retries=5",
+		"--exporter.scrape_retry_backoff=1h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{
+		"--exporter.scrape_retries=0",
+		"--exporter.scrape_retry_backoff=100ms",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scraper := fakeScraper{name: "cancel", err: newScrapeError(ErrConnection, errors.New("connection refused"))}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- retryScraper(ctx, scraper, nil, make(chan prometheus.Metric), log.NewNopLogger())
+	}()
+	cancel()
+
+	convey.Convey("Retries stop when the scrape is cancelled", t, func() {
+		convey.So(errorClass(<-errCh), convey.ShouldEqual, ErrConnection)
+	})
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{"--exporter.scrape_retries=0"})
+	scrapers := []Scraper{ScrapeHeartbeat{}, ScrapePerfEventsStatements{}, ScrapeGlobalStatus{}}
+
+	convey.Convey("Default configuration is valid", t, func() {
+		if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		convey.So(ValidateConfiguration(scrapers), convey.ShouldBeEmpty)
+	})
+
+	convey.Convey("All invalid settings are reported", t, func() {
+		_, err := kingpin.CommandLine.Parse([]string{
+			"--collect.perf_schema.eventsstatements.limit=-1",
+			"--collect.heartbeat.recency_window=500ms",
+			"--exporter.scrape_retries=-1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs := ValidateConfiguration(scrapers)
+		convey.So(errs, convey.ShouldHaveLength, 3)
+		convey.So(errs[0].Error(), convey.ShouldEqual, "exporter.scrape_retries must not be negative, got -1")
+		convey.So(errs[1].Error(), convey.ShouldStartWith, "heartbeat: ")
+		convey.So(errorClass(errs[1]), convey.ShouldEqual, ErrConfig)
+		convey.So(errs[2].Error(), convey.ShouldEqual,
+			"perf_schema.eventsstatements: collect.perf_schema.eventsstatements.limit must not be negative, got -1")
+	})
+
+	convey.Convey("Only the given scrapers are validated", t, func() {
+		_, err := kingpin.CommandLine.Parse([]string{
+			"--collect.perf_schema.eventsstatements.limit=-1",
+			"--exporter.scrape_retries=0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		convey.So(ValidateConfiguration([]Scraper{ScrapeHeartbeat{}}), convey.ShouldBeEmpty)
+	})
+}
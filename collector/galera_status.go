@@ -0,0 +1,137 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the Galera cluster status from `SHOW GLOBAL STATUS LIKE 'wsrep_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	galera = "galera"
+	// Query.
+	galeraStatusQuery = `SHOW GLOBAL STATUS LIKE 'wsrep_%'`
+)
+
+// Metric descriptors.
+var (
+	galeraClusterSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "cluster_size"),
+		"Number of nodes in the Galera cluster (wsrep_cluster_size).",
+		nil, nil,
+	)
+	galeraLocalStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "local_state"),
+		"Galera state of the node, 4 is Synced (wsrep_local_state).",
+		nil, nil,
+	)
+	galeraLocalStateInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "local_state_info"),
+		"Galera state of the node as text (wsrep_local_state_comment).",
+		[]string{"state"}, nil,
+	)
+	galeraFlowControlPausedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "flow_control_paused_ratio"),
+		"Fraction of time replication was paused by flow control since the last FLUSH STATUS (wsrep_flow_control_paused).",
+		nil, nil,
+	)
+	galeraCertFailuresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, galera, "local_cert_failures_total"),
+		"Number of transactions that failed certification (wsrep_local_cert_failures).",
+		nil, nil,
+	)
+)
+
+// galeraStatusMetrics are the numeric wsrep status variables by name.
+var galeraStatusMetrics = map[string]struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}{
+	"wsrep_cluster_size":        {galeraClusterSizeDesc, prometheus.GaugeValue},
+	"wsrep_local_state":         {galeraLocalStateDesc, prometheus.GaugeValue},
+	"wsrep_flow_control_paused": {galeraFlowControlPausedDesc, prometheus.GaugeValue},
+	"wsrep_local_cert_failures": {galeraCertFailuresDesc, prometheus.CounterValue},
+}
+
+// ScrapeGaleraStatus collects the status of a Galera/PXC cluster node.
+type ScrapeGaleraStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeGaleraStatus) Name() string {
+	return galera + ".status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeGaleraStatus) Help() string {
+	return "Collect the cluster size, state, flow control and certification failures of Galera/PXC nodes"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeGaleraStatus) Version() float64 {
+	return 5.5
+}
+
+// Stability of the Scraper.
+func (ScrapeGaleraStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeGaleraStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, galeraStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key  string
+		val  sql.RawBytes
+		rows int
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		rows++
+		if key == "wsrep_local_state_comment" {
+			ch <- prometheus.MustNewConstMetric(galeraLocalStateInfoDesc, prometheus.GaugeValue, 1, string(val))
+			continue
+		}
+		metric, ok := galeraStatusMetrics[key]
+		if !ok {
+			continue
+		}
+		if value, ok := parseStatus(val); ok {
+			ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, value)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	if rows == 0 {
+		level.Debug(logger).Log("msg", "No wsrep status variables, the server is not a Galera node")
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeGaleraStatus{}
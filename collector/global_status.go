@@ -18,10 +18,13 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -33,6 +36,58 @@ const (
 	globalStatus = "global_status"
 )
 
+// Tunable flags.
+var (
+	globalStatusCommandsTopN = kingpin.Flag(
+		"collect.global_status.commands_top_n",
+		"Only collect the mysql_global_status_commands_total of the N most executed commands, 0 collects all",
+	).Default("0").Int()
+	globalStatusMetricTypes = kingpin.Flag(
+		"collect.global_status.metric_types",
+		"Export a status variable without a dedicated metric as counter or gauge instead of untyped, in the form <variable>=<type>. Can be repeated",
+	).StringMap()
+)
+
+// metricTypes parses collect.global_status.metric_types into the value
+// type of each sanitized variable name.
+func metricTypes() (map[string]prometheus.ValueType, error) {
+	types := make(map[string]prometheus.ValueType, len(*globalStatusMetricTypes))
+	for variable, name := range *globalStatusMetricTypes {
+		var valueType prometheus.ValueType
+		switch strings.ToLower(name) {
+		case "counter":
+			valueType = prometheus.CounterValue
+		case "gauge":
+			valueType = prometheus.GaugeValue
+		default:
+			err := fmt.Errorf("collect.global_status.metric_types: unknown type %q for %s, expected counter or gauge", name, variable)
+			return nil, newConfigError("metric_types", ReasonInvalid, err)
+		}
+		types[validPrometheusName(variable)] = valueType
+	}
+	return types, nil
+}
+
+// globalStatusCommand is the execution count of a Com_ status variable.
+type globalStatusCommand struct {
+	command string
+	count   float64
+}
+
+// topCommands returns the n commands executed most, by name for equal counts.
+func topCommands(commands []globalStatusCommand, n int) []globalStatusCommand {
+	sort.SliceStable(commands, func(i, j int) bool {
+		if commands[i].count != commands[j].count {
+			return commands[i].count > commands[j].count
+		}
+		return commands[i].command < commands[j].command
+	})
+	if n < len(commands) {
+		commands = commands[:n]
+	}
+	return commands
+}
+
 // Regexp to match various groups of status vars.
 var globalStatusRE = regexp.MustCompile(`^(com|handler|connection_errors|innodb_buffer_pool_pages|innodb_rows|performance_schema)_(.*)$`)
 
@@ -98,8 +153,18 @@ func (ScrapeGlobalStatus) Version() float64 {
 	return 5.1
 }
 
+// ValidateConfig checks the metric type overrides.
+func (ScrapeGlobalStatus) ValidateConfig() error {
+	_, err := metricTypes()
+	return err
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	types, err := metricTypes()
+	if err != nil {
+		return newScrapeError(ErrConfig, err)
+	}
 	globalStatusRows, err := db.QueryContext(ctx, globalStatusQuery)
 	if err != nil {
 		return err
@@ -108,6 +173,8 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 
 	var key string
 	var val sql.RawBytes
+	var commands []globalStatusCommand
+	topN := *globalStatusCommandsTopN
 	var textItems = map[string]string{
 		"wsrep_local_state_uuid":   "",
 		"wsrep_cluster_state_uuid": "",
@@ -123,15 +190,27 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 			key = validPrometheusName(key)
 			match := globalStatusRE.FindStringSubmatch(key)
 			if match == nil {
+				valueType, ok := types[key]
+				if !ok {
+					valueType = prometheus.UntypedValue
+				}
+				help := metricHelp.GlobalStatus[key]
+				if help == "" {
+					help = "Generic metric from SHOW GLOBAL STATUS."
+				}
 				ch <- prometheus.MustNewConstMetric(
-					newDesc(globalStatus, key, "Generic metric from SHOW GLOBAL STATUS."),
-					prometheus.UntypedValue,
+					newDesc(globalStatus, key, help),
+					valueType,
 					floatVal,
 				)
 				continue
 			}
 			switch match[1] {
 			case "com":
+				if topN > 0 {
+					commands = append(commands, globalStatusCommand{match[2], floatVal})
+					continue
+				}
 				ch <- prometheus.MustNewConstMetric(
 					globalCommandsDesc, prometheus.CounterValue, floatVal, match[2],
 				)
@@ -174,6 +253,12 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 		}
 	}
 
+	for _, c := range topCommands(commands, topN) {
+		ch <- prometheus.MustNewConstMetric(
+			globalCommandsDesc, prometheus.CounterValue, c.count, c.command,
+		)
+	}
+
 	// mysql_galera_variables_info metric.
 	if textItems["wsrep_local_state_uuid"] != "" {
 		ch <- prometheus.MustNewConstMetric(
@@ -227,3 +312,4 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prom
 
 // check interface
 var _ Scraper = ScrapeGlobalStatus{}
+var _ ConfigValidator = ScrapeGlobalStatus{}
@@ -0,0 +1,105 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the aborted connections and connection errors of the server.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// connectionErrorsStatusQuery selects the aborted connections and, since
+// 5.6, the Connection_errors_* family.
+const connectionErrorsStatusQuery = `SHOW GLOBAL STATUS WHERE
+	Variable_name IN ('Aborted_clients', 'Aborted_connects') OR Variable_name LIKE 'Connection\_errors\_%'`
+
+// Metric descriptors.
+var connectionErrorsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, connection, "errors_total"),
+	"Number of aborted connections (aborted_clients, aborted_connects) and connection errors by cause (Connection_errors_*).",
+	[]string{"error"}, nil,
+)
+
+// connectionErrorLabel returns the error label of a status variable, e.g.
+// "max_connections" for Connection_errors_max_connections.
+func connectionErrorLabel(name string) (string, bool) {
+	name = strings.ToLower(name)
+	switch {
+	case name == "aborted_clients" || name == "aborted_connects":
+		return name, true
+	case strings.HasPrefix(name, "connection_errors_"):
+		return strings.TrimPrefix(name, "connection_errors_"), true
+	}
+	return "", false
+}
+
+// ScrapeConnectionErrors collects aborted connections and connection errors,
+// which diagnose client side connection problems and max_connections
+// exhaustion.
+type ScrapeConnectionErrors struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeConnectionErrors) Name() string {
+	return globalStatus + ".connection_errors"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeConnectionErrors) Help() string {
+	return "Collect Aborted_clients, Aborted_connects and Connection_errors_* from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeConnectionErrors) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeConnectionErrors) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeConnectionErrors) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, connectionErrorsStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var key, val string
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		label, ok := connectionErrorLabel(key)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(connectionErrorsDesc, prometheus.CounterValue, value, label)
+	}
+	return wrapDriverError(statusRows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapeConnectionErrors{}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestConnectionErrorLabel(t *testing.T) {
+	convey.Convey("Error labels are extracted from the status variable names", t, func() {
+		for name, want := range map[string]string{
+			"Aborted_clients":                   "aborted_clients",
+			"Aborted_connects":                  "aborted_connects",
+			"Connection_errors_max_connections": "max_connections",
+			"Connection_errors_peer_address":    "peer_address",
+			"connection_errors_accept":          "accept",
+		} {
+			label, ok := connectionErrorLabel(name)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(label, convey.ShouldEqual, want)
+		}
+		_, ok := connectionErrorLabel("Aborted_connects_preauth")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
+
+func TestScrapeConnectionErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(connectionErrorsStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Aborted_clients", "12").
+			AddRow("Aborted_connects", "3").
+			AddRow("Connection_errors_accept", "0").
+			AddRow("Connection_errors_max_connections", "57"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeConnectionErrors{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"error": "aborted_clients"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error": "aborted_connects"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error": "accept"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"error": "max_connections"}, value: 57, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the connection saturation of the server.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	connection = "connection"
+	// Queries.
+	connectionsStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Threads_connected', 'Threads_running', 'Aborted_connects'
+	)`
+	connectionsMaxQuery = `SELECT @@max_connections`
+)
+
+// Metric descriptors.
+var (
+	connectionThreadsConnectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connection, "threads_connected"),
+		"Number of open connections (Threads_connected).",
+		nil, nil,
+	)
+	connectionThreadsRunningDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connection, "threads_running"),
+		"Number of connections running a statement (Threads_running).",
+		nil, nil,
+	)
+	connectionAbortedConnectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connection, "aborted_connects_total"),
+		"Number of failed attempts to connect to the server (Aborted_connects).",
+		nil, nil,
+	)
+	connectionMaxConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connection, "max_connections"),
+		"Maximum number of simultaneous client connections (max_connections).",
+		nil, nil,
+	)
+	connectionSaturationRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, connection, "saturation_ratio"),
+		"Ratio of Threads_connected to max_connections.",
+		nil, nil,
+	)
+)
+
+// connectionsStatus are the status variables in the order they are exported.
+var connectionsStatus = []struct {
+	name      string
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}{
+	{"Threads_connected", connectionThreadsConnectedDesc, prometheus.GaugeValue},
+	{"Threads_running", connectionThreadsRunningDesc, prometheus.GaugeValue},
+	{"Aborted_connects", connectionAbortedConnectsDesc, prometheus.CounterValue},
+}
+
+// ScrapeConnections collects the number of connections and how close it is to
+// max_connections.
+type ScrapeConnections struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeConnections) Name() string {
+	return globalStatus + ".connections"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeConnections) Help() string {
+	return "Collect connected and running threads, aborted connects and the ratio of connections to max_connections"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeConnections) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeConnections) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeConnections) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	maxConnections, err := queryScalarFloat(ctx, db, connectionsMaxQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+
+	statusRows, err := db.QueryContext(ctx, connectionsStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key, val string
+		status   = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		status[key] = value
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	for _, s := range connectionsStatus {
+		if value, ok := status[s.name]; ok {
+			ch <- prometheus.MustNewConstMetric(s.desc, s.valueType, value)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(connectionMaxConnectionsDesc, prometheus.GaugeValue, maxConnections)
+	if connected, ok := status["Threads_connected"]; ok && maxConnections > 0 {
+		ch <- prometheus.MustNewConstMetric(connectionSaturationRatioDesc, prometheus.GaugeValue, connected/maxConnections)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeConnections{}
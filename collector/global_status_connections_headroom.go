@@ -0,0 +1,151 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape how close the server came to max_connections.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	maxUsedConnectionsQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Max_used_connections', 'Max_used_connections_time'
+	)`
+	// maxConnectionsQuery also returns the offset of the session time zone,
+	// which Max_used_connections_time is expressed in.
+	maxConnectionsQuery = `SELECT @@max_connections, TIMESTAMPDIFF(SECOND, UTC_TIMESTAMP(), NOW())`
+)
+
+// Metric descriptors.
+var (
+	connectionsHeadroomDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "connections_headroom"),
+		"Number of connections left between Max_used_connections and max_connections.",
+		nil, nil,
+	)
+	connectionsHeadroomRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "connections_headroom_ratio"),
+		"Fraction of max_connections never used since the server started.",
+		nil, nil,
+	)
+	maxUsedConnectionsTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "max_used_connections_timestamp_seconds"),
+		"When Max_used_connections was reached, converted from the session time zone.",
+		nil, nil,
+	)
+)
+
+// ScrapeConnectionsHeadroom collects the headroom between the peak number of
+// connections and max_connections.
+type ScrapeConnectionsHeadroom struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeConnectionsHeadroom) Name() string {
+	return globalStatus + ".connections_headroom"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeConnectionsHeadroom) Help() string {
+	return "Collect the headroom between Max_used_connections and max_connections, and when the peak occurred"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeConnectionsHeadroom) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeConnectionsHeadroom) Stability() string {
+	return StabilityExperimental
+}
+
+// parseMaxUsedConnectionsTime converts Max_used_connections_time, a local
+// time of the server, to a unix timestamp using the offset of the session
+// time zone in seconds.
+func parseMaxUsedConnectionsTime(value string, offset int64) (float64, error) {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		return 0, err
+	}
+	return float64(t.Unix() - offset), nil
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeConnectionsHeadroom) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var maxConnections float64
+	var offset int64
+	if err := db.QueryRowContext(ctx, maxConnectionsQuery).Scan(&maxConnections, &offset); err != nil {
+		return wrapDriverError(err)
+	}
+
+	maxUsedRows, err := db.QueryContext(ctx, maxUsedConnectionsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer maxUsedRows.Close()
+
+	var (
+		key, val       string
+		maxUsed        float64
+		maxUsedTime    string
+		foundMaxUsed   bool
+		foundMaxUsedTs bool
+	)
+	for maxUsedRows.Next() {
+		if err := maxUsedRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		switch key {
+		case "Max_used_connections":
+			if maxUsed, err = strconv.ParseFloat(val, 64); err != nil {
+				return newScrapeError(ErrParse, err)
+			}
+			foundMaxUsed = true
+		case "Max_used_connections_time":
+			maxUsedTime, foundMaxUsedTs = val, val != ""
+		}
+	}
+	if err := maxUsedRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	if foundMaxUsed && maxConnections > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			connectionsHeadroomDesc, prometheus.GaugeValue, maxConnections-maxUsed,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			connectionsHeadroomRatioDesc, prometheus.GaugeValue, (maxConnections-maxUsed)/maxConnections,
+		)
+	}
+	if foundMaxUsedTs {
+		ts, err := parseMaxUsedConnectionsTime(maxUsedTime, offset)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			maxUsedConnectionsTimestampDesc, prometheus.GaugeValue, ts,
+		)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeConnectionsHeadroom{}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeConnectionsHeadroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The server runs at UTC+02:00.
+	mock.ExpectQuery(sanitizeQuery(maxConnectionsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"@@max_connections", "offset"}).AddRow("200", "7200"))
+	mock.ExpectQuery(sanitizeQuery(maxUsedConnectionsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Max_used_connections", "150").
+			AddRow("Max_used_connections_time", "2023-06-01 12:00:00"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeConnectionsHeadroom{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{}, value: 50, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0.25, metricType: dto.MetricType_GAUGE},
+		// 2023-06-01 10:00:00 UTC.
+		{labels: labelMap{}, value: 1685613600, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestParseMaxUsedConnectionsTime(t *testing.T) {
+	convey.Convey("Max_used_connections_time is converted from the session time zone", t, func() {
+		ts, err := parseMaxUsedConnectionsTime("2023-06-01 10:00:00", 0)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(ts, convey.ShouldEqual, 1685613600)
+
+		ts, err = parseMaxUsedConnectionsTime("2023-06-01 05:00:00", -5*3600)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(ts, convey.ShouldEqual, 1685613600)
+
+		_, err = parseMaxUsedConnectionsTime("yesterday", 0)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeConnections(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// max_connections is raised between the scrapes.
+	for _, maxConnections := range []string{"100", "200"} {
+		mock.ExpectQuery(sanitizeQuery(connectionsMaxQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"@@max_connections"}).AddRow(maxConnections))
+		mock.ExpectQuery(sanitizeQuery(connectionsStatusQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Aborted_connects", "3").
+				AddRow("Threads_connected", "80").
+				AddRow("Threads_running", "4"))
+	}
+
+	scrape := func() []MetricResult {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeConnections{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		return got
+	}
+
+	convey.Convey("The saturation follows max_connections", t, func() {
+		convey.So(scrape(), convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 80, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 4, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{}, value: 100, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 0.8, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(scrape(), convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 80, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 4, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{}, value: 200, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 0.4, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
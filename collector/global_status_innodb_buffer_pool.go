@@ -0,0 +1,124 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the InnoDB buffer pool efficiency from `SHOW GLOBAL STATUS LIKE 'Innodb_buffer_pool_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// innodbBufferPool is the Metric subsystem we use.
+	innodbBufferPool = "innodb_buffer_pool"
+	// innodbBufferPoolStatusQuery selects the buffer pool status variables.
+	innodbBufferPoolStatusQuery = `SHOW GLOBAL STATUS LIKE 'Innodb_buffer_pool_%'`
+)
+
+// Metric descriptors.
+var (
+	innodbBufferPoolHitRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbBufferPool, "hit_ratio"),
+		"Ratio of InnoDB buffer pool read requests not read from disk since the server started.",
+		nil, nil,
+	)
+	innodbBufferPoolUtilizationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbBufferPool, "utilization"),
+		"Ratio of InnoDB buffer pool pages in use.",
+		nil, nil,
+	)
+)
+
+// innodbBufferPoolUtilization returns the ratio of pages that are not free.
+// It returns false for an empty buffer pool.
+func innodbBufferPoolUtilization(pagesTotal, pagesFree float64) (float64, bool) {
+	if pagesTotal <= 0 {
+		return 0, false
+	}
+	return (pagesTotal - pagesFree) / pagesTotal, true
+}
+
+// ScrapeInnodbBufferPoolStatus collects the InnoDB buffer pool hit ratio and
+// utilization. The underlying status variables are exported by global_status.
+type ScrapeInnodbBufferPoolStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbBufferPoolStatus) Name() string {
+	return innodbBufferPool
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbBufferPoolStatus) Help() string {
+	return "Collect the InnoDB buffer pool hit ratio and utilization from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbBufferPoolStatus) Version() float64 {
+	return 5.5
+}
+
+// Stability of the Scraper.
+func (ScrapeInnodbBufferPoolStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbBufferPoolStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, innodbBufferPoolStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key    string
+		val    sql.RawBytes
+		status = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			status[key] = value
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	readRequests, hasReadRequests := status["Innodb_buffer_pool_read_requests"]
+	reads, hasReads := status["Innodb_buffer_pool_reads"]
+	if hasReadRequests && hasReads {
+		// Right after startup there are no read requests yet.
+		if ratio, ok := keyCacheHitRatio(readRequests, reads); ok {
+			ch <- prometheus.MustNewConstMetric(innodbBufferPoolHitRatioDesc, prometheus.GaugeValue, ratio)
+		}
+	}
+	pagesTotal, hasPagesTotal := status["Innodb_buffer_pool_pages_total"]
+	pagesFree, hasPagesFree := status["Innodb_buffer_pool_pages_free"]
+	if hasPagesTotal && hasPagesFree {
+		if ratio, ok := innodbBufferPoolUtilization(pagesTotal, pagesFree); ok {
+			ch <- prometheus.MustNewConstMetric(innodbBufferPoolUtilizationDesc, prometheus.GaugeValue, ratio)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbBufferPoolStatus{}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbBufferPoolStatus(t *testing.T) {
+	convey.Convey("Buffer pool ratios", t, func() {
+		for _, test := range []struct {
+			name         string
+			readRequests string
+			expected     []MetricResult
+		}{
+			{
+				name:         "with read requests",
+				readRequests: "2000",
+				expected: []MetricResult{
+					{labels: labelMap{}, value: 0.975, metricType: dto.MetricType_GAUGE},
+					{labels: labelMap{}, value: 0.75, metricType: dto.MetricType_GAUGE},
+				},
+			},
+			{
+				// Right after startup the hit ratio is skipped.
+				name:         "without read requests",
+				readRequests: "0",
+				expected: []MetricResult{
+					{labels: labelMap{}, value: 0.75, metricType: dto.MetricType_GAUGE},
+				},
+			},
+		} {
+			convey.Convey(test.name, func() {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Fatalf("error opening a stub database connection: %s", err)
+				}
+				defer db.Close()
+
+				columns := []string{"Variable_name", "Value"}
+				rows := sqlmock.NewRows(columns).
+					AddRow("Innodb_buffer_pool_dump_status", "Dumping of buffer pool not started").
+					AddRow("Innodb_buffer_pool_pages_data", "7000").
+					AddRow("Innodb_buffer_pool_pages_free", "2048").
+					AddRow("Innodb_buffer_pool_pages_total", "8192").
+					AddRow("Innodb_buffer_pool_read_requests", test.readRequests).
+					AddRow("Innodb_buffer_pool_reads", "50")
+				mock.ExpectQuery(sanitizeQuery(innodbBufferPoolStatusQuery)).WillReturnRows(rows)
+
+				ch := make(chan prometheus.Metric)
+				go func() {
+					if err := (ScrapeInnodbBufferPoolStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+						t.Errorf("error calling function on test: %s", err)
+					}
+					close(ch)
+				}()
+
+				for _, expect := range test.expected {
+					got := readMetric(<-ch)
+					convey.So(got, convey.ShouldResemble, expect)
+				}
+				_, more := <-ch
+				convey.So(more, convey.ShouldBeFalse)
+
+				// Ensure all SQL queries were executed
+				if err := mock.ExpectationsWereMet(); err != nil {
+					t.Errorf("there were unfulfilled exceptions: %s", err)
+				}
+			})
+		}
+	})
+}
+
+func TestInnodbBufferPoolUtilization(t *testing.T) {
+	convey.Convey("The utilization is skipped for an empty buffer pool", t, func() {
+		ratio, ok := innodbBufferPoolUtilization(8192, 2048)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0.75)
+		_, ok = innodbBufferPoolUtilization(0, 0)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
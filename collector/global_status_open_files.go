@@ -0,0 +1,127 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the open files of the server and open_files_limit.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Queries.
+	openFilesStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN ('Open_files', 'Open_streams')`
+	openFilesLimitQuery  = `SELECT @@open_files_limit`
+)
+
+// Metric descriptors.
+var (
+	openFilesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "open_files"),
+		"Number of files opened by the server that are not sockets or pipes (Open_files).",
+		nil, nil,
+	)
+	openStreamsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "open_streams"),
+		"Number of streams opened by the server (Open_streams).",
+		nil, nil,
+	)
+	openFilesLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "open_files_limit"),
+		"Number of file descriptors available to the server (open_files_limit).",
+		nil, nil,
+	)
+	openFilesRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "open_files_ratio"),
+		"Ratio of Open_files to open_files_limit.",
+		nil, nil,
+	)
+)
+
+// ScrapeOpenFiles collects how close the server is to open_files_limit.
+type ScrapeOpenFiles struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeOpenFiles) Name() string {
+	return "open_files"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeOpenFiles) Help() string {
+	return "Collect the open files and streams of the server and their ratio to open_files_limit"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeOpenFiles) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeOpenFiles) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeOpenFiles) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	limit, err := queryScalarFloat(ctx, db, openFilesLimitQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(openFilesLimitDesc, prometheus.GaugeValue, limit)
+
+	statusRows, err := db.QueryContext(ctx, openFilesStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key, val string
+		status   = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		status[key] = value
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	if value, ok := status["Open_streams"]; ok {
+		ch <- prometheus.MustNewConstMetric(openStreamsDesc, prometheus.GaugeValue, value)
+	}
+	openFiles, ok := status["Open_files"]
+	if !ok {
+		return nil
+	}
+	ch <- prometheus.MustNewConstMetric(openFilesDesc, prometheus.GaugeValue, openFiles)
+	if limit > 0 {
+		ch <- prometheus.MustNewConstMetric(openFilesRatioDesc, prometheus.GaugeValue, openFiles/limit)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeOpenFiles{}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeOpenFiles(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		limit    string
+		expected []MetricResult
+	}{
+		{
+			name:  "limit",
+			limit: "5000",
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 5000, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 1250, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0.25, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name:  "zero limit",
+			limit: "0",
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 1250, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(sanitizeQuery(openFilesLimitQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"@@open_files_limit"}).AddRow(tt.limit))
+			statusRows := sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Open_files", "1250").
+				AddRow("Open_streams", "0")
+			mock.ExpectQuery(sanitizeQuery(openFilesStatusQuery)).WillReturnRows(statusRows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeOpenFiles{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("Open files are collected", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the prepared statements of the server and their limit.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	preparedStmts = "prepared_statements"
+	// Queries.
+	maxPreparedStmtCountQuery = `SELECT @@max_prepared_stmt_count`
+	preparedStmtStatusQuery   = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Prepared_stmt_count', 'Com_stmt_prepare', 'Com_stmt_close'
+	)`
+)
+
+// Metric descriptors.
+var (
+	preparedStatementsActiveDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedStmts, "active"),
+		"Number of prepared statements currently open across all sessions (Prepared_stmt_count).",
+		nil, nil,
+	)
+	preparedStatementsMaxDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedStmts, "max"),
+		"Maximum number of prepared statements open at once (max_prepared_stmt_count).",
+		nil, nil,
+	)
+	preparedStatementsPreparedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedStmts, "prepared_total"),
+		"Number of statements prepared (Com_stmt_prepare).",
+		nil, nil,
+	)
+	preparedStatementsClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedStmts, "closed_total"),
+		"Number of prepared statements closed (Com_stmt_close).",
+		nil, nil,
+	)
+	preparedStatementsSaturationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, preparedStmts, "saturation_ratio"),
+		"Fraction of max_prepared_stmt_count in use. Preparing further statements fails at 1.",
+		nil, nil,
+	)
+)
+
+// preparedStmtCounters are the status variables in the order they are exported.
+var preparedStmtCounters = []struct {
+	name      string
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+}{
+	{"Prepared_stmt_count", preparedStatementsActiveDesc, prometheus.GaugeValue},
+	{"Com_stmt_prepare", preparedStatementsPreparedDesc, prometheus.CounterValue},
+	{"Com_stmt_close", preparedStatementsClosedDesc, prometheus.CounterValue},
+}
+
+// preparedStmtSaturation returns the ratio of active prepared statements to
+// their limit. It returns false if prepared statements are disabled with a
+// limit of 0.
+func preparedStmtSaturation(active, limit float64) (float64, bool) {
+	if limit <= 0 {
+		return 0, false
+	}
+	return active / limit, true
+}
+
+// ScrapePreparedStatements collects the prepared statements currently open,
+// the churn of preparing and closing them, and how close they are to
+// max_prepared_stmt_count, beyond which preparing statements fails.
+type ScrapePreparedStatements struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePreparedStatements) Name() string {
+	return globalStatus + ".prepared_statements"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePreparedStatements) Help() string {
+	return "Collect Prepared_stmt_count, Com_stmt_prepare and Com_stmt_close from SHOW GLOBAL STATUS and their ratio to max_prepared_stmt_count"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePreparedStatements) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapePreparedStatements) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePreparedStatements) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var limit float64
+	if err := db.QueryRowContext(ctx, maxPreparedStmtCountQuery).Scan(&limit); err != nil {
+		return wrapDriverError(err)
+	}
+
+	statusRows, err := db.QueryContext(ctx, preparedStmtStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key    string
+		val    sql.RawBytes
+		status = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			status[key] = value
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(preparedStatementsMaxDesc, prometheus.GaugeValue, limit)
+	for _, counter := range preparedStmtCounters {
+		if value, ok := status[counter.name]; ok {
+			ch <- prometheus.MustNewConstMetric(counter.desc, counter.valueType, value)
+		}
+	}
+	active, ok := status["Prepared_stmt_count"]
+	if !ok {
+		return nil
+	}
+	if ratio, ok := preparedStmtSaturation(active, limit); ok {
+		ch <- prometheus.MustNewConstMetric(preparedStatementsSaturationDesc, prometheus.GaugeValue, ratio)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePreparedStatements{}
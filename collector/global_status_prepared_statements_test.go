@@ -0,0 +1,79 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePreparedStatements(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(maxPreparedStmtCountQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@max_prepared_stmt_count"}).AddRow("16382"))
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Com_stmt_close", "9000").
+		AddRow("Com_stmt_prepare", "13095").
+		AddRow("Prepared_stmt_count", "4095")
+	mock.ExpectQuery(sanitizeQuery(preparedStmtStatusQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapePreparedStatements{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 16382, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 4095, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 13095, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 9000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 4095.0 / 16382, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Prepared statements and their saturation are collected", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestPreparedStmtSaturation(t *testing.T) {
+	convey.Convey("The saturation is skipped with prepared statements disabled", t, func() {
+		ratio, ok := preparedStmtSaturation(100, 400)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0.25)
+
+		ratio, ok = preparedStmtSaturation(0, 400)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0)
+
+		_, ok = preparedStmtSaturation(0, 0)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the types of SELECT executions from `SHOW GLOBAL STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const selectTypesStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Select_full_join', 'Select_full_range_join', 'Select_range', 'Select_range_check', 'Select_scan'
+	)`
+
+// Metric descriptors.
+var (
+	selectTypesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "select_types_total"),
+		"Number of joins and selects by how they read the first table (Select_<type>).",
+		[]string{"type"}, nil,
+	)
+)
+
+// selectTypes are the type labels of the status variables in the order they
+// are exported.
+var selectTypes = []struct {
+	name      string
+	labelType string
+}{
+	{"Select_scan", "scan"},
+	{"Select_full_join", "full_join"},
+	{"Select_range", "range"},
+	{"Select_full_range_join", "full_range_join"},
+	{"Select_range_check", "range_check"},
+}
+
+// ScrapeSelectScanTypes collects the number of selects by type. Growing full
+// joins and scans are early signs of queries no longer using indexes.
+type ScrapeSelectScanTypes struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSelectScanTypes) Name() string {
+	return globalStatus + ".select_types"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSelectScanTypes) Help() string {
+	return "Collect Select_scan, Select_full_join, Select_range, Select_full_range_join and Select_range_check from SHOW GLOBAL STATUS by type"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSelectScanTypes) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeSelectScanTypes) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSelectScanTypes) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, selectTypesStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key    string
+		val    sql.RawBytes
+		status = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			status[key] = value
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	for _, selectType := range selectTypes {
+		if value, ok := status[selectType.name]; ok {
+			ch <- prometheus.MustNewConstMetric(selectTypesDesc, prometheus.CounterValue, value, selectType.labelType)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeSelectScanTypes{}
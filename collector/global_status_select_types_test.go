@@ -0,0 +1,66 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSelectScanTypes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// Distinct values to tell the variables apart, in the order of the server.
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Select_full_join", "1").
+		AddRow("Select_full_range_join", "2").
+		AddRow("Select_range", "3").
+		AddRow("Select_range_check", "4").
+		AddRow("Select_scan", "5")
+	mock.ExpectQuery(sanitizeQuery(selectTypesStatusQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeSelectScanTypes{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"type": "scan"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"type": "full_join"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"type": "range"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"type": "full_range_join"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"type": "range_check"}, value: 4, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Each status variable maps to its type label", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
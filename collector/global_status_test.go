@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -110,3 +111,147 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeGlobalStatusCommandsTopN(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.global_status.commands_top_n=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Com_alter_db", "1").
+		AddRow("Com_insert", "40").
+		AddRow("Com_select", "300").
+		AddRow("Handler_commit", "5").
+		AddRow("Com_update", "40")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGlobalStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var commands []MetricResult
+	for m := range ch {
+		if m.Desc() == globalCommandsDesc {
+			commands = append(commands, readMetric(m))
+		}
+	}
+	convey.Convey("Only the most executed commands are collected", t, func() {
+		convey.So(commands, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"command": "select"}, value: 300, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{"command": "insert"}, value: 40, metricType: dto.MetricType_COUNTER},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeGlobalStatusHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Handler_read_first", "12").
+		AddRow("Handler_read_rnd_next", "123456").
+		AddRow("Handler_write", "98").
+		AddRow("Com_select", "300")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeGlobalStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var handlers []MetricResult
+	for m := range ch {
+		if m.Desc() == globalHandlerDesc {
+			handlers = append(handlers, readMetric(m))
+		}
+	}
+	convey.Convey("Handler counters are labeled by the name suffix", t, func() {
+		convey.So(handlers, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"handler": "read_first"}, value: 12, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{"handler": "read_rnd_next"}, value: 123456, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{"handler": "write"}, value: 98, metricType: dto.MetricType_COUNTER},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeGlobalStatusMetricTypes(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.global_status.metric_types=Uptime=counter",
+		"--collect.global_status.metric_types=Threads_running=GAUGE",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { *globalStatusMetricTypes = map[string]string{} }()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Uptime", "3600").
+		AddRow("Threads_running", "4").
+		AddRow("Aborted_clients", "2")
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeGlobalStatus{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	convey.Convey("Overridden variables have the configured type", t, func() {
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 3600, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{}, value: 4, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 2, metricType: dto.MetricType_UNTYPED},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+
+	*globalStatusMetricTypes = map[string]string{"uptime": "histogram"}
+	convey.Convey("Unknown types are config errors", t, func() {
+		convey.So(ScrapeGlobalStatus{}.ValidateConfig(), convey.ShouldNotBeNil)
+		err := ScrapeGlobalStatus{}.Scrape(context.Background(), db, make(chan prometheus.Metric), log.NewNopLogger())
+		convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+	})
+}
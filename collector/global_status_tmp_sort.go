@@ -0,0 +1,150 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape temporary table and sort activity from `SHOW GLOBAL STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystems.
+	tmpTables = "tmp"
+	sorts     = "sort"
+	// Query.
+	tmpSortStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Created_tmp_tables', 'Created_tmp_disk_tables', 'Sort_merge_passes', 'Sort_scan'
+	)`
+)
+
+// Metric descriptors.
+var (
+	tmpTablesCreatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "tables_created_total"),
+		"Number of internal temporary tables created while executing statements (Created_tmp_tables).",
+		nil, nil,
+	)
+	tmpDiskTablesCreatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "disk_tables_created_total"),
+		"Number of internal on-disk temporary tables created while executing statements (Created_tmp_disk_tables).",
+		nil, nil,
+	)
+	sortMergePassesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sorts, "merge_passes_total"),
+		"Number of merge passes the sort algorithm had to do (Sort_merge_passes).",
+		nil, nil,
+	)
+	sortScanDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, sorts, "scan_total"),
+		"Number of sorts that were done by scanning the table (Sort_scan).",
+		nil, nil,
+	)
+	tmpDiskTableRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "disk_table_ratio"),
+		"Ratio of internal temporary tables created on disk since the server started.",
+		nil, nil,
+	)
+)
+
+// tmpSortCounters are the status variables in the order they are exported.
+var tmpSortCounters = []struct {
+	name string
+	desc *prometheus.Desc
+}{
+	{"Created_tmp_tables", tmpTablesCreatedDesc},
+	{"Created_tmp_disk_tables", tmpDiskTablesCreatedDesc},
+	{"Sort_merge_passes", sortMergePassesDesc},
+	{"Sort_scan", sortScanDesc},
+}
+
+// tmpDiskTableRatio returns the ratio of temporary tables created on disk. It
+// returns false before the first temporary table was created.
+func tmpDiskTableRatio(tables, diskTables float64) (float64, bool) {
+	if tables <= 0 {
+		return 0, false
+	}
+	return diskTables / tables, true
+}
+
+// ScrapeTempAndSort collects the temporary table and sort activity.
+type ScrapeTempAndSort struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTempAndSort) Name() string {
+	return globalStatus + ".tmp_and_sort"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTempAndSort) Help() string {
+	return "Collect created temporary tables, sort merge passes and scans and the ratio of temporary tables created on disk from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTempAndSort) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeTempAndSort) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTempAndSort) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, tmpSortStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key    string
+		val    sql.RawBytes
+		status = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			status[key] = value
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	for _, counter := range tmpSortCounters {
+		if value, ok := status[counter.name]; ok {
+			ch <- prometheus.MustNewConstMetric(counter.desc, prometheus.CounterValue, value)
+		}
+	}
+	tables, hasTables := status["Created_tmp_tables"]
+	diskTables, hasDiskTables := status["Created_tmp_disk_tables"]
+	if !hasTables || !hasDiskTables {
+		return nil
+	}
+	if ratio, ok := tmpDiskTableRatio(tables, diskTables); ok {
+		ch <- prometheus.MustNewConstMetric(tmpDiskTableRatioDesc, prometheus.GaugeValue, ratio)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeTempAndSort{}
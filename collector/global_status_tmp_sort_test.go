@@ -0,0 +1,85 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTempAndSort(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Created_tmp_disk_tables", "25").
+		AddRow("Created_tmp_tables", "200").
+		AddRow("Sort_merge_passes", "3").
+		AddRow("Sort_scan", "1200")
+	mock.ExpectQuery(sanitizeQuery(tmpSortStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeTempAndSort{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 25, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 1200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 0.125, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Temporary table and sort status is collected", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestTmpDiskTableRatio(t *testing.T) {
+	convey.Convey("The disk table ratio is skipped without temporary tables", t, func() {
+		ratio, ok := tmpDiskTableRatio(200, 25)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0.125)
+
+		ratio, ok = tmpDiskTableRatio(10, 0)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0)
+
+		_, ok = tmpDiskTableRatio(0, 0)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the uptime of the server from `SHOW GLOBAL STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const uptimeStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN ('Uptime', 'Uptime_since_flush_status')`
+
+// Metric descriptors.
+var (
+	globalStatusUptimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "uptime_seconds"),
+		"Number of seconds since the server started.",
+		nil, nil,
+	)
+	globalStatusUptimeSinceFlushDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "uptime_since_flush_seconds"),
+		"Number of seconds since the most recent FLUSH STATUS.",
+		nil, nil,
+	)
+	globalStatusUptimeUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalStatus, "uptime_up"),
+		"Whether the uptime could be read from global status.",
+		nil, nil,
+	)
+)
+
+// ScrapeUptime collects the uptime of the server, whose resets reveal
+// restarts, and the time since its status counters were last flushed.
+type ScrapeUptime struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeUptime) Name() string {
+	return globalStatus + ".uptime"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeUptime) Help() string {
+	return "Collect Uptime and Uptime_since_flush_status from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeUptime) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeUptime) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeUptime) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, uptimeStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var key, val string
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		switch key {
+		case "Uptime":
+			ch <- prometheus.MustNewConstMetric(globalStatusUptimeDesc, prometheus.GaugeValue, value)
+		case "Uptime_since_flush_status":
+			ch <- prometheus.MustNewConstMetric(globalStatusUptimeSinceFlushDesc, prometheus.GaugeValue, value)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(globalStatusUptimeUpDesc, prometheus.GaugeValue, 1)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeUptime{}
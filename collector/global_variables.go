@@ -20,7 +20,6 @@ import (
 	"database/sql"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -116,6 +115,10 @@ var (
 		"rocksdb_whole_key_filtering":                     "Enables the bloomfilter to use the whole key for filtering instead of just the prefix. In order for this to be efficient, lookups should use the whole key for matching.",
 		"rocksdb_write_disable_wal":                       "Disables logging data to the WAL files. Useful for bulk loading.",
 		"rocksdb_write_ignore_missing_column_families":    "If 1, then writes to column families that do not exist is ignored by RocksDB.",
+		// https://dev.mysql.com/doc/refman/8.0/en/server-system-variables.html#sysvar_read_only
+		"read_only":        "Whether the server rejects writes from clients without the CONNECTION_ADMIN or SUPER privilege (1) or not (0).",
+		"super_read_only":  "Whether the server rejects writes from all clients, including those with the CONNECTION_ADMIN or SUPER privilege (1) or not (0).",
+		"innodb_read_only": "Whether InnoDB was started in read-only mode (1) or not (0).",
 	}
 )
 
@@ -164,7 +167,10 @@ func (ScrapeGlobalVariables) Scrape(ctx context.Context, db *sql.DB, ch chan<- p
 
 		key = validPrometheusName(key)
 		if floatVal, ok := parseStatus(val); ok {
-			help := globalVariablesHelp[key]
+			help := metricHelp.GlobalVariables[key]
+			if help == "" {
+				help = globalVariablesHelp[key]
+			}
 			if help == "" {
 				help = "Generic gauge metric from SHOW GLOBAL VARIABLES."
 			}
@@ -243,12 +249,5 @@ func parseWsrepProviderOptions(opts string) float64 {
 	return val
 }
 
-func validPrometheusName(s string) string {
-	nameRe := regexp.MustCompile("([^a-zA-Z0-9_])")
-	s = nameRe.ReplaceAllString(s, "_")
-	s = strings.ToLower(s)
-	return s
-}
-
 // check interface
 var _ Scraper = ScrapeGlobalVariables{}
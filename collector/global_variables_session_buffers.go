@@ -0,0 +1,87 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape per-session buffer sizes from global variables.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const sessionBuffersQuery = `SHOW GLOBAL VARIABLES WHERE Variable_name IN (
+		'sort_buffer_size', 'join_buffer_size', 'tmp_table_size', 'max_heap_table_size'
+	)`
+
+// Metric descriptors.
+var (
+	sessionBufferBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, globalVariables, "session_buffer_bytes"),
+		"Size of the buffers a single session may allocate, by variable.",
+		[]string{"variable"}, nil,
+	)
+)
+
+// ScrapeSessionBuffers collects the sizes of per-session buffers.
+type ScrapeSessionBuffers struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSessionBuffers) Name() string {
+	return globalVariables + ".session_buffers"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSessionBuffers) Help() string {
+	return "Collect the sizes of per-session sort, join and temporary table buffers"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSessionBuffers) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeSessionBuffers) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSessionBuffers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	sessionBuffersRows, err := db.QueryContext(ctx, sessionBuffersQuery)
+	if err != nil {
+		return err
+	}
+	defer sessionBuffersRows.Close()
+
+	var key string
+	var val sql.RawBytes
+
+	for sessionBuffersRows.Next() {
+		if err := sessionBuffersRows.Scan(&key, &val); err != nil {
+			return err
+		}
+		if floatVal, ok := parseStatus(val); ok { // Unparsable values are silently skipped.
+			ch <- prometheus.MustNewConstMetric(
+				sessionBufferBytesDesc, prometheus.GaugeValue, floatVal, sanitizeMetricName(key),
+			)
+		}
+	}
+	return sessionBuffersRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSessionBuffers{}
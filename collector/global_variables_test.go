@@ -43,6 +43,9 @@ func TestScrapeGlobalVariables(t *testing.T) {
 		AddRow("sync_binlog", "0").
 		AddRow("sync_frm", "ON").
 		AddRow("slow_launch_time", "2").
+		AddRow("read_only", "ON").
+		AddRow("super_read_only", "OFF").
+		AddRow("innodb_read_only", "OFF").
 		AddRow("innodb_version", "5.6.30-76.3").
 		AddRow("version", "5.6.30-76.3-56").
 		AddRow("version_comment", "Percona XtraDB Cluster...").
@@ -66,6 +69,9 @@ func TestScrapeGlobalVariables(t *testing.T) {
 		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"innodb_version": "5.6.30-76.3", "version": "5.6.30-76.3-56", "version_comment": "Percona XtraDB Cluster..."}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"wsrep_cluster_name": "supercluster"}, value: 1, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{}, value: 134217728, metricType: dto.MetricType_GAUGE},
@@ -0,0 +1,152 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the number of transactions in `@@gtid_executed` and `@@gtid_purged`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	gtid = "gtid"
+	// Query.
+	gtidQuery = `SELECT @@gtid_executed, @@gtid_purged`
+)
+
+// Metric descriptors.
+var (
+	gtidExecutedTransactionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, gtid, "executed_transactions"),
+		"Number of transactions in gtid_executed by source server.",
+		[]string{"source_uuid"}, nil,
+	)
+	gtidPurgedTransactionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, gtid, "purged_transactions"),
+		"Number of transactions in gtid_purged by source server.",
+		[]string{"source_uuid"}, nil,
+	)
+)
+
+// parseGTIDSet returns the number of transactions by source UUID of a GTID
+// set such as "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:11,
+// 4E11FA47-71CA-11E1-9E33-C80AA9429562:1-3". Tagged GTIDs (uuid:tag:1-5) are
+// counted with the untagged GTIDs of the same UUID.
+func parseGTIDSet(set string) (map[string]uint64, error) {
+	transactions := map[string]uint64{}
+	for _, source := range strings.Split(set, ",") {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		parts := strings.Split(source, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid GTID set %q: missing intervals", source)
+		}
+		uuid := strings.ToLower(parts[0])
+		for _, interval := range parts[1:] {
+			if interval == "" || interval[0] < '0' || interval[0] > '9' {
+				// A tag.
+				continue
+			}
+			start, end, ok := strings.Cut(interval, "-")
+			first, err := strconv.ParseUint(start, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GTID interval %q: %w", interval, err)
+			}
+			last := first
+			if ok {
+				if last, err = strconv.ParseUint(end, 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid GTID interval %q: %w", interval, err)
+				}
+			}
+			if last < first {
+				return nil, fmt.Errorf("invalid GTID interval %q: end before start", interval)
+			}
+			transactions[uuid] += last - first + 1
+		}
+	}
+	return transactions, nil
+}
+
+// ScrapeReplicationGTID collects the number of executed and purged
+// transactions by source server.
+type ScrapeReplicationGTID struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeReplicationGTID) Name() string {
+	return gtid
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeReplicationGTID) Help() string {
+	return "Collect the number of transactions in gtid_executed and gtid_purged by source server"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeReplicationGTID) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeReplicationGTID) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeReplicationGTID) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var executed, purged string
+	if err := db.QueryRowContext(ctx, gtidQuery).Scan(&executed, &purged); err != nil {
+		return wrapDriverError(err)
+	}
+	if strings.TrimSpace(executed) == "" && strings.TrimSpace(purged) == "" {
+		level.Debug(logger).Log("msg", "GTID sets are empty, GTID mode is probably OFF")
+		return nil
+	}
+
+	for _, set := range []struct {
+		value string
+		desc  *prometheus.Desc
+	}{
+		{executed, gtidExecutedTransactionsDesc},
+		{purged, gtidPurgedTransactionsDesc},
+	} {
+		transactions, err := parseGTIDSet(set.value)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		uuids := make([]string, 0, len(transactions))
+		for uuid := range transactions {
+			uuids = append(uuids, uuid)
+		}
+		sort.Strings(uuids)
+		for _, uuid := range uuids {
+			ch <- prometheus.MustNewConstMetric(set.desc, prometheus.GaugeValue, float64(transactions[uuid]), uuid)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeReplicationGTID{}
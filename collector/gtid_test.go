@@ -0,0 +1,130 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseGTIDSet(t *testing.T) {
+	convey.Convey("GTID sets are parsed", t, func() {
+		for _, c := range []struct {
+			set      string
+			expected map[string]uint64
+		}{
+			{"", map[string]uint64{}},
+			{"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100:200-300", map[string]uint64{
+				"3e11fa47-71ca-11e1-9e33-c80aa9429562": 201,
+			}},
+			{"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5:11,\n4e11fa47-71ca-11e1-9e33-c80aa9429562:7", map[string]uint64{
+				"3e11fa47-71ca-11e1-9e33-c80aa9429562": 6,
+				"4e11fa47-71ca-11e1-9e33-c80aa9429562": 1,
+			}},
+			{"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:audit:1-2", map[string]uint64{
+				"3e11fa47-71ca-11e1-9e33-c80aa9429562": 7,
+			}},
+		} {
+			got, err := parseGTIDSet(c.set)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldResemble, c.expected)
+		}
+	})
+
+	convey.Convey("Invalid GTID sets are rejected", t, func() {
+		for _, set := range []string{
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-x",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:10-1",
+		} {
+			_, err := parseGTIDSet(set)
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestScrapeReplicationGTID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"@@gtid_executed", "@@gtid_purged"}
+	rows := sqlmock.NewRows(columns).AddRow(
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,\n4e11fa47-71ca-11e1-9e33-c80aa9429562:1-3",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10",
+	)
+	mock.ExpectQuery(sanitizeQuery(gtidQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeReplicationGTID{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"source_uuid": "3e11fa47-71ca-11e1-9e33-c80aa9429562"}, value: 100, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"source_uuid": "4e11fa47-71ca-11e1-9e33-c80aa9429562"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"source_uuid": "3e11fa47-71ca-11e1-9e33-c80aa9429562"}, value: 10, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Transactions are counted by source", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeReplicationGTIDEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"@@gtid_executed", "@@gtid_purged"}).AddRow("", "")
+	mock.ExpectQuery(sanitizeQuery(gtidQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeReplicationGTID{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics without GTID mode", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
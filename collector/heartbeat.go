@@ -18,11 +18,17 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -30,10 +36,22 @@ const (
 	// heartbeat is the Metric subsystem we use.
 	heartbeat = "heartbeat"
 	// heartbeatQuery is the query used to fetch the stored and current
-	// timestamps. %s will be replaced by the database and table name.
+	// timestamps. %s will be replaced by the quoted database and table name.
 	// The second column allows gets the server timestamp at the exact same
 	// time the query is run.
-	heartbeatQuery = "SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(%s), server_id from `%s`.`%s`"
+	heartbeatQuery = "SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(%s), server_id from %s.%s"
+	// heartbeatServerSideLagQuery lets the server compute the lag of each row
+	// against %s, the current timestamp expression, with microsecond
+	// precision. The other %s will be replaced by the quoted database and
+	// table name.
+	heartbeatServerSideLagQuery = "SELECT TIMESTAMPDIFF(MICROSECOND, ts, %s)/1e6, server_id from %s.%s"
+	// heartbeatRecencyClause limits heartbeatQuery to rows updated within the
+	// last %d seconds of %s, the current timestamp expression.
+	heartbeatRecencyClause = " WHERE ts > %s - INTERVAL %d SECOND"
+	// heartbeatRelayPositionQuery fetches the binlog position logged by the
+	// source with each heartbeat. %s will be replaced by the quoted database
+	// and table name.
+	heartbeatRelayPositionQuery = "SELECT server_id, file, position from %s.%s"
 )
 
 var (
@@ -49,22 +67,240 @@ var (
 		"collect.heartbeat.utc",
 		"Use UTC for timestamps of the current server (`pt-heartbeat` is called with `--utc`)",
 	).Bool()
+	collectHeartbeatMode = kingpin.Flag(
+		"collect.heartbeat.mode",
+		"How to measure replication lag: timestamp compares the stored timestamp to the current time, server_side lets the server compute that difference, relay_position compares the logged binlog position to the executed position of the replica",
+	).Default("timestamp").Enum("timestamp", "server_side", "relay_position")
+	collectHeartbeatIncludeServerID = kingpin.Flag(
+		"collect.heartbeat.include_server_id",
+		"Label heartbeat metrics with the server_id of the row. Without the label only the row with the newest stored timestamp is exported, as in setups with a single writer",
+	).Default("true").Bool()
+	collectHeartbeatMaxLag = kingpin.Flag(
+		"collect.heartbeat.max_lag",
+		"Lag above which a heartbeat is reported as stale, 0 disables mysql_heartbeat_stale",
+	).Default("0s").Duration()
+	collectHeartbeatAgeMetric = kingpin.Flag(
+		"collect.heartbeat.age_metric",
+		"Export the current minus the stored timestamp of each server_id as mysql_heartbeat_age_seconds, as named by other heartbeat exporters",
+	).Default("false").Bool()
+	collectHeartbeatMissingGracePeriod = kingpin.Flag(
+		"collect.heartbeat.missing_grace_period",
+		"Report server_ids missing from the heartbeat table in mysql_heartbeat_server_missing for this long after they were last seen, 0 disables the metric",
+	).Default("0s").Duration()
+	collectHeartbeatCheckRegression = kingpin.Flag(
+		"collect.heartbeat.check_regression",
+		"Report heartbeat timestamps lower than the one seen in the previous scrape in mysql_heartbeat_ts_regressed",
+	).Bool()
+	collectHeartbeatRecencyWindow = kingpin.Flag(
+		"collect.heartbeat.recency_window",
+		"Only scan heartbeat rows updated within this window, 0 scans all rows",
+	).Default("0s").Duration()
+	collectHeartbeatPrimaryServerID = kingpin.Flag(
+		"collect.heartbeat.primary_server_id",
+		"server_id of the upstream to export mysql_heartbeat_primary_lag_seconds for in the timestamp and server_side modes, e.g. in multi-source replication. 0 disables the metric",
+	).Default("0").Int()
+	collectHeartbeatIncludeOthers = kingpin.Flag(
+		"collect.heartbeat.include_others",
+		"Also export the heartbeat metrics of server_ids other than collect.heartbeat.primary_server_id",
+	).Default("true").Bool()
+	collectHeartbeatQueryOverride = kingpin.Flag(
+		"collect.heartbeat.query_override",
+		"Query used verbatim instead of the default heartbeat query. It must return the stored timestamp, the current timestamp (both as unix timestamps) and the server_id, in this order. collect.heartbeat.database, table, utc and recency_window are ignored",
+	).Default("").String()
+	collectHeartbeatLockWaitTimeout = kingpin.Flag(
+		"collect.heartbeat.lock_wait_timeout",
+		"lock_wait_timeout of the heartbeat session, rounded up to seconds, so that DDL on the heartbeat table does not block the scrape. The session then uses a connection of its own for each scrape. 0 keeps the server setting",
+	).Default("0s").Duration()
 )
 
 // Metric descriptors.
 var (
-	HeartbeatStoredDesc = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, heartbeat, "stored_timestamp_seconds"),
+	HeartbeatStoredDesc = heartbeatDesc(
+		"stored_timestamp_seconds",
 		"Timestamp stored in the heartbeat table.",
-		[]string{"server_id"}, nil,
 	)
-	HeartbeatNowDesc = prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, heartbeat, "now_timestamp_seconds"),
+	HeartbeatNowDesc = heartbeatDesc(
+		"now_timestamp_seconds",
 		"Timestamp of the current server.",
-		[]string{"server_id"}, nil,
+	)
+	HeartbeatStaleDesc = heartbeatDesc(
+		"stale",
+		"Whether the stored timestamp is older than collect.heartbeat.max_lag.",
+	)
+	HeartbeatLagThresholdExceededDesc = heartbeatDesc(
+		"lag_threshold_exceeded_total",
+		"Number of scrapes in which the lag exceeded collect.heartbeat.max_lag.",
+	)
+	HeartbeatAgeDesc = heartbeatDesc(
+		"age_seconds",
+		"Age of the timestamp stored in the heartbeat table, i.e. the current minus the stored timestamp.",
+	)
+	HeartbeatRelayPositionGapDesc = heartbeatDesc(
+		"relay_position_gap_bytes",
+		"Bytes between the binlog position logged in the heartbeat table and Exec_Master_Log_Pos of the replica.",
+	)
+	HeartbeatWorstLagDesc = heartbeatDesc(
+		"worst_lag_seconds",
+		"Highest lag across all heartbeat rows, labeled with the server_id it belongs to.",
+	)
+	HeartbeatTsRegressedDesc = heartbeatDesc(
+		"ts_regressed",
+		"Whether the stored timestamp is lower than in the previous scrape, e.g. after restoring a backup over the heartbeat table.",
+	)
+	HeartbeatServerCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, heartbeat, "server_count"),
+		"Number of distinct server_ids with a valid row in the heartbeat table.",
+		nil, nil,
+	)
+	HeartbeatUpdateIntervalDesc = heartbeatDesc(
+		"update_interval_seconds",
+		"Difference between the last two different stored timestamps seen across scrapes, i.e. how often the heartbeat row is updated.",
+	)
+	HeartbeatServerLastSeenDesc = heartbeatDesc(
+		"server_last_seen_timestamp_seconds",
+		"Time of the scrape that last found a valid row of the server_id in the heartbeat table.",
+	)
+	HeartbeatServerMissingDesc = heartbeatDesc(
+		"server_missing",
+		"Whether a server_id seen within collect.heartbeat.missing_grace_period has no valid row in the heartbeat table anymore.",
+	)
+	HeartbeatLagDesc = heartbeatDesc(
+		"lag_seconds",
+		"Lag of the timestamp stored in the heartbeat table as computed by the server.",
+	)
+	HeartbeatPrimaryLagDesc = heartbeatDescWithConstLabels(
+		"primary_lag_seconds",
+		"Lag of the heartbeat row of collect.heartbeat.primary_server_id.",
+		prometheus.Labels{"role": "primary"},
+	)
+	HeartbeatPrimaryMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, heartbeat, "primary_missing"),
+		"Whether the heartbeat table has no valid row of collect.heartbeat.primary_server_id.",
+		nil, nil,
 	)
 )
 
+// heartbeatUnlabeledDescs maps the heartbeat descriptors to their variants
+// without the server_id label.
+var heartbeatUnlabeledDescs = map[*prometheus.Desc]*prometheus.Desc{}
+
+// heartbeatDesc returns the descriptor of a heartbeat metric labeled with
+// server_id and keeps an unlabeled variant for
+// collect.heartbeat.include_server_id=false.
+func heartbeatDesc(name, help string) *prometheus.Desc {
+	return heartbeatDescWithConstLabels(name, help, nil)
+}
+
+// heartbeatDescWithConstLabels is heartbeatDesc with constLabels added to
+// both variants.
+func heartbeatDescWithConstLabels(name, help string, constLabels prometheus.Labels) *prometheus.Desc {
+	fqName := prometheus.BuildFQName(namespace, heartbeat, name)
+	desc := prometheus.NewDesc(fqName, help, []string{"server_id"}, constLabels)
+	heartbeatUnlabeledDescs[desc] = prometheus.NewDesc(fqName, help, nil, constLabels)
+	return desc
+}
+
+// heartbeatPrimary returns collect.heartbeat.primary_server_id as a
+// server_id label value, or "" if it is not set.
+func heartbeatPrimary() string {
+	if *collectHeartbeatPrimaryServerID <= 0 {
+		return ""
+	}
+	return strconv.Itoa(*collectHeartbeatPrimaryServerID)
+}
+
+// skipHeartbeatRow reports whether the row of serverId is left out because
+// it is not the row of collect.heartbeat.primary_server_id and
+// collect.heartbeat.include_others is not set.
+func skipHeartbeatRow(serverId, primary string) bool {
+	return primary != "" && serverId != primary && !*collectHeartbeatIncludeOthers
+}
+
+// sendHeartbeatPrimary sends the lag of the row of
+// collect.heartbeat.primary_server_id, if it was found, and whether it is
+// missing.
+func sendHeartbeatPrimary(ch chan<- prometheus.Metric, primary string, lag float64, found bool) {
+	if primary == "" {
+		return
+	}
+	missing := 1.0
+	if found {
+		missing = 0
+		ch <- heartbeatMetric(HeartbeatPrimaryLagDesc, prometheus.GaugeValue, lag, primary)
+	}
+	ch <- prometheus.MustNewConstMetric(HeartbeatPrimaryMissingDesc, prometheus.GaugeValue, missing)
+}
+
+// heartbeatMetric returns a metric of a descriptor returned by heartbeatDesc,
+// labeled with serverId unless collect.heartbeat.include_server_id is false.
+func heartbeatMetric(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, serverId string) prometheus.Metric {
+	if !*collectHeartbeatIncludeServerID {
+		return prometheus.MustNewConstMetric(heartbeatUnlabeledDescs[desc], valueType, value)
+	}
+	return prometheus.MustNewConstMetric(desc, valueType, value, serverId)
+}
+
+// heartbeatRow is a heartbeat row with parsed timestamps.
+type heartbeatRow struct {
+	serverId string
+	ts, now  float64
+}
+
+// newestHeartbeatRow returns the row with the newest stored timestamp, so
+// that metrics without the server_id label describe the current writer.
+func newestHeartbeatRow(rows []heartbeatRow) []heartbeatRow {
+	if len(rows) == 0 {
+		return rows
+	}
+	newest := rows[0]
+	for _, row := range rows[1:] {
+		if row.ts > newest.ts {
+			newest = row
+		}
+	}
+	return []heartbeatRow{newest}
+}
+
+// heartbeatInterval is the update interval of the heartbeat row of a
+// server_id.
+type heartbeatInterval struct {
+	serverId string
+	seconds  float64
+}
+
+// heartbeatNow returns the scrape time, replaced in tests.
+var heartbeatNow = time.Now
+
+// heartbeatParseErrors counts heartbeat rows skipped because ts or the
+// current timestamp could not be parsed, e.g. a NULL ts left behind by a
+// crashed pt-heartbeat.
+var heartbeatParseErrors = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: heartbeat,
+	Name:      "parse_errors_total",
+	Help:      "Number of heartbeat rows skipped because a timestamp could not be parsed.",
+})
+
+// heartbeatRowState is the stored timestamp of a heartbeat row in the previous
+// scrape, the last interval between two different stored timestamps, the
+// number of scrapes its lag exceeded collect.heartbeat.max_lag and when the
+// row was last seen.
+type heartbeatRowState struct {
+	ts, interval float64
+	exceeded     uint64
+	lastSeen     time.Time
+}
+
+// heartbeatLastTs keeps the state of each heartbeat row across scrapes, keyed
+// by the scraped target, the replica server_id, the heartbeat table and the
+// server_id of the row. The replica server_id is only known with
+// collect.heartbeat.check_regression, it tells apart servers behind the same
+// target, e.g. after a failover.
+var heartbeatLastTs = struct {
+	sync.Mutex
+	rows map[string]heartbeatRowState
+}{rows: map[string]heartbeatRowState{}}
+
 // ScrapeHeartbeat scrapes from the heartbeat table.
 // This is mainly targeting pt-heartbeat, but will work with any heartbeat
 // implementation that writes to a table with two columns:
@@ -91,61 +327,525 @@ func (ScrapeHeartbeat) Version() float64 {
 	return 5.1
 }
 
-// nowExpr returns a current timestamp expression.
-func nowExpr() string {
-	if *collectHeartbeatUtc {
+// Priority of the Scraper. Heartbeat is cheap and signals replication health,
+// so it runs before other scrapers.
+func (ScrapeHeartbeat) Priority() int {
+	return 10
+}
+
+// MutatesState reports whether the Scraper writes to the server. Heartbeat
+// rows are only read.
+func (ScrapeHeartbeat) MutatesState() bool {
+	return false
+}
+
+// SessionInit returns the session settings of the Scraper. Reading the
+// heartbeat table waits for the metadata lock of running DDL, by default for
+// a year, which would hold up the scrape.
+func (ScrapeHeartbeat) SessionInit() []string {
+	timeout := *collectHeartbeatLockWaitTimeout
+	if timeout <= 0 {
+		return nil
+	}
+	seconds := int64((timeout + time.Second - 1) / time.Second)
+	return []string{fmt.Sprintf("SET SESSION lock_wait_timeout = %d", seconds)}
+}
+
+// nowExpr returns a current timestamp expression, in UTC if utc is set.
+func nowExpr(utc bool) string {
+	if utc {
 		return "UTC_TIMESTAMP(6)"
 	}
 	return "NOW(6)"
 }
 
+// timestampQuery returns heartbeatQuery, or heartbeatServerSideLagQuery in
+// server_side mode, restricted to the rows updated within
+// collect.heartbeat.recency_window when it is set, or
+// collect.heartbeat.query_override.
+func timestampQuery() (string, error) {
+	serverSide := *collectHeartbeatMode == "server_side"
+	if override := *collectHeartbeatQueryOverride; override != "" {
+		if serverSide {
+			err := newScrapeError(ErrConfig, errors.New("collect.heartbeat.query_override cannot be combined with collect.heartbeat.mode=server_side"))
+			return "", newConfigError("query_override", ReasonConflict, err)
+		}
+		override = strings.TrimSpace(override)
+		if override == "" {
+			err := newScrapeError(ErrConfig, errors.New("collect.heartbeat.query_override must not be blank"))
+			return "", newConfigError("query_override", ReasonInvalid, err)
+		}
+		if strings.HasSuffix(override, ";") {
+			err := newScrapeError(ErrConfig, errors.New("collect.heartbeat.query_override must not end with a semicolon"))
+			return "", newConfigError("query_override", ReasonInvalid, err)
+		}
+		return override, nil
+	}
+	template := heartbeatQuery
+	if serverSide {
+		template = heartbeatServerSideLagQuery
+	}
+	query := fmt.Sprintf(template, nowExpr(*collectHeartbeatUtc), quoteIdent(*collectHeartbeatDatabase), quoteIdent(*collectHeartbeatTable))
+	window := *collectHeartbeatRecencyWindow
+	if window == 0 {
+		return query, nil
+	}
+	seconds := int64(window.Seconds())
+	if seconds <= 0 {
+		err := newScrapeError(ErrConfig, fmt.Errorf("collect.heartbeat.recency_window must be at least 1s, got %s", window))
+		return "", newConfigError("recency_window", ReasonOutOfRange, err)
+	}
+	return query + fmt.Sprintf(heartbeatRecencyClause, nowExpr(*collectHeartbeatUtc), seconds), nil
+}
+
+// Describe sends the descriptors of all heartbeat metrics.
+func (ScrapeHeartbeat) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		HeartbeatStoredDesc,
+		HeartbeatNowDesc,
+		HeartbeatStaleDesc,
+		HeartbeatLagThresholdExceededDesc,
+		HeartbeatAgeDesc,
+		HeartbeatRelayPositionGapDesc,
+		HeartbeatWorstLagDesc,
+		HeartbeatTsRegressedDesc,
+		HeartbeatServerCountDesc,
+		HeartbeatUpdateIntervalDesc,
+		HeartbeatServerLastSeenDesc,
+		HeartbeatServerMissingDesc,
+		HeartbeatLagDesc,
+		HeartbeatPrimaryLagDesc,
+		HeartbeatPrimaryMissingDesc,
+	} {
+		if unlabeled, ok := heartbeatUnlabeledDescs[desc]; ok && !*collectHeartbeatIncludeServerID {
+			desc = unlabeled
+		}
+		ch <- desc
+	}
+	ch <- heartbeatParseErrors.Desc()
+}
+
+// DeltaTracked returns the heartbeat metrics also exported as the change
+// since the previous scrape.
+func (ScrapeHeartbeat) DeltaTracked() []*prometheus.Desc {
+	return []*prometheus.Desc{HeartbeatUpdateIntervalDesc, heartbeatUnlabeledDescs[HeartbeatUpdateIntervalDesc]}
+}
+
+// ValidateConfig checks the primary server_id, query override and recency
+// window.
+func (ScrapeHeartbeat) ValidateConfig() error {
+	if timeout := *collectHeartbeatLockWaitTimeout; timeout < 0 {
+		err := newScrapeError(ErrConfig, fmt.Errorf("collect.heartbeat.lock_wait_timeout must not be negative, got %s", timeout))
+		return newConfigError("lock_wait_timeout", ReasonOutOfRange, err)
+	}
+	if id := *collectHeartbeatPrimaryServerID; id < 0 {
+		err := newScrapeError(ErrConfig, fmt.Errorf("collect.heartbeat.primary_server_id must not be negative, got %d", id))
+		return newConfigError("primary_server_id", ReasonOutOfRange, err)
+	}
+	_, err := timestampQuery()
+	return err
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeHeartbeat) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	query := fmt.Sprintf(heartbeatQuery, nowExpr(), *collectHeartbeatDatabase, *collectHeartbeatTable)
-	heartbeatRows, err := db.QueryContext(ctx, query)
+	switch *collectHeartbeatMode {
+	case "relay_position":
+		return scrapeHeartbeatRelayPosition(ctx, db, ch, logger)
+	case "server_side":
+		return scrapeHeartbeatServerSideLag(ctx, db, ch, logger)
+	}
+
+	var replicaServerID string
+	if *collectHeartbeatCheckRegression {
+		if err := db.QueryRowContext(ctx, serverIDQuery).Scan(&replicaServerID); err != nil {
+			return wrapDriverError(err)
+		}
+	}
+
+	query, err := timestampQuery()
 	if err != nil {
 		return err
 	}
+	heartbeatRows, err := preparedStatements.queryContext(ctx, db, query)
+	if err != nil {
+		return wrapDriverError(err)
+	}
 	defer heartbeatRows.Close()
 
 	var (
 		now, ts  sql.RawBytes
 		serverId int
+
+		worstLag      float64
+		worstServerId string
+		rows          int
+		serverIds     []string
+		seen          = map[string]bool{}
+		intervals     []heartbeatInterval
+		scraped       = heartbeatNow()
+		keyPrefix     = scrapeTarget(ctx) + "\xff" + replicaServerID + "\xff" + *collectHeartbeatDatabase + "." + *collectHeartbeatTable + "\xff"
+
+		primary      = heartbeatPrimary()
+		primaryLag   float64
+		primaryFound bool
 	)
 
-	for heartbeatRows.Next() {
-		if err := heartbeatRows.Scan(&ts, &now, &serverId); err != nil {
+	// emit exports the metrics of a row. Without
+	// collect.heartbeat.include_server_id only the newest row is emitted,
+	// once all rows were read.
+	emit := func(row heartbeatRow) {
+		serverId, tsFloatVal, nowFloatVal := row.serverId, row.ts, row.now
+
+		ch <- withServerTimestamp(heartbeatMetric(
+			HeartbeatNowDesc,
+			prometheus.GaugeValue,
+			nowFloatVal,
+			serverId,
+		), nowFloatVal)
+		ch <- withServerTimestamp(heartbeatMetric(
+			HeartbeatStoredDesc,
+			prometheus.GaugeValue,
+			tsFloatVal,
+			serverId,
+		), nowFloatVal)
+
+		lag := nowFloatVal - tsFloatVal
+		maxLag := collectHeartbeatMaxLag.Seconds()
+
+		key := keyPrefix + serverId
+		heartbeatLastTs.Lock()
+		last, ok := heartbeatLastTs.rows[key]
+		state := heartbeatRowState{ts: tsFloatVal, exceeded: last.exceeded, lastSeen: scraped}
+		if maxLag > 0 && lag > maxLag {
+			state.exceeded++
+		}
+		switch {
+		case ok && tsFloatVal > last.ts:
+			state.interval = tsFloatVal - last.ts
+		case ok && tsFloatVal == last.ts:
+			// Not updated since the previous scrape.
+			state.interval = last.interval
+		}
+		heartbeatLastTs.rows[key] = state
+		heartbeatLastTs.Unlock()
+		if state.interval > 0 {
+			intervals = append(intervals, heartbeatInterval{serverId, state.interval})
+		}
+
+		if *collectHeartbeatCheckRegression {
+			regressed := 0.0
+			if ok && tsFloatVal < last.ts {
+				regressed = 1
+			}
+			ch <- heartbeatMetric(
+				HeartbeatTsRegressedDesc,
+				prometheus.GaugeValue,
+				regressed,
+				serverId,
+			)
+		}
+
+		if maxLag > 0 {
+			stale := 0.0
+			if lag > maxLag {
+				stale = 1
+			}
+			ch <- heartbeatMetric(
+				HeartbeatStaleDesc,
+				prometheus.GaugeValue,
+				stale,
+				serverId,
+			)
+			ch <- heartbeatMetric(
+				HeartbeatLagThresholdExceededDesc,
+				prometheus.CounterValue,
+				float64(state.exceeded),
+				serverId,
+			)
+		}
+		if *collectHeartbeatAgeMetric {
+			ch <- withServerTimestamp(heartbeatMetric(
+				HeartbeatAgeDesc,
+				prometheus.GaugeValue,
+				lag,
+				serverId,
+			), nowFloatVal)
+		}
+
+		if rows == 0 || lag > worstLag {
+			worstLag, worstServerId = lag, serverId
+		}
+		if !seen[serverId] {
+			seen[serverId] = true
+			serverIds = append(serverIds, serverId)
+		}
+		rows++
+	}
+
+	var newest []heartbeatRow
+	for scanned := 0; heartbeatRows.Next(); scanned++ {
+		if err := checkCtx(ctx, scanned); err != nil {
 			return err
 		}
+		if err := heartbeatRows.Scan(&ts, &now, &serverId); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+
+		serverId := strconv.Itoa(serverId)
 
 		tsFloatVal, err := strconv.ParseFloat(string(ts), 64)
 		if err != nil {
-			return err
+			level.Warn(logger).Log("msg", "Skipping heartbeat row with unparsable ts", "server_id", serverId, "err", err)
+			heartbeatParseErrors.Inc()
+			continue
 		}
 
 		nowFloatVal, err := strconv.ParseFloat(string(now), 64)
 		if err != nil {
-			return err
+			level.Warn(logger).Log("msg", "Skipping heartbeat row with unparsable current timestamp", "server_id", serverId, "err", err)
+			heartbeatParseErrors.Inc()
+			continue
 		}
+		if serverId == primary {
+			primaryLag, primaryFound = nowFloatVal-tsFloatVal, true
+		}
+		if skipHeartbeatRow(serverId, primary) {
+			continue
+		}
+		row := heartbeatRow{serverId: serverId, ts: tsFloatVal, now: nowFloatVal}
+		if *collectHeartbeatIncludeServerID {
+			emit(row)
+		} else {
+			newest = newestHeartbeatRow(append(newest, row))
+		}
+	}
+	if err := heartbeatRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	for _, row := range newest {
+		emit(row)
+	}
+	sendHeartbeatPrimary(ch, primary, primaryLag, primaryFound)
+	level.Debug(logger).Log("msg", "Scraped heartbeat table", "database", *collectHeartbeatDatabase, "table", *collectHeartbeatTable, "query", query, "rows", rows)
 
-		serverId := strconv.Itoa(serverId)
-
-		ch <- prometheus.MustNewConstMetric(
-			HeartbeatNowDesc,
+	if rows > 0 {
+		ch <- heartbeatMetric(
+			HeartbeatWorstLagDesc,
 			prometheus.GaugeValue,
-			nowFloatVal,
+			worstLag,
+			worstServerId,
+		)
+	}
+	scrapeTime := float64(scraped.UnixNano()) / 1e9
+	for _, serverId := range serverIds {
+		ch <- heartbeatMetric(
+			HeartbeatServerLastSeenDesc,
+			prometheus.GaugeValue,
+			scrapeTime,
 			serverId,
 		)
-		ch <- prometheus.MustNewConstMetric(
-			HeartbeatStoredDesc,
+	}
+	ch <- prometheus.MustNewConstMetric(
+		HeartbeatServerCountDesc,
+		prometheus.GaugeValue,
+		float64(len(serverIds)),
+	)
+	for _, interval := range intervals {
+		ch <- heartbeatMetric(
+			HeartbeatUpdateIntervalDesc,
 			prometheus.GaugeValue,
-			tsFloatVal,
+			interval.seconds,
+			interval.serverId,
+		)
+	}
+	// Without the server_id label, rows of other server_ids are not missing.
+	if grace := *collectHeartbeatMissingGracePeriod; grace > 0 && *collectHeartbeatIncludeServerID {
+		var missing []string
+		heartbeatLastTs.Lock()
+		for key, state := range heartbeatLastTs.rows {
+			serverId := strings.TrimPrefix(key, keyPrefix)
+			if serverId == key || seen[serverId] {
+				continue
+			}
+			if scraped.Sub(state.lastSeen) > grace {
+				delete(heartbeatLastTs.rows, key)
+				continue
+			}
+			missing = append(missing, serverId)
+		}
+		heartbeatLastTs.Unlock()
+		sort.Strings(missing)
+		for _, serverId := range serverIds {
+			ch <- prometheus.MustNewConstMetric(HeartbeatServerMissingDesc, prometheus.GaugeValue, 0, serverId)
+		}
+		for _, serverId := range missing {
+			ch <- prometheus.MustNewConstMetric(HeartbeatServerMissingDesc, prometheus.GaugeValue, 1, serverId)
+		}
+	}
+	ch <- heartbeatParseErrors
+
+	return nil
+}
+
+// scrapeHeartbeatServerSideLag exports the lag of each heartbeat row as
+// computed by the server, which is not affected by the time the result takes
+// to reach the exporter. The server does not return the timestamps, so the
+// metrics derived from them are not exported.
+func scrapeHeartbeatServerSideLag(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	query, err := timestampQuery()
+	if err != nil {
+		return err
+	}
+	heartbeatRows, err := preparedStatements.queryContext(ctx, db, query)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer heartbeatRows.Close()
+
+	var (
+		lag, newest, primaryLag sql.NullFloat64
+		serverId                int
+		primary                 = heartbeatPrimary()
+	)
+	for rows := 0; heartbeatRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		if err := heartbeatRows.Scan(&lag, &serverId); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		serverId := strconv.Itoa(serverId)
+		// The server returns NULL for a ts it cannot parse.
+		if !lag.Valid {
+			level.Warn(logger).Log("msg", "Skipping heartbeat row with unparsable ts", "server_id", serverId)
+			heartbeatParseErrors.Inc()
+			continue
+		}
+		if serverId == primary {
+			primaryLag = lag
+		}
+		if skipHeartbeatRow(serverId, primary) {
+			continue
+		}
+		if !*collectHeartbeatIncludeServerID {
+			// The newest stored timestamp has the lowest lag.
+			if !newest.Valid || lag.Float64 < newest.Float64 {
+				newest = lag
+			}
+			continue
+		}
+		ch <- heartbeatMetric(
+			HeartbeatLagDesc,
+			prometheus.GaugeValue,
+			lag.Float64,
 			serverId,
 		)
 	}
+	if err := heartbeatRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	if newest.Valid {
+		ch <- heartbeatMetric(HeartbeatLagDesc, prometheus.GaugeValue, newest.Float64, "")
+	}
+	sendHeartbeatPrimary(ch, primary, primaryLag.Float64, primaryLag.Valid)
+	ch <- heartbeatParseErrors
+	return nil
+}
+
+// scrapeHeartbeatRelayPosition compares the binlog position the source logged
+// with each heartbeat against the position executed by the replica. This does
+// not depend on the clocks of the source and the replica agreeing. Positions
+// can only be compared within the same binlog file, other rows are skipped.
+func scrapeHeartbeatRelayPosition(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	// Exec_Master_Log_Pos and Relay_Master_Log_File by Master_Server_Id.
+	type execPosition struct {
+		file     string
+		position float64
+	}
+	executed := map[string]execPosition{}
 
+	slaveStatusRows, err := querySlaveStatus(ctx, db)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer slaveStatusRows.Close()
+	slaveCols, err := slaveStatusRows.Columns()
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	for slaveStatusRows.Next() {
+		scanArgs := make([]interface{}, len(slaveCols))
+		for i := range scanArgs {
+			scanArgs[i] = &sql.RawBytes{}
+		}
+		if err := slaveStatusRows.Scan(scanArgs...); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		position, err := strconv.ParseFloat(columnValue(scanArgs, slaveCols, "Exec_Master_Log_Pos"), 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		executed[columnValue(scanArgs, slaveCols, "Master_Server_Id")] = execPosition{
+			file:     columnValue(scanArgs, slaveCols, "Relay_Master_Log_File"),
+			position: position,
+		}
+	}
+	if err := slaveStatusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	query := fmt.Sprintf(heartbeatRelayPositionQuery, quoteIdent(*collectHeartbeatDatabase), quoteIdent(*collectHeartbeatTable))
+	heartbeatRows, err := preparedStatements.queryContext(ctx, db, query)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer heartbeatRows.Close()
+
+	var (
+		serverId, file      string
+		position, newestGap float64
+		found               bool
+	)
+	for rows := 0; heartbeatRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		if err := heartbeatRows.Scan(&serverId, &file, &position); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		exec, ok := executed[serverId]
+		if !ok || exec.file != file {
+			level.Debug(logger).Log("msg", "No comparable executed position for heartbeat", "server_id", serverId, "file", file)
+			continue
+		}
+		if !*collectHeartbeatIncludeServerID {
+			// Rows carry no timestamp, the newest heartbeat logged the
+			// highest position.
+			if !found || position-exec.position > newestGap {
+				newestGap, found = position-exec.position, true
+			}
+			continue
+		}
+		ch <- heartbeatMetric(
+			HeartbeatRelayPositionGapDesc,
+			prometheus.GaugeValue,
+			position-exec.position,
+			serverId,
+		)
+	}
+	if err := heartbeatRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	if found {
+		ch <- heartbeatMetric(HeartbeatRelayPositionGapDesc, prometheus.GaugeValue, newestGap, "")
+	}
 	return nil
 }
 
 // check interface
 var _ Scraper = ScrapeHeartbeat{}
+var _ StateMutator = ScrapeHeartbeat{}
+var _ Prioritizer = ScrapeHeartbeat{}
+var _ Describer = ScrapeHeartbeat{}
+var _ DeltaTracker = ScrapeHeartbeat{}
+var _ SessionIniter = ScrapeHeartbeat{}
@@ -14,14 +14,20 @@
 package collector
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
 )
@@ -50,9 +56,25 @@ var ScrapeHeartbeatTestCases = []ScrapeHeartbeatTestCase{
 		[]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(UTC_TIMESTAMP(6))", "server_id"},
 		"SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(UTC_TIMESTAMP(6)), server_id from `heartbeat-test`.`heartbeat-test`",
 	},
+	{
+		[]string{
+			"--collect.heartbeat.database", "heartbeat-test",
+			"--collect.heartbeat.table", `heartbeat"test`,
+			"--no-collect.heartbeat.utc",
+			"--exporter.identifier_quoting", "ansi",
+		},
+		[]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"},
+		`SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from "heartbeat-test"."heartbeat""test"`,
+	},
 }
 
 func TestScrapeHeartbeat(t *testing.T) {
+	defer func() { heartbeatNow = time.Now }()
+	heartbeatNow = func() time.Time { return time.Unix(1487598120, 0) }
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
 	for _, tt := range ScrapeHeartbeatTestCases {
 		t.Run(fmt.Sprint(tt.Args), func(t *testing.T) {
 			_, err := kingpin.CommandLine.Parse(tt.Args)
@@ -70,6 +92,175 @@ func TestScrapeHeartbeat(t *testing.T) {
 				AddRow("1487597613.001320", "1487598113.448042", 1)
 			mock.ExpectQuery(sanitizeQuery(tt.Query)).WillReturnRows(rows)
 
+			metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+
+			now, stored := 1487598113.448042, 1487597613.00132
+			counterExpected := []MetricResult{
+				{labels: labelMap{"server_id": "1"}, value: now, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "1"}, value: stored, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "1"}, value: now - stored, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "1"}, value: 1487598120, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_COUNTER},
+			}
+			convey.Convey("Metrics comparison", t, func() {
+				convey.So(metrics, convey.ShouldHaveLength, len(counterExpected))
+				for i, expect := range counterExpected {
+					convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+				}
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeHeartbeatWorstLag(t *testing.T) {
+	defer func() { heartbeatNow = time.Now }()
+	heartbeatNow = func() time.Time { return time.Unix(1487598120, 0) }
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.max_lag=0s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1).
+		AddRow("1487598050.000000", "1487598113.000000", 2).
+		AddRow("1487598100.000000", "1487598113.000000", 3)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	gauge := func(serverID string, value float64) MetricResult {
+		return MetricResult{labels: labelMap{"server_id": serverID}, value: value, metricType: dto.MetricType_GAUGE}
+	}
+	scraped := float64(1487598120)
+	expected := []MetricResult{
+		gauge("1", 1487598113), gauge("1", 1487598110),
+		gauge("2", 1487598113), gauge("2", 1487598050),
+		gauge("3", 1487598113), gauge("3", 1487598100),
+		gauge("2", 63),
+		gauge("1", scraped), gauge("2", scraped), gauge("3", scraped),
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("The worst lag is reported once for the lagging server", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+		convey.So(metrics[6].Desc(), convey.ShouldEqual, HeartbeatWorstLagDesc)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatStale(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.max_lag=30s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.max_lag=0s"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1).
+		AddRow("1487598050.000000", "1487598113.000000", 2)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	var stale []MetricResult
+	for _, m := range metrics {
+		if m.Desc() == HeartbeatStaleDesc {
+			stale = append(stale, readMetric(m))
+		}
+	}
+
+	convey.Convey("Each server_id is compared to max_lag", t, func() {
+		convey.So(stale, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "1"}, value: 0, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"server_id": "2"}, value: 1, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatAgeMetric(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprint(enabled), func(t *testing.T) {
+			ageFlag := "--no-collect.heartbeat.age_metric"
+			if enabled {
+				ageFlag = "--collect.heartbeat.age_metric"
+			}
+			_, err := kingpin.CommandLine.Parse([]string{
+				"--collect.heartbeat.database=heartbeat",
+				"--collect.heartbeat.table=heartbeat",
+				"--no-collect.heartbeat.utc",
+				ageFlag,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("1487598110.000000", "1487598113.000000", 1).
+				AddRow("1487598050.000000", "1487598113.000000", 2)
+			mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
 			ch := make(chan prometheus.Metric)
 			go func() {
 				if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
@@ -78,15 +269,22 @@ func TestScrapeHeartbeat(t *testing.T) {
 				close(ch)
 			}()
 
-			counterExpected := []MetricResult{
-				{labels: labelMap{"server_id": "1"}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE},
-				{labels: labelMap{"server_id": "1"}, value: 1487597613.00132, metricType: dto.MetricType_GAUGE},
+			var age []MetricResult
+			for m := range ch {
+				if m.Desc() == HeartbeatAgeDesc {
+					age = append(age, readMetric(m))
+				}
 			}
-			convey.Convey("Metrics comparison", t, func() {
-				for _, expect := range counterExpected {
-					got := readMetric(<-ch)
-					convey.So(got, convey.ShouldResemble, expect)
+
+			convey.Convey("The age metric is only exported when enabled", t, func() {
+				if !enabled {
+					convey.So(age, convey.ShouldBeEmpty)
+					return
 				}
+				convey.So(age, convey.ShouldResemble, []MetricResult{
+					{labels: labelMap{"server_id": "1"}, value: 3, metricType: dto.MetricType_GAUGE},
+					{labels: labelMap{"server_id": "2"}, value: 63, metricType: dto.MetricType_GAUGE},
+				})
 			})
 
 			// Ensure all SQL queries were executed
@@ -96,3 +294,1099 @@ func TestScrapeHeartbeat(t *testing.T) {
 		})
 	}
 }
+
+func TestScrapeHeartbeatServerTimestamps(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=timestamps",
+		"--no-collect.heartbeat.utc",
+		"--exporter.use_server_timestamps",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--no-exporter.use_server_timestamps"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.250000", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`timestamps`")).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	timestamps := map[*prometheus.Desc]int64{}
+	for _, m := range metrics {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+		if pb.TimestampMs != nil {
+			timestamps[m.Desc()] = pb.GetTimestampMs()
+		}
+	}
+	convey.Convey("The row metrics carry the server time", t, func() {
+		convey.So(timestamps, convey.ShouldResemble, map[*prometheus.Desc]int64{
+			HeartbeatNowDesc:    1487598113250,
+			HeartbeatStoredDesc: 1487598113250,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatTsRegressed(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.check_regression",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--no-collect.heartbeat.check_regression"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	sequence := []string{"1487598110.000000", "1487598120.000000", "1487598050.000000", "1487598060.000000"}
+	for _, ts := range sequence {
+		mock.ExpectQuery(sanitizeQuery(serverIDQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@server_id"}).AddRow(21))
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(ts, "1487598130.000000", 1))
+	}
+
+	var regressed []float64
+	for range sequence {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		for m := range ch {
+			if m.Desc() == HeartbeatTsRegressedDesc {
+				regressed = append(regressed, readMetric(m).value)
+			}
+		}
+	}
+
+	convey.Convey("Only a decreasing ts is reported", t, func() {
+		convey.So(regressed, convey.ShouldResemble, []float64{0, 0, 1, 0})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatRecencyWindow(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.recency_window=5m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.recency_window=0s"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// The server only returns the rows matched by the recency clause.
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat` WHERE ts > NOW(6) - INTERVAL 300 SECOND")).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	serverIDs := map[string]bool{}
+	for _, m := range metrics {
+		if m.Desc() == HeartbeatStoredDesc {
+			serverIDs[readMetric(m).labels["server_id"]] = true
+		}
+	}
+
+	convey.Convey("Only recent rows are scanned", t, func() {
+		convey.So(serverIDs, convey.ShouldResemble, map[string]bool{"1": true})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatInvalidRecencyWindow(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.recency_window=0s"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	convey.Convey("A window below one second is a config error", t, func() {
+		for _, window := range []string{"-1m", "500ms"} {
+			if _, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.recency_window=" + window}); err != nil {
+				t.Fatal(err)
+			}
+			err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, make(chan prometheus.Metric), log.NewNopLogger())
+			convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+		}
+	})
+
+	// Ensure no SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatQueryOverride(t *testing.T) {
+	query := "SELECT UNIX_TIMESTAMP(beat_at), UNIX_TIMESTAMP(NOW(6)), origin_id FROM ops.beats"
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.query_override=" + query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.query_override="})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(beat_at)", "UNIX_TIMESTAMP(NOW(6))", "origin_id"}
+	rows := sqlmock.NewRows(columns).AddRow("1487598110.000000", "1487598113.000000", 7)
+	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var stored []MetricResult
+	for m := range ch {
+		if m.Desc() == HeartbeatStoredDesc {
+			stored = append(stored, readMetric(m))
+		}
+	}
+	convey.Convey("The override is used verbatim", t, func() {
+		convey.So(stored, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "7"}, value: 1487598110, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatInvalidQueryOverride(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.query_override="})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	convey.Convey("Blank overrides and trailing semicolons are config errors", t, func() {
+		for _, query := range []string{"  ", "SELECT ts, NOW(), server_id FROM ops.beats; "} {
+			if _, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.query_override=" + query}); err != nil {
+				t.Fatal(err)
+			}
+			err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, make(chan prometheus.Metric), log.NewNopLogger())
+			convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+		}
+	})
+
+	// Ensure no SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatSkipsUnparsableRows(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1).
+		AddRow(nil, "1487598113.000000", 2).
+		AddRow("1487598111.000000", "1487598113.000000", 3).
+		AddRow("1487598112.000000", "1487598113.000000", 4)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	parseErrorsBefore := testutil.ToFloat64(heartbeatParseErrors)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var stored []string
+	for m := range ch {
+		if m.Desc() == HeartbeatStoredDesc {
+			stored = append(stored, readMetric(m).labels["server_id"])
+		}
+	}
+
+	convey.Convey("Rows with a NULL ts are skipped and counted", t, func() {
+		convey.So(stored, convey.ShouldResemble, []string{"1", "3", "4"})
+		convey.So(testutil.ToFloat64(heartbeatParseErrors), convey.ShouldEqual, parseErrorsBefore+1)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatServers(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	defer func() { heartbeatNow = time.Now }()
+	heartbeatNow = func() time.Time { return time.Unix(1487598120, 0) }
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1).
+		AddRow("1487598111.000000", "1487598113.000000", 2).
+		AddRow("1487598112.000000", "1487598113.000000", 3)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var (
+		lastSeen []MetricResult
+		count    []MetricResult
+	)
+	for m := range ch {
+		switch m.Desc() {
+		case HeartbeatServerLastSeenDesc:
+			lastSeen = append(lastSeen, readMetric(m))
+		case HeartbeatServerCountDesc:
+			count = append(count, readMetric(m))
+		}
+	}
+
+	convey.Convey("Every server_id is counted and seen at the scrape time", t, func() {
+		convey.So(count, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(lastSeen, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "1"}, value: 1487598120, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"server_id": "2"}, value: 1487598120, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"server_id": "3"}, value: 1487598120, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatUpdateInterval(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=interval",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	scrape := func(ts string) []MetricResult {
+		columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+		rows := sqlmock.NewRows(columns).AddRow(ts, "1487598120.000000", 1)
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`interval`")).WillReturnRows(rows)
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		var intervals []MetricResult
+		for m := range ch {
+			if m.Desc() == HeartbeatUpdateIntervalDesc {
+				intervals = append(intervals, readMetric(m))
+			}
+		}
+		return intervals
+	}
+
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
+	convey.Convey("The interval between stored timestamps is exported", t, func() {
+		convey.So(scrape("1487598110.000000"), convey.ShouldBeEmpty)
+		// Unchanged without a previous interval.
+		convey.So(scrape("1487598110.000000"), convey.ShouldBeEmpty)
+		expected := []MetricResult{{labels: labelMap{"server_id": "1"}, value: 2.5, metricType: dto.MetricType_GAUGE}}
+		convey.So(scrape("1487598112.500000"), convey.ShouldResemble, expected)
+		// Unchanged, the last interval is kept.
+		convey.So(scrape("1487598112.500000"), convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatLagThresholdExceeded(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=threshold",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.max_lag=30s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.max_lag=0s"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	scrape := func(ts string) []MetricResult {
+		columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+		rows := sqlmock.NewRows(columns).AddRow(ts, "1487598120.000000", 1)
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`threshold`")).WillReturnRows(rows)
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		var exceeded []MetricResult
+		for m := range ch {
+			if m.Desc() == HeartbeatLagThresholdExceededDesc {
+				exceeded = append(exceeded, readMetric(m))
+			}
+		}
+		return exceeded
+	}
+	exceeded := func(count float64) []MetricResult {
+		return []MetricResult{{labels: labelMap{"server_id": "1"}, value: count, metricType: dto.MetricType_COUNTER}}
+	}
+
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
+	convey.Convey("Scrapes lagging more than max_lag are counted", t, func() {
+		convey.So(scrape("1487598060.000000"), convey.ShouldResemble, exceeded(1))
+		convey.So(scrape("1487598110.000000"), convey.ShouldResemble, exceeded(1))
+		convey.So(scrape("1487598000.000000"), convey.ShouldResemble, exceeded(2))
+	})
+
+	convey.Convey("The counter is not exported without max_lag", t, func() {
+		_, err := kingpin.CommandLine.Parse([]string{
+			"--collect.heartbeat.database=heartbeat",
+			"--collect.heartbeat.table=threshold",
+			"--no-collect.heartbeat.utc",
+		})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(scrape("1487598000.000000"), convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatServerMissing(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=missing",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.missing_grace_period=1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Unix(1487598120, 0)
+	defer func() { heartbeatNow = time.Now }()
+	heartbeatNow = func() time.Time { return now }
+
+	scrape := func(serverIDs ...int) map[string]float64 {
+		columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+		rows := sqlmock.NewRows(columns)
+		for _, id := range serverIDs {
+			rows.AddRow("1487598110.000000", "1487598120.000000", id)
+		}
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`missing`")).WillReturnRows(rows)
+		metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+		if err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		missing := map[string]float64{}
+		for _, m := range metrics {
+			if m.Desc() == HeartbeatServerMissingDesc {
+				got := readMetric(m)
+				missing[got.labels["server_id"]] = got.value
+			}
+		}
+		return missing
+	}
+
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
+	convey.Convey("Server ids that stopped writing heartbeats are reported", t, func() {
+		convey.So(scrape(1, 2), convey.ShouldResemble, map[string]float64{"1": 0, "2": 0})
+		now = now.Add(10 * time.Second)
+		convey.So(scrape(1), convey.ShouldResemble, map[string]float64{"1": 0, "2": 1})
+		now = now.Add(30 * time.Second)
+		convey.So(scrape(1), convey.ShouldResemble, map[string]float64{"1": 0, "2": 1})
+		// Forgotten after the grace period.
+		now = now.Add(time.Minute)
+		convey.So(scrape(1), convey.ShouldResemble, map[string]float64{"1": 0})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatTargets(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=targets",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.missing_grace_period=1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	defer func() { heartbeatNow = time.Now }()
+	heartbeatNow = func() time.Time { return time.Unix(1487598120, 0) }
+
+	heartbeatLastTs.Lock()
+	heartbeatLastTs.rows = map[string]heartbeatRowState{}
+	heartbeatLastTs.Unlock()
+
+	type target struct {
+		exporter *Exporter
+		db       *sql.DB
+		mock     sqlmock.Sqlmock
+	}
+	newTarget := func() target {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		return target{NewWithDB(context.Background(), db, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger()), db, mock}
+	}
+	a, b := newTarget(), newTarget()
+	defer a.db.Close()
+	defer b.db.Close()
+
+	// scrape returns the update intervals and missing server_ids of a
+	// scrape of the target finding ts for serverIDs.
+	scrape := func(tg target, ts string, serverIDs ...int) (map[string]float64, map[string]float64) {
+		tg.mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+		rows := sqlmock.NewRows(columns)
+		for _, id := range serverIDs {
+			rows.AddRow(ts, "1487598120.000000", id)
+		}
+		tg.mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`targets`")).WillReturnRows(rows)
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := tg.exporter.scrapeDB(context.Background(), tg.db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		intervals, missing := map[string]float64{}, map[string]float64{}
+		for m := range ch {
+			switch m.Desc() {
+			case HeartbeatUpdateIntervalDesc:
+				got := readMetric(m)
+				intervals[got.labels["server_id"]] = got.value
+			case HeartbeatServerMissingDesc:
+				got := readMetric(m)
+				missing[got.labels["server_id"]] = got.value
+			}
+		}
+		return intervals, missing
+	}
+
+	convey.Convey("Targets keep their heartbeat state apart", t, func() {
+		scrape(a, "1487598110.000000", 1)
+		scrape(b, "1487598100.000000", 1, 2)
+
+		intervals, missing := scrape(a, "1487598112.500000", 1)
+		convey.So(intervals, convey.ShouldResemble, map[string]float64{"1": 2.5})
+		convey.So(missing, convey.ShouldResemble, map[string]float64{"1": 0})
+
+		intervals, missing = scrape(b, "1487598105.000000", 1, 2)
+		convey.So(intervals, convey.ShouldResemble, map[string]float64{"1": 5, "2": 5})
+		convey.So(missing, convey.ShouldResemble, map[string]float64{"1": 0, "2": 0})
+	})
+
+	// Ensure all SQL queries were executed
+	for _, tg := range []target{a, b} {
+		if err := tg.mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+	}
+}
+
+func TestScrapeHeartbeatLogFields(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487598110.000000", "1487598113.000000", 1).
+		AddRow("1487598111.000000", "1487598113.000000", 2)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	var buf bytes.Buffer
+	logger := log.NewLogfmtLogger(log.NewSyncWriter(&buf))
+	e := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, logger)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = e.scrapeWithDropLabels(context.Background(), ScrapeHeartbeat{}, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Heartbeat logs the collector, table and row count", t, func() {
+		var line string
+		for _, l := range strings.Split(buf.String(), "\n") {
+			if strings.Contains(l, "Scraped heartbeat table") {
+				line = l
+			}
+		}
+		for _, field := range []string{"collector=heartbeat", "database=heartbeat", "table=heartbeat", "rows=2", "query="} {
+			convey.So(line, convey.ShouldContainSubstring, field)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatCancel(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--collect.heartbeat.max_lag=0s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns)
+	for i := 0; i < 10*ctxCheckInterval; i++ {
+		rows.AddRow("1487597613.001320", "1487598113.448042", i)
+	}
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		err = (ScrapeHeartbeat{}).Scrape(ctx, db, ch, log.NewNopLogger())
+		close(ch)
+	}()
+
+	metrics := 0
+	for range ch {
+		metrics++
+		if metrics == 1 {
+			cancel()
+		}
+	}
+
+	convey.Convey("The scrape stops when the context is cancelled", t, func() {
+		convey.So(errors.Is(err, context.Canceled), convey.ShouldBeTrue)
+		// Two metrics per row, at most one more check interval is read.
+		convey.So(metrics, convey.ShouldBeLessThanOrEqualTo, 2*ctxCheckInterval)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatRelayPosition(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--collect.heartbeat.mode=relay_position",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.mode=timestamp"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	slaveColumns := []string{"Master_Host", "Relay_Master_Log_File", "Exec_Master_Log_Pos", "Master_Server_Id"}
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(sqlmock.NewRows(slaveColumns).
+		AddRow("10.0.0.1", "mysql-bin.000042", "1000", "1").
+		AddRow("10.0.0.2", "mysql-bin.000007", "500", "2"))
+	columns := []string{"server_id", "file", "position"}
+	mock.ExpectQuery(sanitizeQuery("SELECT server_id, file, position from `heartbeat`.`heartbeat`")).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("1", "mysql-bin.000042", "4096").
+		AddRow("2", "mysql-bin.000008", "120").
+		AddRow("3", "mysql-bin.000001", "120"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var got []MetricResult
+	for m := range ch {
+		if m.Desc() != HeartbeatRelayPositionGapDesc {
+			t.Errorf("unexpected metric %s", m.Desc())
+		}
+		got = append(got, readMetric(m))
+	}
+
+	convey.Convey("Only positions in the executed binlog file are compared", t, func() {
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "1"}, value: 3096, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatServerSideLag(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--collect.heartbeat.mode=server_side",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.mode=timestamp"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TIMESTAMPDIFF(MICROSECOND, ts, NOW(6))/1e6", "server_id"}
+	mock.ExpectQuery(sanitizeQuery("SELECT TIMESTAMPDIFF(MICROSECOND, ts, NOW(6))/1e6, server_id from `heartbeat`.`heartbeat`")).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("0.250000", 1).
+		AddRow(nil, 2).
+		AddRow("12.004200", 3))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		if m.Desc() == heartbeatParseErrors.Desc() {
+			continue
+		}
+		if m.Desc() != HeartbeatLagDesc {
+			t.Errorf("unexpected metric %s", m.Desc())
+		}
+		got = append(got, readMetric(m))
+	}
+
+	convey.Convey("Only the lag computed by the server is exported", t, func() {
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "1"}, value: 0.25, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"server_id": "3"}, value: 12.0042, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeHeartbeatServerSideLagQueryOverride(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.mode=server_side",
+		"--collect.heartbeat.query_override=SELECT ts, NOW(), server_id FROM ops.beats",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.mode=timestamp", "--collect.heartbeat.query_override="})
+
+	convey.Convey("A query override cannot be combined with server side lag", t, func() {
+		convey.So(errorClass(ScrapeHeartbeat{}.ValidateConfig()), convey.ShouldEqual, ErrConfig)
+	})
+}
+
+func TestScrapeHeartbeatInvalidMode(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.mode=gtid"})
+	convey.Convey("Unknown modes are rejected", t, func() {
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestScrapeHeartbeatIncludeServerID(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		flag     string
+		desc     *prometheus.Desc
+		expected []MetricResult
+	}{
+		{
+			name: "labeled",
+			flag: "--collect.heartbeat.include_server_id",
+			desc: HeartbeatStoredDesc,
+			expected: []MetricResult{
+				{labels: labelMap{"server_id": "1"}, value: 1487598110, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "2"}, value: 1487598112, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "unlabeled",
+			flag: "--no-collect.heartbeat.include_server_id",
+			desc: heartbeatUnlabeledDescs[HeartbeatStoredDesc],
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 1487598112, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kingpin.CommandLine.Parse([]string{
+				"--collect.heartbeat.database=heartbeat",
+				"--collect.heartbeat.table=single_writer",
+				"--no-collect.heartbeat.utc",
+				tt.flag,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{"--collect.heartbeat.include_server_id"})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("1487598110.000000", "1487598113.000000", 1).
+				AddRow("1487598112.000000", "1487598113.000000", 2)
+			mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`single_writer`")).WillReturnRows(rows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var stored []MetricResult
+			for _, m := range metrics {
+				if m.Desc() == tt.desc {
+					stored = append(stored, readMetric(m))
+				}
+			}
+			convey.Convey("Stored timestamps are exported", t, func() {
+				convey.So(stored, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeHeartbeatPrimaryServerID(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		flags         []string
+		stored        []MetricResult
+		primary       []MetricResult
+		primaryMissed float64
+	}{
+		{
+			name:  "present",
+			flags: []string{"--collect.heartbeat.primary_server_id=2", "--collect.heartbeat.include_others"},
+			stored: []MetricResult{
+				{labels: labelMap{"server_id": "1"}, value: 1487598110, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "2"}, value: 1487598112, metricType: dto.MetricType_GAUGE},
+			},
+			primary: []MetricResult{
+				{labels: labelMap{"server_id": "2", "role": "primary"}, value: 1, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name:  "present without others",
+			flags: []string{"--collect.heartbeat.primary_server_id=2", "--no-collect.heartbeat.include_others"},
+			stored: []MetricResult{
+				{labels: labelMap{"server_id": "2"}, value: 1487598112, metricType: dto.MetricType_GAUGE},
+			},
+			primary: []MetricResult{
+				{labels: labelMap{"server_id": "2", "role": "primary"}, value: 1, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name:  "missing",
+			flags: []string{"--collect.heartbeat.primary_server_id=3", "--collect.heartbeat.include_others"},
+			stored: []MetricResult{
+				{labels: labelMap{"server_id": "1"}, value: 1487598110, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"server_id": "2"}, value: 1487598112, metricType: dto.MetricType_GAUGE},
+			},
+			primaryMissed: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kingpin.CommandLine.Parse(append([]string{
+				"--collect.heartbeat.database=heartbeat",
+				"--collect.heartbeat.table=multi_source",
+				"--no-collect.heartbeat.utc",
+			}, tt.flags...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("1487598110.000000", "1487598113.000000", 1).
+				AddRow("1487598112.000000", "1487598113.000000", 2)
+			mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`multi_source`")).WillReturnRows(rows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var stored, primary []MetricResult
+			missing := -1.0
+			for _, m := range metrics {
+				switch m.Desc() {
+				case HeartbeatStoredDesc:
+					stored = append(stored, readMetric(m))
+				case HeartbeatPrimaryLagDesc:
+					primary = append(primary, readMetric(m))
+				case HeartbeatPrimaryMissingDesc:
+					missing = readMetric(m).value
+				}
+			}
+			convey.Convey("The lag of the primary is exported", t, func() {
+				convey.So(stored, convey.ShouldResemble, tt.stored)
+				convey.So(primary, convey.ShouldResemble, tt.primary)
+				convey.So(missing, convey.ShouldEqual, tt.primaryMissed)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeHeartbeatInvalidPrimaryServerID(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.primary_server_id=-1"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	err := ScrapeHeartbeat{}.ValidateConfig()
+	convey.Convey("A negative primary server_id is rejected", t, func() {
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+	})
+}
+
+func TestScrapeHeartbeatLockWaitTimeout(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--collect.heartbeat.lock_wait_timeout=1500ms",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(sanitizeQuery("SET SESSION lock_wait_timeout = 2")).WillReturnResult(sqlmock.NewResult(0, 0))
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := runScraper(context.Background(), ScrapeHeartbeat{}, db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("The lock wait timeout is set in seconds before the heartbeat query", t, func() {
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+	})
+
+	if _, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.lock_wait_timeout=-1s"}); err != nil {
+		t.Fatal(err)
+	}
+	err = ScrapeHeartbeat{}.ValidateConfig()
+	convey.Convey("A negative lock wait timeout is rejected", t, func() {
+		convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+	})
+
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	convey.Convey("Without a lock wait timeout the session is left alone", t, func() {
+		convey.So(ScrapeHeartbeat{}.SessionInit(), convey.ShouldBeNil)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MetricHelp is the help text of the metrics of global_status and
+// global_variables by variable name, replacing their generic help text.
+type MetricHelp struct {
+	GlobalStatus    map[string]string `yaml:"global_status,omitempty"`
+	GlobalVariables map[string]string `yaml:"global_variables,omitempty"`
+}
+
+// metricHelp is applied to the metrics of global_status and global_variables.
+var metricHelp MetricHelp
+
+// SetMetricHelp sets the help text of the metrics of global_status and
+// global_variables. It must be called before an Exporter is registered.
+func SetMetricHelp(help MetricHelp) error {
+	if err := help.init(); err != nil {
+		return err
+	}
+	metricHelp = help
+	return nil
+}
+
+// LoadMetricHelp reads the help text of metrics from a YAML file.
+func LoadMetricHelp(path string) (MetricHelp, error) {
+	var help MetricHelp
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return help, err
+	}
+	if err := yaml.UnmarshalStrict(content, &help); err != nil {
+		return help, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := help.init(); err != nil {
+		return help, fmt.Errorf("%s: %w", path, err)
+	}
+	return help, nil
+}
+
+// init keys the help text by metric name, e.g. threads_running for
+// Threads_running, and rejects blank help text.
+func (h *MetricHelp) init() error {
+	for _, m := range []struct {
+		scraper string
+		help    *map[string]string
+	}{
+		{globalStatus, &h.GlobalStatus},
+		{globalVariables, &h.GlobalVariables},
+	} {
+		sanitized := make(map[string]string, len(*m.help))
+		for name, help := range *m.help {
+			if strings.TrimSpace(help) == "" {
+				return fmt.Errorf("%s: help of %s must not be blank", m.scraper, name)
+			}
+			sanitized[validPrometheusName(name)] = help
+		}
+		*m.help = sanitized
+	}
+	return nil
+}
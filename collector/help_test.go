@@ -0,0 +1,93 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoadMetricHelp(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "help.yml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	convey.Convey("Help text is keyed by metric name", t, func() {
+		help, err := LoadMetricHelp(write(`
+global_status:
+  Threads_running: Threads executing a query, alert above 64.
+global_variables:
+  max_connections: Connection limit, see the capacity runbook.
+`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(help.GlobalStatus, convey.ShouldResemble, map[string]string{"threads_running": "Threads executing a query, alert above 64."})
+		convey.So(help.GlobalVariables, convey.ShouldResemble, map[string]string{"max_connections": "Connection limit, see the capacity runbook."})
+	})
+
+	convey.Convey("Blank help text and unknown scrapers are rejected", t, func() {
+		for _, content := range []string{
+			"global_status:\n  Threads_running: ''\n",
+			"global_variables:\n  max_connections: ' '\n",
+			"slave_status:\n  seconds_behind_master: Replication lag.\n",
+		} {
+			_, err := LoadMetricHelp(write(content))
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestScrapeGlobalStatusHelp(t *testing.T) {
+	if err := SetMetricHelp(MetricHelp{GlobalStatus: map[string]string{"Threads_running": "Threads executing a query, alert above 64."}}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetMetricHelp(MetricHelp{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Threads_running", "7").
+			AddRow("Uptime", "86400"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeGlobalStatus{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	convey.Convey("The configured help text replaces the generic one", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, 2)
+		convey.So(metrics[0].Desc().String(), convey.ShouldContainSubstring, `help: "Threads executing a query, alert above 64."`)
+		convey.So(metrics[1].Desc().String(), convey.ShouldContainSubstring, `help: "Generic metric from SHOW GLOBAL STATUS."`)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
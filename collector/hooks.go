@@ -0,0 +1,132 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// scraperHookTimeout is how long a scraper waits for its ScraperHooks before
+// it reports its metrics anyway.
+const scraperHookTimeout = time.Second
+
+// PreScrapeHook is called before each collection cycle.
+type PreScrapeHook func(ctx context.Context)
+
+// PostScrapeHook is called after each collection cycle with its aggregate
+// error, nil when the connection and all scrapers succeeded.
+type PostScrapeHook func(ctx context.Context, err error)
+
+// ScraperHook is called after each run of a scraper with its name, its
+// error, nil when it succeeded, and how long it ran.
+type ScraperHook func(name string, err error, dur time.Duration)
+
+var scrapeHooks struct {
+	sync.RWMutex
+	pre     []PreScrapeHook
+	post    []PostScrapeHook
+	scraper []ScraperHook
+}
+
+// RegisterPreScrapeHook adds a hook run before each collection cycle. Hooks
+// run in registration order.
+func RegisterPreScrapeHook(hook PreScrapeHook) {
+	scrapeHooks.Lock()
+	defer scrapeHooks.Unlock()
+	scrapeHooks.pre = append(scrapeHooks.pre, hook)
+}
+
+// RegisterPostScrapeHook adds a hook run after each collection cycle. Hooks
+// run in registration order.
+func RegisterPostScrapeHook(hook PostScrapeHook) {
+	scrapeHooks.Lock()
+	defer scrapeHooks.Unlock()
+	scrapeHooks.post = append(scrapeHooks.post, hook)
+}
+
+// RegisterScraperHook adds a hook run after each run of every scraper. Hooks
+// run in registration order, concurrently for scrapers running concurrently.
+// A scraper waits at most scraperHookTimeout for its hooks, slower hooks keep
+// running in the background.
+func RegisterScraperHook(hook ScraperHook) {
+	scrapeHooks.Lock()
+	defer scrapeHooks.Unlock()
+	scrapeHooks.scraper = append(scrapeHooks.scraper, hook)
+}
+
+func hasScrapeHooks() bool {
+	scrapeHooks.RLock()
+	defer scrapeHooks.RUnlock()
+	return len(scrapeHooks.pre) > 0 || len(scrapeHooks.post) > 0
+}
+
+func runPreScrapeHooks(ctx context.Context) {
+	scrapeHooks.RLock()
+	hooks := scrapeHooks.pre
+	scrapeHooks.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+func runPostScrapeHooks(ctx context.Context, err error) {
+	scrapeHooks.RLock()
+	hooks := scrapeHooks.post
+	scrapeHooks.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx, err)
+	}
+}
+
+func runScraperHooks(logger log.Logger, name string, err error, dur time.Duration) {
+	scrapeHooks.RLock()
+	hooks := scrapeHooks.scraper
+	scrapeHooks.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, hook := range hooks {
+			hook(name, err, dur)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(scraperHookTimeout):
+		level.Warn(logger).Log("msg", "Scraper hooks did not return in time", "scraper", name, "timeout", scraperHookTimeout)
+	}
+}
+
+// scrapeErrors is the aggregate error of the scrapers of a collection cycle.
+type scrapeErrors []error
+
+func (e scrapeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e scrapeErrors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeHooks(t *testing.T) {
+	defer func() {
+		scrapeHooks.pre, scrapeHooks.post = nil, nil
+	}()
+
+	var (
+		events  []string
+		gotErrs []error
+	)
+	RegisterPreScrapeHook(func(ctx context.Context) { events = append(events, "pre1") })
+	RegisterPreScrapeHook(func(ctx context.Context) { events = append(events, "pre2") })
+	RegisterPostScrapeHook(func(ctx context.Context, err error) {
+		events = append(events, "post1")
+		gotErrs = append(gotErrs, err)
+	})
+	RegisterPostScrapeHook(func(ctx context.Context, err error) {
+		events = append(events, "post2")
+		gotErrs = append(gotErrs, err)
+	})
+
+	// Nothing listens on port 1, so the cycle fails on ping.
+	exporter := New(context.Background(), "root@tcp(127.0.0.1:1)/", []Scraper{fakeScraper{name: "unused"}}, log.NewNopLogger())
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.Collect(ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Hooks run in order around the collection cycle", t, func() {
+		convey.So(events, convey.ShouldResemble, []string{"pre1", "pre2", "post1", "post2"})
+		convey.So(gotErrs, convey.ShouldHaveLength, 2)
+		convey.So(gotErrs[0], convey.ShouldNotBeNil)
+		convey.So(gotErrs[1], convey.ShouldEqual, gotErrs[0])
+	})
+}
+
+func TestScrapeDBAggregateError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+
+	errFirst, errSecond := errors.New("first failed"), errors.New("second failed")
+	exporter := New(
+		context.Background(),
+		dsn,
+		[]Scraper{
+			fakeScraper{name: "first", err: errFirst},
+			fakeScraper{name: "ok"},
+			fakeScraper{name: "second", err: errSecond},
+		},
+		log.NewNopLogger(),
+	)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		err = exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("The errors of all failed scrapers are returned", t, func() {
+		var errs scrapeErrors
+		convey.So(errors.As(err, &errs), convey.ShouldBeTrue)
+		convey.So(errs, convey.ShouldHaveLength, 2)
+		convey.So(errors.Is(err, errFirst), convey.ShouldBeTrue)
+		convey.So(errors.Is(err, errSecond), convey.ShouldBeTrue)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "collect.first: first failed")
+		convey.So(err.Error(), convey.ShouldContainSubstring, "collect.second: second failed")
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScraperHooks(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+	defer func() {
+		scrapeHooks.scraper = nil
+	}()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	rows := sqlmock.NewRows([]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	var (
+		mu    sync.Mutex
+		names []string
+		errs  []error
+	)
+	RegisterScraperHook(func(name string, err error, dur time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, name)
+		errs = append(errs, err)
+	})
+	exporter := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger())
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+	for range ch {
+	}
+
+	convey.Convey("Hooks run after each scraper", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{"heartbeat"})
+		convey.So(errs, convey.ShouldResemble, []error{nil})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScraperHooksTimeout(t *testing.T) {
+	defer func() {
+		scrapeHooks.scraper = nil
+	}()
+
+	release := make(chan struct{})
+	defer close(release)
+	RegisterScraperHook(func(name string, err error, dur time.Duration) {
+		<-release
+	})
+
+	start := time.Now()
+	runScraperHooks(log.NewNopLogger(), "blocked", nil, 0)
+	convey.Convey("Blocked hooks do not block the scraper", t, func() {
+		convey.So(time.Since(start), convey.ShouldBeLessThan, 2*scraperHookTimeout)
+	})
+}
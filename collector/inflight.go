@@ -0,0 +1,187 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// inflightScrape is a scrape whose result is shared with the collections of
+// the same target started while it runs.
+type inflightScrape struct {
+	ctx     *sharedScrapeContext
+	done    chan struct{}
+	waiters int
+	// active is the number of collections still waiting for the scrape.
+	active  int
+	metrics []prometheus.Metric
+	up      float64
+	err     error
+}
+
+// inflightScrapes holds the running scrapes by target and collectors.
+// Exporters are created per request, so concurrent collections of the same
+// target use different Exporters.
+var inflightScrapes = struct {
+	sync.Mutex
+	scrapes map[string]*inflightScrape
+}{scrapes: map[string]*inflightScrape{}}
+
+// sharedScrape calls scrape unless a scrape for key is already running, in
+// which case it waits for that scrape instead. Either way it sends the
+// metrics of the scrape and returns its up value and error. It returns the
+// number of collections that shared the scrape besides the caller's, or -1 if
+// the caller waited for another scrape.
+//
+// The scrape does not run with the context of a single collection, so that a
+// collection giving up does not fail the others. Its context is done once
+// the latest deadline of the collections passed, or once none of them waits
+// anymore.
+func sharedScrape(ctx context.Context, key string, ch chan<- prometheus.Metric, scrape func(context.Context, chan<- prometheus.Metric) (float64, error)) (float64, int, error) {
+	inflightScrapes.Lock()
+	s, running := inflightScrapes.scrapes[key]
+	if running {
+		s.waiters++
+	} else {
+		s = &inflightScrape{ctx: newSharedScrapeContext(ctx), done: make(chan struct{})}
+		inflightScrapes.scrapes[key] = s
+		go s.run(key, scrape)
+	}
+	s.active++
+	s.ctx.extend(ctx)
+	inflightScrapes.Unlock()
+
+	waiters := -1
+	if !running {
+		waiters = 0
+	}
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		inflightScrapes.Lock()
+		s.active--
+		if s.active == 0 {
+			s.ctx.cancel(context.Canceled)
+		}
+		inflightScrapes.Unlock()
+		return 0, waiters, ctx.Err()
+	}
+	for _, m := range s.metrics {
+		ch <- m
+	}
+	if !running {
+		// No collection joins after done is closed.
+		waiters = s.waiters
+	}
+	return s.up, waiters, s.err
+}
+
+// run runs scrape with the shared context and keeps its result.
+func (s *inflightScrape) run(key string, scrape func(context.Context, chan<- prometheus.Metric) (float64, error)) {
+	buffered := make(chan prometheus.Metric)
+	go func() {
+		s.up, s.err = scrape(s.ctx, buffered)
+		close(buffered)
+	}()
+	for m := range buffered {
+		s.metrics = append(s.metrics, m)
+	}
+
+	inflightScrapes.Lock()
+	delete(inflightScrapes.scrapes, key)
+	inflightScrapes.Unlock()
+	s.ctx.cancel(context.Canceled)
+	close(s.done)
+}
+
+// sharedScrapeContext is the context of a shared scrape. It keeps the values
+// of the context of the collection that started the scrape, but not its
+// deadline or cancellation. It reports no deadline, as collections joining
+// the scrape may extend it.
+type sharedScrapeContext struct {
+	values context.Context
+	done   chan struct{}
+
+	mu        sync.Mutex
+	err       error
+	deadline  time.Time
+	unbounded bool
+	timer     *time.Timer
+}
+
+func newSharedScrapeContext(values context.Context) *sharedScrapeContext {
+	return &sharedScrapeContext{values: values, done: make(chan struct{})}
+}
+
+func (c *sharedScrapeContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c *sharedScrapeContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *sharedScrapeContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *sharedScrapeContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+// extend moves the deadline of the scrape to the deadline of ctx if it is
+// later. Without a deadline of ctx the scrape has none either.
+func (c *sharedScrapeContext) extend(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || c.unbounded {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if ok && !deadline.After(c.deadline) {
+		return
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if !ok {
+		c.unbounded = true
+		return
+	}
+	c.deadline = deadline
+	c.timer = time.AfterFunc(time.Until(deadline), func() {
+		c.cancel(context.DeadlineExceeded)
+	})
+}
+
+// cancel ends the context with err, unless it already ended.
+func (c *sharedScrapeContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	close(c.done)
+}
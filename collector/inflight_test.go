@@ -0,0 +1,211 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type sharedResult struct {
+	up      float64
+	err     error
+	metrics []MetricResult
+}
+
+// runSharedScrapes starts n concurrent collections of key. The scraper only
+// runs once all other collections wait for it.
+func runSharedScrapes(key string, n int, scraper Scraper) []sharedResult {
+	scrape := func(ctx context.Context, ch chan<- prometheus.Metric) (float64, error) {
+		for {
+			inflightScrapes.Lock()
+			waiters := inflightScrapes.scrapes[key].waiters
+			inflightScrapes.Unlock()
+			if waiters == n-1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := scraper.Scrape(ctx, nil, ch, log.NewNopLogger()); err != nil {
+			return 1, err
+		}
+		return 1, nil
+	}
+
+	results := make([]sharedResult, n)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *sharedResult) {
+			defer wg.Done()
+			ch := make(chan prometheus.Metric)
+			done := make(chan struct{})
+			go func() {
+				for m := range ch {
+					r.metrics = append(r.metrics, readMetric(m))
+				}
+				close(done)
+			}()
+			r.up, _, r.err = sharedScrape(context.Background(), key, ch, scrape)
+			close(ch)
+			<-done
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+func TestSharedScrape(t *testing.T) {
+	scrapes := 0
+	scraper := countingScraper{fakeScraper: fakeScraper{name: "counting"}, scrapes: &scrapes}
+	results := runSharedScrapes("shared", 5, scraper)
+
+	convey.Convey("Concurrent collections share a single scrape", t, func() {
+		convey.So(scrapes, convey.ShouldEqual, 1)
+		for _, r := range results {
+			convey.So(r.err, convey.ShouldBeNil)
+			convey.So(r.up, convey.ShouldEqual, 1)
+			convey.So(r.metrics, convey.ShouldResemble, []MetricResult{
+				{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+			})
+		}
+	})
+
+	convey.Convey("Later collections scrape again", t, func() {
+		runSharedScrapes("shared", 1, scraper)
+		convey.So(scrapes, convey.ShouldEqual, 2)
+	})
+}
+
+func TestSharedScrapeError(t *testing.T) {
+	scrapes := 0
+	failed := errors.New("failed")
+	scraper := countingScraper{fakeScraper: fakeScraper{name: "counting", err: failed}, scrapes: &scrapes}
+	results := runSharedScrapes("shared_error", 3, scraper)
+
+	convey.Convey("The error of a shared scrape is returned to all collections", t, func() {
+		convey.So(scrapes, convey.ShouldEqual, 1)
+		for _, r := range results {
+			convey.So(r.err, convey.ShouldEqual, failed)
+		}
+	})
+}
+
+// runAbandonedSharedScrape starts a collection of key with leaderCtx and a
+// second one sharing its scrape. Once both wait, leave is called and the
+// scrape waits for the first collection to stop waiting before it completes.
+// It returns the results of both collections and the error of the context of
+// the scrape when it completed.
+func runAbandonedSharedScrape(key string, leaderCtx context.Context, leave func()) (sharedResult, sharedResult, error) {
+	scraping := func(cond func(s *inflightScrape) bool) {
+		for {
+			inflightScrapes.Lock()
+			s, ok := inflightScrapes.scrapes[key]
+			done := ok && cond(s)
+			inflightScrapes.Unlock()
+			if done {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	var scrapeErr error
+	scrape := func(ctx context.Context, ch chan<- prometheus.Metric) (float64, error) {
+		scraping(func(s *inflightScrape) bool { return s.waiters == 1 })
+		leave()
+		scraping(func(s *inflightScrape) bool { return s.active == 1 })
+		scrapeErr = ctx.Err()
+		ch <- prometheus.MustNewConstMetric(countingScraperDesc, prometheus.GaugeValue, 1)
+		return 1, nil
+	}
+
+	collect := func(ctx context.Context, r *sharedResult, wg *sync.WaitGroup) {
+		defer wg.Done()
+		ch := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func() {
+			for m := range ch {
+				r.metrics = append(r.metrics, readMetric(m))
+			}
+			close(done)
+		}()
+		r.up, _, r.err = sharedScrape(ctx, key, ch, scrape)
+		close(ch)
+		<-done
+	}
+	var (
+		leader, waiter sharedResult
+		wg             sync.WaitGroup
+	)
+	wg.Add(2)
+	go collect(leaderCtx, &leader, &wg)
+	scraping(func(*inflightScrape) bool { return true })
+	waiterCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	go collect(waiterCtx, &waiter, &wg)
+	wg.Wait()
+	return leader, waiter, scrapeErr
+}
+
+func TestSharedScrapeOutlivesCollection(t *testing.T) {
+	complete := sharedResult{up: 1, metrics: []MetricResult{{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE}}}
+
+	convey.Convey("A waiting collection gets the whole scrape when the first one is cancelled", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		leader, waiter, scrapeErr := runAbandonedSharedScrape("shared_cancelled", ctx, cancel)
+		convey.So(leader.err, convey.ShouldEqual, context.Canceled)
+		convey.So(leader.metrics, convey.ShouldBeEmpty)
+		convey.So(scrapeErr, convey.ShouldBeNil)
+		convey.So(waiter, convey.ShouldResemble, complete)
+	})
+
+	convey.Convey("The scrape runs until the latest deadline of the collections", t, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		leader, waiter, scrapeErr := runAbandonedSharedScrape("shared_deadline", ctx, func() {})
+		convey.So(leader.err, convey.ShouldEqual, context.DeadlineExceeded)
+		convey.So(scrapeErr, convey.ShouldBeNil)
+		convey.So(waiter, convey.ShouldResemble, complete)
+	})
+
+	convey.Convey("The scrape is cancelled once no collection waits for it", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		key := "shared_abandoned"
+		scrapeCtx := make(chan context.Context, 1)
+		_, _, err := sharedScrape(ctx, key, nil, func(ctx context.Context, ch chan<- prometheus.Metric) (float64, error) {
+			scrapeCtx <- ctx
+			cancel()
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		convey.So(err, convey.ShouldEqual, context.Canceled)
+		select {
+		case ctx := <-scrapeCtx:
+			<-ctx.Done()
+			convey.So(ctx.Err(), convey.ShouldEqual, context.Canceled)
+		case <-time.After(time.Minute):
+			t.Fatal("the scrape did not start")
+		}
+	})
+}
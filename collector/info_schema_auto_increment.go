@@ -18,25 +18,40 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"math"
+	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const infoSchemaAutoIncrementQuery = `
 		SELECT table_schema, table_name, column_name, auto_increment,
-		  pow(2, case data_type
-		    when 'tinyint'   then 7
-		    when 'smallint'  then 15
-		    when 'mediumint' then 23
-		    when 'int'       then 31
-		    when 'bigint'    then 63
-		    end+(column_type like '% unsigned'))-1 as max_int
+		  data_type, column_type
 		  FROM information_schema.columns c
 		  STRAIGHT_JOIN information_schema.tables t USING (table_schema,table_name)
 		  WHERE c.extra = 'auto_increment' AND t.auto_increment IS NOT NULL
 		`
 
+// Tunable flags.
+var (
+	autoIncrementDatabases = kingpin.Flag(
+		"collect.auto_increment.columns.databases",
+		"The list of databases to collect auto_increment columns for, or '*' for all",
+	).Default("*").String()
+)
+
+// autoIncrementBits are the value bits of the signed integer types.
+var autoIncrementBits = map[string]float64{
+	"tinyint":   7,
+	"smallint":  15,
+	"mediumint": 23,
+	"int":       31,
+	"bigint":    63,
+}
+
 // Metric descriptors.
 var (
 	globalInfoSchemaAutoIncrementDesc = prometheus.NewDesc(
@@ -49,8 +64,28 @@ var (
 		"The max value of an auto_increment column from information_schema.",
 		[]string{"schema", "table", "column"}, nil,
 	)
+	globalInfoSchemaAutoIncrementRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "auto_increment_ratio"),
+		"The current value of an auto_increment column divided by the max value of its type.",
+		[]string{"schema", "table", "column"}, nil,
+	)
 )
 
+// autoIncrementMax returns the largest value of an integer column given its
+// data_type and column_type, e.g. "int" and "int(10) unsigned".
+func autoIncrementMax(dataType, columnType string) (float64, bool) {
+	bits, ok := autoIncrementBits[strings.ToLower(dataType)]
+	if !ok {
+		return 0, false
+	}
+	for _, attribute := range strings.Fields(strings.ToLower(columnType)) {
+		if attribute == "unsigned" {
+			bits++
+		}
+	}
+	return math.Pow(2, bits) - 1, true
+}
+
 // ScrapeAutoIncrementColumns collects auto_increment column information.
 type ScrapeAutoIncrementColumns struct{}
 
@@ -77,17 +112,34 @@ func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch cha
 	}
 	defer autoIncrementRows.Close()
 
+	var databases map[string]bool
+	if *autoIncrementDatabases != "*" {
+		databases = map[string]bool{}
+		for _, database := range strings.Split(*autoIncrementDatabases, ",") {
+			databases[database] = true
+		}
+	}
+
 	var (
 		schema, table, column string
-		value, max            float64
+		value                 float64
+		dataType, columnType  string
 	)
 
 	for autoIncrementRows.Next() {
 		if err := autoIncrementRows.Scan(
-			&schema, &table, &column, &value, &max,
+			&schema, &table, &column, &value, &dataType, &columnType,
 		); err != nil {
 			return err
 		}
+		if databases != nil && !databases[schema] {
+			continue
+		}
+		max, ok := autoIncrementMax(dataType, columnType)
+		if !ok {
+			level.Debug(logger).Log("msg", "Skipping auto_increment column of unknown type", "schema", schema, "table", table, "column", column, "data_type", dataType)
+			continue
+		}
 		ch <- prometheus.MustNewConstMetric(
 			globalInfoSchemaAutoIncrementDesc, prometheus.GaugeValue, value,
 			schema, table, column,
@@ -96,8 +148,12 @@ func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, db *sql.DB, ch cha
 			globalInfoSchemaAutoIncrementMaxDesc, prometheus.GaugeValue, max,
 			schema, table, column,
 		)
+		ch <- prometheus.MustNewConstMetric(
+			globalInfoSchemaAutoIncrementRatioDesc, prometheus.GaugeValue, value/max,
+			schema, table, column,
+		)
 	}
-	return nil
+	return autoIncrementRows.Err()
 }
 
 // check interface
@@ -0,0 +1,102 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestAutoIncrementMax(t *testing.T) {
+	convey.Convey("Max values account for the sign of the type", t, func() {
+		for _, tt := range []struct {
+			dataType, columnType string
+			want                 float64
+		}{
+			{"tinyint", "tinyint(4)", 127},
+			{"tinyint", "tinyint(3) unsigned", 255},
+			{"int", "int(11)", 2147483647},
+			{"int", "int(10) unsigned", 4294967295},
+			{"int", "int unsigned zerofill", 4294967295},
+			{"bigint", "bigint(20)", math.Pow(2, 63) - 1},
+			{"bigint", "bigint(20) unsigned", math.Pow(2, 64) - 1},
+		} {
+			got, ok := autoIncrementMax(tt.dataType, tt.columnType)
+			convey.So(ok, convey.ShouldBeTrue)
+			convey.So(got, convey.ShouldEqual, tt.want)
+		}
+		_, ok := autoIncrementMax("decimal", "decimal(10,0)")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
+
+func TestScrapeAutoIncrementColumns(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.auto_increment.columns.databases=app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--collect.auto_increment.columns.databases=*"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"table_schema", "table_name", "column_name", "auto_increment", "data_type", "column_type"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("app", "orders", "id", "3221225472", "int", "int(10) unsigned").
+		AddRow("app", "events", "id", "4611686018427387904", "bigint", "bigint(20)").
+		AddRow("other", "users", "id", "10", "int", "int(11)")
+	mock.ExpectQuery(sanitizeQuery(infoSchemaAutoIncrementQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeAutoIncrementColumns{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	orders := labelMap{"schema": "app", "table": "orders", "column": "id"}
+	events := labelMap{"schema": "app", "table": "events", "column": "id"}
+	metricExpected := []MetricResult{
+		{labels: orders, value: 3221225472, metricType: dto.MetricType_GAUGE},
+		{labels: orders, value: 4294967295, metricType: dto.MetricType_GAUGE},
+		{labels: orders, value: 3221225472 / 4294967295.0, metricType: dto.MetricType_GAUGE},
+		{labels: events, value: 4611686018427387904, metricType: dto.MetricType_GAUGE},
+		{labels: events, value: math.Pow(2, 63) - 1, metricType: dto.MetricType_GAUGE},
+		{labels: events, value: 0.5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		convey.So(got, convey.ShouldResemble, metricExpected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
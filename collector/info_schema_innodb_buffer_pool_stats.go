@@ -0,0 +1,142 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.INNODB_BUFFER_POOL_STATS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const innodbBufferPoolStatsQuery = `
+		SELECT
+		  pool_id, pool_size, free_buffers, modified_database_pages,
+		  number_pages_get, number_pages_read, hit_rate,
+		  pending_reads, pending_flush_lru, pending_flush_list, pending_decompress
+		  FROM information_schema.innodb_buffer_pool_stats
+		`
+
+// Metric descriptors.
+var (
+	infoSchemaInnodbBufferPoolPagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_pages"),
+		"Number of pages in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolFreePagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_free_pages"),
+		"Number of free pages in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolDirtyPagesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_dirty_pages"),
+		"Number of modified pages in the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolReadRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_read_requests_total"),
+		"Number of logical read requests to the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolReadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_reads_total"),
+		"Number of pages read from disk into the buffer pool instance.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolHitRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_hit_ratio"),
+		"Ratio of page reads served from the buffer pool instance since the InnoDB monitor was last printed.",
+		[]string{"pool_id"}, nil,
+	)
+	infoSchemaInnodbBufferPoolPendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "innodb_buffer_pool_pending_operations"),
+		"Number of pending operations of the buffer pool instance by operation.",
+		[]string{"pool_id", "operation"}, nil,
+	)
+)
+
+// ScrapeInnodbBufferPoolStats collects from `information_schema.innodb_buffer_pool_stats`.
+type ScrapeInnodbBufferPoolStats struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbBufferPoolStats) Name() string {
+	return informationSchema + ".innodb_buffer_pool_stats"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbBufferPoolStats) Help() string {
+	return "Collect metrics from information_schema.innodb_buffer_pool_stats"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbBufferPoolStats) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeInnodbBufferPoolStats) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbBufferPoolStats) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, innodbBufferPoolStatsQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		poolID                                                             string
+		size, free, dirty, readRequests, reads, hitRate                    float64
+		pendingReads, pendingFlushLRU, pendingFlushList, pendingDecompress float64
+		pools                                                              int
+	)
+	for rows.Next() {
+		if err := rows.Scan(
+			&poolID, &size, &free, &dirty, &readRequests, &reads, &hitRate,
+			&pendingReads, &pendingFlushLRU, &pendingFlushList, &pendingDecompress,
+		); err != nil {
+			return err
+		}
+		pools++
+
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolPagesDesc, prometheus.GaugeValue, size, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolFreePagesDesc, prometheus.GaugeValue, free, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolDirtyPagesDesc, prometheus.GaugeValue, dirty, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolReadRequestsDesc, prometheus.CounterValue, readRequests, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolReadsDesc, prometheus.CounterValue, reads, poolID)
+		// HIT_RATE is given per 1000 page reads.
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolHitRateDesc, prometheus.GaugeValue, hitRate/1000, poolID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolPendingDesc, prometheus.GaugeValue, pendingReads, poolID, "read")
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolPendingDesc, prometheus.GaugeValue, pendingFlushLRU, poolID, "flush_lru")
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolPendingDesc, prometheus.GaugeValue, pendingFlushList, poolID, "flush_list")
+		ch <- prometheus.MustNewConstMetric(infoSchemaInnodbBufferPoolPendingDesc, prometheus.GaugeValue, pendingDecompress, poolID, "decompress")
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if pools == 0 {
+		level.Debug(logger).Log("msg", "No InnoDB buffer pool stats, InnoDB may be disabled")
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbBufferPoolStats{}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbBufferPoolStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"pool_id", "pool_size", "free_buffers", "modified_database_pages",
+		"number_pages_get", "number_pages_read", "hit_rate",
+		"pending_reads", "pending_flush_lru", "pending_flush_list", "pending_decompress",
+	}
+	rows := sqlmock.NewRows(columns).
+		AddRow("0", 8191, 1024, 12, 500000, 2000, 999, 1, 0, 2, 0).
+		AddRow("1", 8192, 2048, 0, 300000, 1500, 1000, 0, 3, 0, 4)
+	mock.ExpectQuery(sanitizeQuery(innodbBufferPoolStatsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbBufferPoolStats{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"pool_id": "0"}, value: 8191, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 1024, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0"}, value: 500000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "0"}, value: 2000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "0"}, value: 0.999, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0", "operation": "read"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0", "operation": "flush_lru"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0", "operation": "flush_list"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "0", "operation": "decompress"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 8192, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 2048, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1"}, value: 300000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "1"}, value: 1500, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"pool_id": "1"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1", "operation": "read"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1", "operation": "flush_lru"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1", "operation": "flush_list"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"pool_id": "1", "operation": "decompress"}, value: 4, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(expect, convey.ShouldResemble, got)
+		}
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeInnodbBufferPoolStatsEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(innodbBufferPoolStatsQuery)).WillReturnRows(sqlmock.NewRows([]string{"pool_id"}))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbBufferPoolStats{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics without buffer pools", t, func() {
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
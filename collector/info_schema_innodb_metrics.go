@@ -171,28 +171,30 @@ func (ScrapeInnodbMetrics) Scrape(ctx context.Context, db *sql.DB, ch chan<- pro
 				continue
 			}
 		}
-		metricName := "innodb_metrics_" + subsystem + "_" + name
+		metricName := validPrometheusName("innodb_metrics_" + subsystem + "_" + name)
 		// MySQL returns counters named two different ways. "counter" and "status_counter"
 		// value >= 0 is necessary due to upstream bugs: http://bugs.mysql.com/bug.php?id=75966
 		if (metricType == "counter" || metricType == "status_counter") && value >= 0 {
 			description := prometheus.NewDesc(
 				prometheus.BuildFQName(namespace, informationSchema, metricName+"_total"),
-				comment, nil, nil,
+				comment, []string{"subsystem"}, nil,
 			)
 			ch <- prometheus.MustNewConstMetric(
 				description,
 				prometheus.CounterValue,
 				value,
+				subsystem,
 			)
 		} else {
 			description := prometheus.NewDesc(
 				prometheus.BuildFQName(namespace, informationSchema, metricName),
-				comment, nil, nil,
+				comment, []string{"subsystem"}, nil,
 			)
 			ch <- prometheus.MustNewConstMetric(
 				description,
 				prometheus.GaugeValue,
 				value,
+				subsystem,
 			)
 		}
 	}
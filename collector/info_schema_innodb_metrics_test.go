@@ -47,7 +47,8 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 		AddRow("buffer_pool_pages_dirty", "buffer", "gauge", "Number of dirt buffer pool pages", 5).
 		AddRow("buffer_pool_pages_data", "buffer", "gauge", "Number of data buffer pool pages", 6).
 		AddRow("buffer_pool_pages_total", "buffer", "gauge", "Number of total buffer pool pages", 7).
-		AddRow("NOPE", "buffer_page_io", "counter", "An invalid buffer_page_io metric", 999)
+		AddRow("NOPE", "buffer_page_io", "counter", "An invalid buffer_page_io metric", 999).
+		AddRow("trx_rseg_current_size-pages", "transaction", "value", "A name with invalid characters", 8)
 	query := fmt.Sprintf(infoSchemaInnodbMetricsQuery, "status", "enabled")
 	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
 
@@ -60,9 +61,9 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 	}()
 
 	metricExpected := []MetricResult{
-		{labels: labelMap{}, value: 0, metricType: dto.MetricType_COUNTER},
-		{labels: labelMap{}, value: 1, metricType: dto.MetricType_COUNTER},
-		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"subsystem": "lock"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"subsystem": "buffer"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"subsystem": "server"}, value: 2, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"type": "system_page"}, value: 3, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"type": "undo_log"}, value: 4, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{}, value: 5, metricType: dto.MetricType_GAUGE},
@@ -73,6 +74,12 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 			got := readMetric(<-ch)
 			convey.So(got, convey.ShouldResemble, expect)
 		}
+
+		m := <-ch
+		name, _, err := descNameHelp(m.Desc())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(name, convey.ShouldEqual, "mysql_info_schema_innodb_metrics_transaction_trx_rseg_current_size_pages")
+		convey.So(readMetric(m), convey.ShouldResemble, MetricResult{labels: labelMap{"subsystem": "transaction"}, value: 8, metricType: dto.MetricType_GAUGE})
 	})
 
 	// Ensure all SQL queries were executed
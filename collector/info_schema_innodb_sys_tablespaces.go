@@ -20,8 +20,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -47,6 +51,14 @@ const innodbTablespacesQuery = `
 	    ALLOCATED_SIZE
 	  FROM information_schema.` + "`%s`"
 
+// Tunable flags.
+var (
+	innodbTablespacesSpaceTypes = kingpin.Flag(
+		"collect.info_schema.innodb_tablespaces.space_types",
+		"The list of space types, e.g. 'Undo,System', to collect tablespaces of, or '*' for all",
+	).Default("*").String()
+)
+
 // Metric descriptors.
 var (
 	infoSchemaInnodbTablesspaceInfoDesc = prometheus.NewDesc(
@@ -102,10 +114,24 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 
 	tablespacesRows, err := db.QueryContext(ctx, query)
 	if err != nil {
+		// Forks and older versions may lack some of the columns.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1054 {
+			level.Debug(logger).Log("msg", "Tablespaces table lacks expected columns", "table", tablespacesTablename, "err", err)
+			return nil
+		}
 		return err
 	}
 	defer tablespacesRows.Close()
 
+	var spaceTypes map[string]bool
+	if *innodbTablespacesSpaceTypes != "*" {
+		spaceTypes = map[string]bool{}
+		for _, spaceType := range strings.Split(*innodbTablespacesSpaceTypes, ",") {
+			spaceTypes[strings.ToLower(strings.TrimSpace(spaceType))] = true
+		}
+	}
+
 	var (
 		tableSpace    uint32
 		tableName     string
@@ -129,6 +155,9 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 		if err != nil {
 			return err
 		}
+		if spaceTypes != nil && !spaceTypes[strings.ToLower(spaceType)] {
+			continue
+		}
 		ch <- prometheus.MustNewConstMetric(
 			infoSchemaInnodbTablesspaceInfoDesc, prometheus.GaugeValue, float64(tableSpace),
 			tableName, fileFormat, rowFormat, spaceType,
@@ -143,7 +172,7 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, db *sql.DB,
 		)
 	}
 
-	return nil
+	return tablespacesRows.Err()
 }
 
 // check interface
@@ -19,7 +19,9 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -73,3 +75,82 @@ func TestScrapeInfoSchemaInnodbTablespaces(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeInfoSchemaInnodbTablespacesSpaceTypes(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.info_schema.innodb_tablespaces.space_types=Undo,System"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(innodbTablespacesTablenameQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("INNODB_TABLESPACES"))
+	columns := []string{"SPACE", "NAME", "FILE_FORMAT", "ROW_FORMAT", "SPACE_TYPE", "FILE_SIZE", "ALLOCATED_SIZE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(4294967294, "mysql", "NONE", "Any", "General", 100, 100).
+		AddRow(4294967279, "innodb_undo_001", "NONE", "Undo", "Undo", 16777216, 16777216).
+		AddRow(4294967278, "innodb_system", "NONE", "Any", "System", 12582912, 12582912).
+		AddRow(7, "db/t1", "NONE", "Dynamic", "Single", 114688, 114688)
+	query := fmt.Sprintf(innodbTablespacesQuery, "INNODB_TABLESPACES", "INNODB_TABLESPACES")
+	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInfoSchemaInnodbTablespaces{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var names []string
+	for m := range ch {
+		names = append(names, readMetric(m).labels["tablespace_name"])
+	}
+	convey.Convey("Only tablespaces of the given space types are collected", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{
+			"innodb_undo_001", "innodb_undo_001", "innodb_undo_001",
+			"innodb_system", "innodb_system", "innodb_system",
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeInfoSchemaInnodbTablespacesMissingColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(innodbTablespacesTablenameQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("INNODB_SYS_TABLESPACES"))
+	query := fmt.Sprintf(innodbTablespacesQuery, "INNODB_SYS_TABLESPACES", "INNODB_SYS_TABLESPACES")
+	mock.ExpectQuery(sanitizeQuery(query)).WillReturnError(&mysql.MySQLError{Number: 1054, Message: "Unknown column 'ALLOCATED_SIZE' in 'field list'"})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInfoSchemaInnodbTablespaces{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Tablespaces without the expected columns are skipped", t, func() {
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the age of running transactions from `information_schema.innodb_trx`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// longTransactionsQuery selects the age of all running transactions by the
+// clock of the server.
+const longTransactionsQuery = `
+	SELECT TIMESTAMPDIFF(SECOND, trx_started, NOW())
+	  FROM information_schema.innodb_trx
+	`
+
+// Tunable flags.
+var (
+	longTransactionsMinAge = kingpin.Flag(
+		"collect.info_schema.innodb_trx.min_age",
+		"Minimum age of a transaction to be counted as long running",
+	).Default("60s").Duration()
+)
+
+// Metric descriptors.
+var (
+	innodbLongTransactionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "long_transactions"),
+		"Number of InnoDB transactions running for longer than collect.info_schema.innodb_trx.min_age.",
+		nil, nil,
+	)
+	innodbOldestTransactionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "innodb", "oldest_transaction_seconds"),
+		"Age of the oldest running InnoDB transaction, 0 without transactions.",
+		nil, nil,
+	)
+)
+
+// ScrapeLongTransactions collects the number of long running transactions
+// and the age of the oldest. Long transactions hold back purge, so that the
+// history list grows.
+type ScrapeLongTransactions struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeLongTransactions) Name() string {
+	return informationSchema + ".innodb_trx"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeLongTransactions) Help() string {
+	return "Collect the number of long running transactions and the age of the oldest from information_schema.innodb_trx"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeLongTransactions) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeLongTransactions) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeLongTransactions) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	trxRows, err := db.QueryContext(ctx, longTransactionsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer trxRows.Close()
+
+	minAge := longTransactionsMinAge.Seconds()
+	var long, oldest float64
+	for rows := 0; trxRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		var age float64
+		if err := trxRows.Scan(&age); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if age >= minAge {
+			long++
+		}
+		if age > oldest {
+			oldest = age
+		}
+	}
+	if err := trxRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(innodbLongTransactionsDesc, prometheus.GaugeValue, long)
+	ch <- prometheus.MustNewConstMetric(innodbOldestTransactionDesc, prometheus.GaugeValue, oldest)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeLongTransactions{}
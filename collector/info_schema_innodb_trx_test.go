@@ -0,0 +1,83 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeLongTransactions(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.info_schema.innodb_trx.min_age=30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	for _, tt := range []struct {
+		name     string
+		ages     []string
+		expected []MetricResult
+	}{
+		{
+			name: "One old and several young transactions",
+			ages: []string{"0", "2", "1800", "29", "30"},
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 1800, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "No running transactions",
+			expected: []MetricResult{
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		rows := sqlmock.NewRows([]string{"TIMESTAMPDIFF(SECOND, trx_started, NOW())"})
+		for _, age := range tt.ages {
+			rows.AddRow(age)
+		}
+		mock.ExpectQuery(sanitizeQuery(longTransactionsQuery)).WillReturnRows(rows)
+
+		metrics, err := CollectOnce(context.Background(), ScrapeLongTransactions{}, db, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("error calling function on test: %s", err)
+		}
+		convey.Convey(tt.name, t, func() {
+			convey.So(metrics, convey.ShouldHaveLength, len(tt.expected))
+			for i, expect := range tt.expected {
+				convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+			}
+		})
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
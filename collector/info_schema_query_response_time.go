@@ -18,15 +18,29 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math"
 	"strconv"
 	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const queryResponseCheckQuery = `SELECT @@query_response_time_stats`
+const (
+	queryResponseCheckQuery = `SELECT @@query_response_time_stats`
+	// queryResponseTimeTooLong is the time of the bucket of queries slower
+	// than the largest bucket.
+	queryResponseTimeTooLong = "TOO LONG"
+)
+
+var queryResponseTimeReadWrite = kingpin.Flag(
+	"collect.info_schema.query_response_time.read_write",
+	"Also collect the read and write query response time distributions of Percona Server 5.6/5.7.",
+).Default("true").Bool()
 
 var (
 	// Use uppercase for table names, otherwise read/write split will return the same results as total
@@ -82,21 +96,31 @@ func processQueryResponseTimeTable(ctx context.Context, db *sql.DB, ch chan<- pr
 			return err
 		}
 
+		histogramCnt += count
+		// The "TOO LONG" row has no total, only a count.
+		if strings.TrimSpace(length) == queryResponseTimeTooLong {
+			countBuckets[math.Inf(1)] = histogramCnt
+			continue
+		}
 		length, _ := strconv.ParseFloat(strings.TrimSpace(length), 64)
 		total, _ := strconv.ParseFloat(strings.TrimSpace(total), 64)
-		histogramCnt += count
 		histogramSum += total
-		// Special case for "TOO LONG" row where we take into account the count field which is the only available
-		// and do not add it as a part of histogram or metric
 		if length == 0 {
 			continue
 		}
 		countBuckets[length] = histogramCnt
 	}
+	if err := queryDistributionRows.Err(); err != nil {
+		return err
+	}
 	// Create histogram with query counts
-	ch <- prometheus.MustNewConstHistogram(
+	histogram, err := newLatencyHistogram(
 		infoSchemaQueryResponseTimeCountDescs[i], histogramCnt, histogramSum, countBuckets,
 	)
+	if err != nil {
+		return err
+	}
+	ch <- histogram
 	return nil
 }
 
@@ -132,10 +156,18 @@ func (ScrapeQueryResponseTime) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 	}
 
 	for i, query := range queryResponseTimeQueries {
+		if i > 0 && !*queryResponseTimeReadWrite {
+			break
+		}
 		err := processQueryResponseTimeTable(ctx, db, ch, query, i)
 		// The first query should not fail if query_response_time_stats is ON,
 		// unlike the other two when the read/write tables exist only with Percona Server 5.6/5.7.
 		if i == 0 && err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+				level.Debug(logger).Log("msg", "Query response time plugin is not installed.")
+				return nil
+			}
 			return err
 		}
 	}
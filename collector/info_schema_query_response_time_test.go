@@ -15,10 +15,13 @@ package collector
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -60,19 +63,20 @@ func TestScrapeQueryResponseTime(t *testing.T) {
 
 	// Test histogram
 	expectCounts := map[float64]uint64{
-		1e-06:  124,
-		1e-05:  303,
-		0.0001: 3162,
-		0.001:  4247,
-		0.01:   4516,
-		0.1:    4527,
-		1:      4528,
-		10:     4528,
-		100:    4528,
-		1000:   4528,
-		10000:  4528,
-		100000: 4528,
-		1e+06:  4528,
+		1e-06:       124,
+		1e-05:       303,
+		0.0001:      3162,
+		0.001:       4247,
+		0.01:        4516,
+		0.1:         4527,
+		1:           4528,
+		10:          4528,
+		100:         4528,
+		1000:        4528,
+		10000:       4528,
+		100000:      4528,
+		1e+06:       4528,
+		math.Inf(1): 4528,
 	}
 	expectHistogram := prometheus.MustNewConstHistogram(infoSchemaQueryResponseTimeCountDescs[0],
 		4528, 1.5773549999999998, expectCounts)
@@ -91,3 +95,79 @@ func TestScrapeQueryResponseTime(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeQueryResponseTimeReadWrite(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--no-collect.info_schema.query_response_time.read_write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(queryResponseCheckQuery).WillReturnRows(sqlmock.NewRows([]string{""}).AddRow(1))
+	rows := sqlmock.NewRows([]string{"TIME", "COUNT", "TOTAL"}).
+		AddRow(0.000001, 1, 0.000001).
+		AddRow("TOO LONG", 2, "TOO LONG")
+	mock.ExpectQuery(sanitizeQuery(queryResponseTimeQueries[0])).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeQueryResponseTime{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var got []prometheus.Metric
+	for m := range ch {
+		got = append(got, m)
+	}
+	convey.Convey("Only the total distribution is collected", t, func() {
+		convey.So(got, convey.ShouldHaveLength, 1)
+		pb := &dto.Metric{}
+		convey.So(got[0].Write(pb), convey.ShouldBeNil)
+		convey.So(pb.Histogram.GetSampleCount(), convey.ShouldEqual, 3)
+		buckets := pb.Histogram.GetBucket()
+		convey.So(buckets, convey.ShouldHaveLength, 2)
+		convey.So(math.IsInf(buckets[1].GetUpperBound(), 1), convey.ShouldBeTrue)
+		convey.So(buckets[1].GetCumulativeCount(), convey.ShouldEqual, 3)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeQueryResponseTimeMissingTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(queryResponseCheckQuery).WillReturnRows(sqlmock.NewRows([]string{""}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(queryResponseTimeQueries[0])).
+		WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'information_schema.QUERY_RESPONSE_TIME' doesn't exist"})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeQueryResponseTime{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics are collected", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the number of events, triggers and routines by schema.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	infoSchemaEventsQuery   = `SELECT event_schema, COUNT(*) FROM information_schema.events GROUP BY event_schema`
+	infoSchemaTriggersQuery = `SELECT trigger_schema, COUNT(*) FROM information_schema.triggers GROUP BY trigger_schema`
+	infoSchemaRoutinesQuery = `SELECT routine_schema, COUNT(*) FROM information_schema.routines GROUP BY routine_schema`
+)
+
+// Tunable flags.
+var (
+	schemaObjectsDatabases = kingpin.Flag(
+		"collect.info_schema.schema_objects.databases",
+		"The list of databases to count events, triggers and routines for, or '*' for all",
+	).Default("*").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaEventsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "events"),
+		"The number of events from information_schema.events.",
+		[]string{"schema"}, nil,
+	)
+	infoSchemaTriggersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "triggers"),
+		"The number of triggers from information_schema.triggers.",
+		[]string{"schema"}, nil,
+	)
+	infoSchemaRoutinesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "routines"),
+		"The number of stored procedures and functions from information_schema.routines.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// ScrapeSchemaObjects collects the number of events, triggers and routines.
+type ScrapeSchemaObjects struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaObjects) Name() string {
+	return informationSchema + ".schema_objects"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaObjects) Help() string {
+	return "Collect the number of events, triggers and routines by schema from information_schema"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaObjects) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeSchemaObjects) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+// The objects are counted independently, so that missing privileges on one
+// table do not prevent the others from being counted.
+func (ScrapeSchemaObjects) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var databases map[string]bool
+	if *schemaObjectsDatabases != "*" {
+		databases = map[string]bool{}
+		for _, database := range strings.Split(*schemaObjectsDatabases, ",") {
+			databases[database] = true
+		}
+	}
+
+	var firstErr error
+	failed := 0
+	for _, objects := range []struct {
+		query string
+		desc  *prometheus.Desc
+	}{
+		{infoSchemaEventsQuery, infoSchemaEventsDesc},
+		{infoSchemaTriggersQuery, infoSchemaTriggersDesc},
+		{infoSchemaRoutinesQuery, infoSchemaRoutinesDesc},
+	} {
+		if err := scrapeSchemaObjectCounts(ctx, db, ch, objects.query, objects.desc, databases); err != nil {
+			level.Warn(logger).Log("msg", "Error counting schema objects", "query", objects.query, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if failed == 3 {
+		return firstErr
+	}
+	return nil
+}
+
+// scrapeSchemaObjectCounts sends the counts by schema returned by query.
+func scrapeSchemaObjectCounts(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, query string, desc *prometheus.Desc, databases map[string]bool) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer rows.Close()
+
+	var (
+		schema string
+		count  float64
+	)
+	for rows.Next() {
+		if err := rows.Scan(&schema, &count); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if databases != nil && !databases[schema] {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, count, schema)
+	}
+	return wrapDriverError(rows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaObjects{}
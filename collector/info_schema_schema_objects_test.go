@@ -0,0 +1,79 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSchemaObjects(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.info_schema.schema_objects.databases=shop,billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(infoSchemaEventsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"event_schema", "COUNT(*)"}).AddRow("shop", 2))
+	// A missing privilege on triggers does not prevent counting routines.
+	mock.ExpectQuery(sanitizeQuery(infoSchemaTriggersQuery)).WillReturnError(
+		&mysql.MySQLError{Number: 1142, Message: "SELECT command denied"})
+	mock.ExpectQuery(sanitizeQuery(infoSchemaRoutinesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"routine_schema", "COUNT(*)"}).
+			AddRow("billing", 5).
+			AddRow("other", 1).
+			AddRow("shop", 3))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSchemaObjects{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "billing"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"schema": "shop"}, value: 3, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Objects of the selected databases are counted", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
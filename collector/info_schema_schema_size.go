@@ -0,0 +1,140 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the size of schemas from `information_schema.tables`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const schemaSizeQuery = `
+	SELECT
+	    TABLE_SCHEMA,
+	    COUNT(*) as TABLE_COUNT,
+	    ifnull(SUM(DATA_LENGTH), 0) as DATA_LENGTH,
+	    ifnull(SUM(INDEX_LENGTH), 0) as INDEX_LENGTH
+	  FROM information_schema.tables
+	  WHERE TABLE_TYPE = 'BASE TABLE'
+	    AND TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	  GROUP BY TABLE_SCHEMA
+	`
+
+// Tunable flags.
+var (
+	schemaSizeDatabases = kingpin.Flag(
+		"collect.info_schema.schema_size.databases",
+		"The list of databases to collect the size of, or '*' for all",
+	).Default("*").String()
+	schemaSizeExcludeDatabases = kingpin.Flag(
+		"collect.info_schema.schema_size.exclude_databases",
+		"The list of databases not to collect the size of, applied after collect.info_schema.schema_size.databases",
+	).Default("").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaSchemaSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "schema_size_bytes"),
+		"The data and index length of the tables of the schema from information_schema.tables.",
+		[]string{"schema"}, nil,
+	)
+	infoSchemaSchemaTableCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "schema_table_count"),
+		"The number of base tables of the schema from information_schema.tables.",
+		[]string{"schema"}, nil,
+	)
+)
+
+// databaseSet returns the databases of a comma separated list, or nil for
+// '*' or an empty list.
+func databaseSet(list string) map[string]bool {
+	if list == "*" || list == "" {
+		return nil
+	}
+	databases := map[string]bool{}
+	for _, database := range strings.Split(list, ",") {
+		databases[database] = true
+	}
+	return databases
+}
+
+// ScrapeSchemaSize collects the size and number of tables of each schema,
+// the low cardinality companion of info_schema.tables.
+type ScrapeSchemaSize struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSchemaSize) Name() string {
+	return informationSchema + ".schema_size"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSchemaSize) Help() string {
+	return "Collect the size and number of tables of schemas from information_schema.tables"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSchemaSize) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeSchemaSize) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSchemaSize) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	databases := databaseSet(*schemaSizeDatabases)
+	excluded := databaseSet(*schemaSizeExcludeDatabases)
+
+	schemaRows, err := db.QueryContext(ctx, schemaSizeQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer schemaRows.Close()
+
+	var (
+		schema                  string
+		tables                  uint64
+		dataLength, indexLength uint64
+	)
+	for schemaRows.Next() {
+		if err := schemaRows.Scan(&schema, &tables, &dataLength, &indexLength); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if (databases != nil && !databases[schema]) || excluded[schema] {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaSchemaSizeDesc, prometheus.GaugeValue, float64(dataLength+indexLength), schema,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaSchemaTableCountDesc, prometheus.GaugeValue, float64(tables), schema,
+		)
+	}
+	if err := schemaRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeSchemaSize{}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSchemaSize(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		args     []string
+		expected []MetricResult
+	}{
+		{
+			name: "all databases",
+			expected: []MetricResult{
+				{labels: labelMap{"schema": "shop"}, value: 16777216, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "shop"}, value: 2, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "blog"}, value: 32768, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "blog"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "staging"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "staging"}, value: 3, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "filtered databases",
+			args: []string{
+				"--collect.info_schema.schema_size.databases=shop,staging",
+				"--collect.info_schema.schema_size.exclude_databases=staging",
+			},
+			expected: []MetricResult{
+				{labels: labelMap{"schema": "shop"}, value: 16777216, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "shop"}, value: 2, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := kingpin.CommandLine.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			// One row per schema, as grouped by the server.
+			columns := []string{"TABLE_SCHEMA", "TABLE_COUNT", "DATA_LENGTH", "INDEX_LENGTH"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("shop", 2, 12582912, 4194304).
+				AddRow("blog", 1, 16384, 16384).
+				AddRow("staging", 3, 0, 0)
+			mock.ExpectQuery(sanitizeQuery(schemaSizeQuery)).WillReturnRows(rows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeSchemaSize{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("Metrics comparison", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the free space of tables from `information_schema.tables`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const tableFragmentationQuery = `
+	SELECT
+	    TABLE_SCHEMA,
+	    TABLE_NAME,
+	    ifnull(DATA_LENGTH, 0) as DATA_LENGTH,
+	    ifnull(INDEX_LENGTH, 0) as INDEX_LENGTH,
+	    ifnull(DATA_FREE, 0) as DATA_FREE
+	  FROM information_schema.tables
+	  WHERE TABLE_TYPE = 'BASE TABLE'
+	    AND TABLE_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema', 'sys')
+	`
+
+// Tunable flags.
+var (
+	tableFragmentationDatabases = kingpin.Flag(
+		"collect.info_schema.table_fragmentation.databases",
+		"The list of databases to collect table free space for, or '*' for all",
+	).Default("*").String()
+)
+
+// Metric descriptors.
+var (
+	infoSchemaTableDataFreeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "table_data_free_bytes"),
+		"Allocated but unused bytes of the table from information_schema.tables.",
+		[]string{"schema", "table"}, nil,
+	)
+	infoSchemaTableFragmentationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "table_fragmentation_ratio"),
+		"Ratio of the free bytes of the table to its data and index length.",
+		[]string{"schema", "table"}, nil,
+	)
+)
+
+// ScrapeTableFragmentation collects the free space of tables, e.g. to find
+// candidates for OPTIMIZE TABLE.
+type ScrapeTableFragmentation struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTableFragmentation) Name() string {
+	return informationSchema + ".table_fragmentation"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTableFragmentation) Help() string {
+	return "Collect the free space and fragmentation ratio of tables from information_schema.tables"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTableFragmentation) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeTableFragmentation) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTableFragmentation) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var databases map[string]bool
+	if *tableFragmentationDatabases != "*" {
+		databases = map[string]bool{}
+		for _, database := range strings.Split(*tableFragmentationDatabases, ",") {
+			databases[database] = true
+		}
+	}
+
+	tableRows, err := db.QueryContext(ctx, tableFragmentationQuery)
+	if err != nil {
+		return err
+	}
+	defer tableRows.Close()
+
+	var (
+		schema, table                     string
+		dataLength, indexLength, dataFree uint64
+	)
+	for rows := 0; tableRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		if rowLimitReached(rows, ScrapeTableFragmentation{}.Name(), ch, logger) {
+			return nil
+		}
+		if err := tableRows.Scan(&schema, &table, &dataLength, &indexLength, &dataFree); err != nil {
+			return err
+		}
+		if databases != nil && !databases[schema] {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			infoSchemaTableDataFreeDesc, prometheus.GaugeValue, float64(dataFree), schema, table,
+		)
+		// Empty tables have no meaningful ratio.
+		if size := dataLength + indexLength; size > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				infoSchemaTableFragmentationDesc, prometheus.GaugeValue, float64(dataFree)/float64(size), schema, table,
+			)
+		}
+	}
+	return tableRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeTableFragmentation{}
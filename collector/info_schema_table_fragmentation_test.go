@@ -0,0 +1,138 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTableFragmentation(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		databases string
+		expected  []MetricResult
+	}{
+		{
+			name:      "all databases",
+			databases: "*",
+			expected: []MetricResult{
+				{labels: labelMap{"schema": "shop", "table": "orders"}, value: 4194304, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "shop", "table": "orders"}, value: 0.25, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "shop", "table": "empty"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "blog", "table": "posts"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "blog", "table": "posts"}, value: 0, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name:      "filtered databases",
+			databases: "blog",
+			expected: []MetricResult{
+				{labels: labelMap{"schema": "blog", "table": "posts"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"schema": "blog", "table": "posts"}, value: 0, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kingpin.CommandLine.Parse([]string{"--collect.info_schema.table_fragmentation.databases", tt.databases})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			columns := []string{"TABLE_SCHEMA", "TABLE_NAME", "DATA_LENGTH", "INDEX_LENGTH", "DATA_FREE"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("shop", "orders", 12582912, 4194304, 4194304).
+				AddRow("shop", "empty", 0, 0, 0).
+				AddRow("blog", "posts", 16384, 16384, 0)
+			mock.ExpectQuery(sanitizeQuery(tableFragmentationQuery)).WillReturnRows(rows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeTableFragmentation{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("Metrics comparison", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeTableFragmentationMaxRows(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.max_rows=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"TABLE_SCHEMA", "TABLE_NAME", "DATA_LENGTH", "INDEX_LENGTH", "DATA_FREE"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("shop", "orders", 12582912, 4194304, 4194304).
+		AddRow("blog", "posts", 16384, 16384, 0)
+	mock.ExpectQuery(sanitizeQuery(tableFragmentationQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeTableFragmentation{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var tables, truncated []MetricResult
+	for _, m := range metrics {
+		switch m.Desc() {
+		case mysqlScrapeTruncated:
+			truncated = append(truncated, readMetric(m))
+		case infoSchemaTableDataFreeDesc:
+			tables = append(tables, readMetric(m))
+		}
+	}
+	convey.Convey("The scrape stops after exporter.max_rows rows", t, func() {
+		convey.So(tables, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"schema": "shop", "table": "orders"}, value: 4194304, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(truncated, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"collector": "collect.info_schema.table_fragmentation"}, value: 1, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -120,6 +120,7 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		dbList = strings.Split(*tableSchemaDatabases, ",")
 	}
 
+	rows := 0
 	for _, database := range dbList {
 		tableSchemaRows, err := db.QueryContext(ctx, fmt.Sprintf(tableSchemaQuery, database))
 		if err != nil {
@@ -142,6 +143,13 @@ func (ScrapeTableSchema) Scrape(ctx context.Context, db *sql.DB, ch chan<- prome
 		)
 
 		for tableSchemaRows.Next() {
+			if err := checkCtx(ctx, rows); err != nil {
+				return err
+			}
+			if rowLimitReached(rows, ScrapeTableSchema{}.Name(), ch, logger) {
+				return nil
+			}
+			rows++
 			err = tableSchemaRows.Scan(
 				&tableSchema,
 				&tableName,
@@ -0,0 +1,181 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `information_schema.tp_thread_group_stats` and
+// `information_schema.tp_thread_state`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const threadPoolGroupStatsQuery = `
+	SELECT
+		TP_GROUP_ID,
+		CONNECTIONS_STARTED,
+		CONNECTIONS_CLOSED,
+		QUERIES_EXECUTED,
+		QUERIES_QUEUED,
+		THREADS_STARTED,
+		PRIO_KICKUPS,
+		STALLED_QUERIES_EXECUTED
+	FROM information_schema.tp_thread_group_stats
+	`
+
+const threadPoolThreadStateQuery = `
+	SELECT
+		TP_GROUP_ID,
+		COUNT(*),
+		SUM(TIME_OF_ATTACH IS NOT NULL)
+	FROM information_schema.tp_thread_state
+	GROUP BY TP_GROUP_ID
+	`
+
+// Metric descriptors.
+var (
+	infoSchemaThreadPoolConnectionsStartedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_connections_started_total"),
+		"Number of connections started in the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolConnectionsClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_connections_closed_total"),
+		"Number of connections closed in the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolQueriesExecutedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_queries_executed_total"),
+		"Number of statements executed in the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolQueriesQueuedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_queries_queued_total"),
+		"Number of statements that had to wait in the queue of the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolThreadsStartedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_threads_started_total"),
+		"Number of threads started in the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolPrioKickupsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_prio_kickups_total"),
+		"Number of statements moved from the low to the high priority queue of the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolStalledQueriesExecutedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_stalled_queries_executed_total"),
+		"Number of statements of the thread group that became stalled.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_threads"),
+		"Number of threads of the thread group.",
+		[]string{"group_id"}, nil,
+	)
+	infoSchemaThreadPoolActiveThreadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, informationSchema, "thread_pool_active_threads"),
+		"Number of threads of the thread group attached to a connection.",
+		[]string{"group_id"}, nil,
+	)
+)
+
+// ScrapeThreadPool collects the per thread group statistics of the thread
+// pool plugin, which help tuning thread_pool_size.
+type ScrapeThreadPool struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeThreadPool) Name() string {
+	return informationSchema + ".thread_pool"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeThreadPool) Help() string {
+	return "Collect thread pool metrics from information_schema.tp_thread_group_stats and tp_thread_state"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeThreadPool) Version() float64 {
+	return 5.5
+}
+
+// Stability of the Scraper.
+func (ScrapeThreadPool) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeThreadPool) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	groupRows, err := db.QueryContext(ctx, threadPoolGroupStatsQuery)
+	if err != nil {
+		// The tables only exist while the thread pool plugin is loaded.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "Thread pool plugin is not loaded", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer groupRows.Close()
+
+	var (
+		groupID                                                string
+		connectionsStarted, connectionsClosed                  float64
+		queriesExecuted, queriesQueued, threadsStarted         float64
+		prioKickups, stalledQueriesExecuted, threads, attached float64
+	)
+	for groupRows.Next() {
+		if err := groupRows.Scan(
+			&groupID, &connectionsStarted, &connectionsClosed, &queriesExecuted, &queriesQueued,
+			&threadsStarted, &prioKickups, &stalledQueriesExecuted,
+		); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolConnectionsStartedDesc, prometheus.CounterValue, connectionsStarted, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolConnectionsClosedDesc, prometheus.CounterValue, connectionsClosed, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolQueriesExecutedDesc, prometheus.CounterValue, queriesExecuted, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolQueriesQueuedDesc, prometheus.CounterValue, queriesQueued, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolThreadsStartedDesc, prometheus.CounterValue, threadsStarted, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolPrioKickupsDesc, prometheus.CounterValue, prioKickups, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolStalledQueriesExecutedDesc, prometheus.CounterValue, stalledQueriesExecuted, groupID)
+	}
+	if err := groupRows.Err(); err != nil {
+		return err
+	}
+
+	stateRows, err := db.QueryContext(ctx, threadPoolThreadStateQuery)
+	if err != nil {
+		return err
+	}
+	defer stateRows.Close()
+
+	for stateRows.Next() {
+		if err := stateRows.Scan(&groupID, &threads, &attached); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolThreadsDesc, prometheus.GaugeValue, threads, groupID)
+		ch <- prometheus.MustNewConstMetric(infoSchemaThreadPoolActiveThreadsDesc, prometheus.GaugeValue, attached, groupID)
+	}
+	return stateRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeThreadPool{}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeThreadPool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	groupColumns := []string{
+		"TP_GROUP_ID", "CONNECTIONS_STARTED", "CONNECTIONS_CLOSED", "QUERIES_EXECUTED", "QUERIES_QUEUED",
+		"THREADS_STARTED", "PRIO_KICKUPS", "STALLED_QUERIES_EXECUTED",
+	}
+	groupRows := sqlmock.NewRows(groupColumns).
+		AddRow("0", 10, 8, 1000, 50, 4, 3, 2).
+		AddRow("1", 12, 11, 2000, 70, 5, 0, 1)
+	mock.ExpectQuery(sanitizeQuery(threadPoolGroupStatsQuery)).WillReturnRows(groupRows)
+
+	stateRows := sqlmock.NewRows([]string{"TP_GROUP_ID", "COUNT(*)", "SUM(TIME_OF_ATTACH IS NOT NULL)"}).
+		AddRow("0", 4, 1).
+		AddRow("1", 5, 3)
+	mock.ExpectQuery(sanitizeQuery(threadPoolThreadStateQuery)).WillReturnRows(stateRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadPool{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{"group_id": "0"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 8, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 50, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 4, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 11, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 2000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 70, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "1"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"group_id": "0"}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "0"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "1"}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"group_id": "1"}, value: 3, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeThreadPoolNotLoaded(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(threadPoolGroupStatsQuery)).
+		WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Unknown table 'TP_THREAD_GROUP_STATS' in information_schema"})
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeThreadPool{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics are collected", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
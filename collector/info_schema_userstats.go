@@ -21,13 +21,23 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const userStatQuery = `SELECT * FROM information_schema.user_statistics`
 
+// Tunable flags.
+var (
+	userStatRequired = kingpin.Flag(
+		"collect.info_schema.userstats.userstat_required",
+		"Fail the scrape instead of skipping it when user statistics are not available",
+	).Default("false").Bool()
+)
+
 var (
 	// Map known user-statistics values to types. Unknown types will be mapped as
 	// untyped.
@@ -161,16 +171,26 @@ func (ScrapeUserStat) Scrape(ctx context.Context, db *sql.DB, ch chan<- promethe
 	var varName, varVal string
 	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
+		if *userStatRequired {
+			return fmt.Errorf("detailed user stats are not available: %w", err)
+		}
 		level.Debug(logger).Log("msg", "Detailed user stats are not available.")
 		return nil
 	}
 	if varVal == "OFF" {
+		if *userStatRequired {
+			return fmt.Errorf("MySQL variable %s is OFF", varName)
+		}
 		level.Debug(logger).Log("msg", "MySQL variable is OFF.", "var", varName)
 		return nil
 	}
 
 	informationSchemaUserStatisticsRows, err := db.QueryContext(ctx, userStatQuery)
 	if err != nil {
+		if mysqlErr, ok := err.(*MySQL.MySQLError); ok && mysqlErr.Number == 1109 && !*userStatRequired {
+			level.Warn(logger).Log("msg", "Table information_schema.user_statistics does not exist, is userstat enabled?", "err", err)
+			return nil
+		}
 		return err
 	}
 	defer informationSchemaUserStatisticsRows.Close()
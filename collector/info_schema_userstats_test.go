@@ -15,10 +15,13 @@ package collector
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	MySQL "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/smartystreets/goconvey/convey"
@@ -83,3 +86,78 @@ func TestScrapeUserStat(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapeUserStatUnknownTable(t *testing.T) {
+	for _, required := range []bool{false, true} {
+		args := []string{"--no-collect.info_schema.userstats.userstat_required"}
+		if required {
+			args = []string{"--collect.info_schema.userstats.userstat_required"}
+		}
+		if _, err := kingpin.CommandLine.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		mock.ExpectQuery(sanitizeQuery(userstatCheckQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("userstat", "ON"))
+		mock.ExpectQuery(sanitizeQuery(userStatQuery)).
+			WillReturnError(&MySQL.MySQLError{Number: 1109, Message: "Unknown table 'USER_STATISTICS' in information_schema"})
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			err = (ScrapeUserStat{}).Scrape(context.Background(), db, ch, log.NewNopLogger())
+			close(ch)
+		}()
+
+		convey.Convey(fmt.Sprintf("Unknown table with userstat_required=%t", required), t, func() {
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+			if required {
+				convey.So(err, convey.ShouldNotBeNil)
+			} else {
+				convey.So(err, convey.ShouldBeNil)
+			}
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+
+	if _, err := kingpin.CommandLine.Parse([]string{"--no-collect.info_schema.userstats.userstat_required"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScrapeUserStatRequiredOff(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--collect.info_schema.userstats.userstat_required"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--no-collect.info_schema.userstats.userstat_required"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(userstatCheckQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+		AddRow("userstat", "OFF"))
+
+	ch := make(chan prometheus.Metric)
+	convey.Convey("userstat=OFF fails when required", t, func() {
+		err := (ScrapeUserStat{}).Scrape(context.Background(), db, ch, log.NewNopLogger())
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
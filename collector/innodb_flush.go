@@ -0,0 +1,127 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape InnoDB flush stats from `SHOW GLOBAL STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// innodbFlush is the Metric subsystem we use.
+	innodbFlush = "innodb_flush"
+	// innodbFlushQuery selects the status variables related to flushing.
+	// Page cleaner variables only exist in some forks and versions.
+	innodbFlushQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Innodb_buffer_pool_pages_flushed',
+		'Innodb_data_pending_fsyncs',
+		'Innodb_data_pending_writes',
+		'Innodb_os_log_pending_fsyncs',
+		'Innodb_os_log_pending_writes'
+	) OR Variable_name LIKE 'Innodb_page_cleaner%'`
+)
+
+// Metric descriptors.
+var (
+	innodbFlushPagesFlushedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbFlush, "pages_flushed_total"),
+		"Number of pages flushed from the InnoDB buffer pool.",
+		nil, nil,
+	)
+	innodbFlushPendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbFlush, "pending_operations"),
+		"Number of pending InnoDB flush operations.",
+		[]string{"operation"}, nil,
+	)
+)
+
+// innodbFlushPending maps the pending status variables to operation labels.
+var innodbFlushPending = map[string]string{
+	"innodb_data_pending_fsyncs":   "data_fsync",
+	"innodb_data_pending_writes":   "data_write",
+	"innodb_os_log_pending_fsyncs": "log_fsync",
+	"innodb_os_log_pending_writes": "log_write",
+}
+
+// ScrapeInnodbFlush collects InnoDB flush and page cleaner stats.
+type ScrapeInnodbFlush struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbFlush) Name() string {
+	return innodbFlush
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbFlush) Help() string {
+	return "Collect InnoDB flush and page cleaner stats from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbFlush) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeInnodbFlush) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbFlush) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	flushRows, err := db.QueryContext(ctx, innodbFlushQuery)
+	if err != nil {
+		return err
+	}
+	defer flushRows.Close()
+
+	var key string
+	var val sql.RawBytes
+
+	for flushRows.Next() {
+		if err := flushRows.Scan(&key, &val); err != nil {
+			return err
+		}
+		floatVal, ok := parseStatus(val)
+		if !ok { // Unparsable values are silently skipped.
+			continue
+		}
+		key = sanitizeMetricName(key)
+		switch {
+		case key == "innodb_buffer_pool_pages_flushed":
+			ch <- prometheus.MustNewConstMetric(
+				innodbFlushPagesFlushedDesc, prometheus.CounterValue, floatVal,
+			)
+		case innodbFlushPending[key] != "":
+			ch <- prometheus.MustNewConstMetric(
+				innodbFlushPendingDesc, prometheus.GaugeValue, floatVal, innodbFlushPending[key],
+			)
+		case strings.HasPrefix(key, "innodb_page_cleaner_"):
+			ch <- prometheus.MustNewConstMetric(
+				newDesc(innodbFlush, strings.TrimPrefix(key, "innodb_"), "Generic InnoDB page cleaner metric from SHOW GLOBAL STATUS."),
+				prometheus.UntypedValue,
+				floatVal,
+			)
+		}
+	}
+	return flushRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbFlush{}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeInnodbFlush(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Innodb_buffer_pool_pages_flushed", "1234").
+		AddRow("Innodb_data_pending_fsyncs", "1").
+		AddRow("Innodb_data_pending_writes", "2").
+		AddRow("Innodb_os_log_pending_fsyncs", "3").
+		AddRow("Innodb_os_log_pending_writes", "4").
+		AddRow("Innodb_page_cleaner_lag", "5")
+	mock.ExpectQuery(sanitizeQuery(innodbFlushQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbFlush{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{}, value: 1234, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"operation": "data_fsync"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "data_write"}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "log_fsync"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"operation": "log_write"}, value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 5, metricType: dto.MetricType_UNTYPED},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeInnodbFlushMissingVariables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Innodb_buffer_pool_pages_flushed", "1234")
+	mock.ExpectQuery(sanitizeQuery(innodbFlushQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeInnodbFlush{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var got []MetricResult
+	for m := range ch {
+		got = append(got, readMetric(m))
+	}
+	convey.Convey("Only present variables are exported", t, func() {
+		convey.So(got, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 1234, metricType: dto.MetricType_COUNTER},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the InnoDB checkpoint age from `SHOW GLOBAL STATUS` or the LOG
+// section of `SHOW ENGINE INNODB STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// innodbRedoLog is the Metric subsystem we use.
+	innodbRedoLog = "innodb"
+	// innodbRedoLogStatusQuery selects the LSNs exported since 8.0.30.
+	innodbRedoLogStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN ('Innodb_redo_log_current_lsn', 'Innodb_redo_log_checkpoint_lsn')`
+)
+
+// Metric descriptors.
+var (
+	innodbLogSequenceNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbRedoLog, "log_sequence_number"),
+		"The current log sequence number (LSN) of InnoDB.",
+		nil, nil,
+	)
+	innodbCheckpointLSNDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbRedoLog, "last_checkpoint_lsn"),
+		"The log sequence number of the last InnoDB checkpoint.",
+		nil, nil,
+	)
+	innodbCheckpointAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, innodbRedoLog, "checkpoint_age_bytes"),
+		"Bytes of redo log written since the last checkpoint. Writes stall when it reaches the redo log capacity.",
+		nil, nil,
+	)
+)
+
+// parseInnodbLSN parses the LSN fields of a line of the LOG section. Before
+// 5.5 the LSN is printed as its high and low 32 bits.
+func parseInnodbLSN(fields []string) (uint64, bool) {
+	switch len(fields) {
+	case 1:
+		lsn, err := strconv.ParseUint(fields[0], 10, 64)
+		return lsn, err == nil
+	case 2:
+		high, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		low, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		return high<<32 | low, true
+	}
+	return 0, false
+}
+
+// parseInnodbStatusLSNs returns the log sequence number and the last
+// checkpoint of the LOG section of SHOW ENGINE INNODB STATUS.
+func parseInnodbStatusLSNs(status string) (lsn, checkpoint uint64, ok bool) {
+	var hasLSN, hasCheckpoint bool
+	for _, line := range strings.Split(status, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Log sequence number"):
+			lsn, hasLSN = parseInnodbLSN(strings.Fields(strings.TrimPrefix(line, "Log sequence number")))
+		case strings.HasPrefix(line, "Last checkpoint at"):
+			checkpoint, hasCheckpoint = parseInnodbLSN(strings.Fields(strings.TrimPrefix(line, "Last checkpoint at")))
+		}
+	}
+	return lsn, checkpoint, hasLSN && hasCheckpoint
+}
+
+// checkpointAge returns the redo log written since the checkpoint. The two
+// LSNs are not read atomically, so a checkpoint ahead of the LSN is no age.
+func checkpointAge(lsn, checkpoint uint64) uint64 {
+	if checkpoint > lsn {
+		return 0
+	}
+	return lsn - checkpoint
+}
+
+// ScrapeInnodbRedoLog collects the InnoDB checkpoint age, which predicts
+// write stalls once it approaches the redo log capacity.
+type ScrapeInnodbRedoLog struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeInnodbRedoLog) Name() string {
+	return "innodb_redo_log"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeInnodbRedoLog) Help() string {
+	return "Collect the InnoDB log sequence number and checkpoint age from SHOW GLOBAL STATUS or SHOW ENGINE INNODB STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeInnodbRedoLog) Version() float64 {
+	return 5.5
+}
+
+// Stability of the Scraper.
+func (ScrapeInnodbRedoLog) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeInnodbRedoLog) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	lsn, checkpoint, ok, err := innodbRedoLogStatusLSNs(ctx, db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		var typeCol, nameCol, statusCol string
+		if err := db.QueryRowContext(ctx, engineInnodbStatusQuery).Scan(&typeCol, &nameCol, &statusCol); err != nil {
+			return wrapDriverError(err)
+		}
+		if lsn, checkpoint, ok = parseInnodbStatusLSNs(statusCol); !ok {
+			level.Debug(logger).Log("msg", "No LSNs found in the LOG section of SHOW ENGINE INNODB STATUS")
+			return nil
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(innodbLogSequenceNumberDesc, prometheus.GaugeValue, float64(lsn))
+	ch <- prometheus.MustNewConstMetric(innodbCheckpointLSNDesc, prometheus.GaugeValue, float64(checkpoint))
+	ch <- prometheus.MustNewConstMetric(innodbCheckpointAgeDesc, prometheus.GaugeValue, float64(checkpointAge(lsn, checkpoint)))
+	return nil
+}
+
+// innodbRedoLogStatusLSNs returns the LSNs of the status variables, which
+// only exist since 8.0.30.
+func innodbRedoLogStatusLSNs(ctx context.Context, db *sql.DB) (lsn, checkpoint uint64, ok bool, err error) {
+	rows, err := db.QueryContext(ctx, innodbRedoLogStatusQuery)
+	if err != nil {
+		return 0, 0, false, wrapDriverError(err)
+	}
+	defer rows.Close()
+
+	var (
+		key, val              string
+		hasLSN, hasCheckpoint bool
+	)
+	for rows.Next() {
+		if err := rows.Scan(&key, &val); err != nil {
+			return 0, 0, false, newScrapeError(ErrParse, err)
+		}
+		value, parseErr := strconv.ParseUint(val, 10, 64)
+		switch {
+		case parseErr != nil:
+		case key == "Innodb_redo_log_current_lsn":
+			lsn, hasLSN = value, true
+		case key == "Innodb_redo_log_checkpoint_lsn":
+			checkpoint, hasCheckpoint = value, true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, false, wrapDriverError(err)
+	}
+	return lsn, checkpoint, hasLSN && hasCheckpoint, nil
+}
+
+// check interface
+var _ Scraper = ScrapeInnodbRedoLog{}
@@ -0,0 +1,132 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const innodbStatusLogSection = `
+=====================================
+2023-06-12 10:21:45 140245609551616 INNODB MONITOR OUTPUT
+=====================================
+Per second averages calculated from the last 6 seconds
+---
+LOG
+---
+Log sequence number          4373522395
+Log buffer assigned up to    4373522395
+Log buffer completed up to   4373522395
+Log written up to            4373522395
+Log flushed up to            4373522395
+Added dirty pages up to      4373522395
+Pages flushed up to          4371403043
+Last checkpoint at           4371380437
+1794 log i/o's done, 0.00 log i/o's/second
+----------------------
+BUFFER POOL AND MEMORY
+----------------------
+`
+
+func TestParseInnodbStatusLSNs(t *testing.T) {
+	convey.Convey("LSNs are parsed from the LOG section", t, func() {
+		lsn, checkpoint, ok := parseInnodbStatusLSNs(innodbStatusLogSection)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(lsn, convey.ShouldEqual, 4373522395)
+		convey.So(checkpoint, convey.ShouldEqual, 4371380437)
+		convey.So(checkpointAge(lsn, checkpoint), convey.ShouldEqual, 2141958)
+	})
+
+	convey.Convey("LSNs printed as high and low 32 bits are combined", t, func() {
+		lsn, checkpoint, ok := parseInnodbStatusLSNs("Log sequence number 1 3211338\nLast checkpoint at  0 4294967295\n")
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(lsn, convey.ShouldEqual, 1<<32+3211338)
+		convey.So(checkpoint, convey.ShouldEqual, 4294967295)
+		convey.So(checkpointAge(lsn, checkpoint), convey.ShouldEqual, 3211339)
+	})
+
+	convey.Convey("Missing or garbled LSNs are not parsed", t, func() {
+		_, _, ok := parseInnodbStatusLSNs("Log sequence number 4373522395\n")
+		convey.So(ok, convey.ShouldBeFalse)
+		_, _, ok = parseInnodbStatusLSNs("Log sequence number n/a\nLast checkpoint at 4371380437\n")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("A checkpoint read ahead of the LSN is no age", t, func() {
+		convey.So(checkpointAge(100, 120), convey.ShouldEqual, 0)
+	})
+}
+
+func TestScrapeInnodbRedoLog(t *testing.T) {
+	convey.Convey("Status variables are preferred", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(innodbRedoLogStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).
+			AddRow("Innodb_redo_log_checkpoint_lsn", "4371380437").
+			AddRow("Innodb_redo_log_current_lsn", "4373522395"))
+
+		metrics, err := CollectOnce(context.Background(), ScrapeInnodbRedoLog{}, db, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldHaveLength, 3)
+		convey.So(readMetric(metrics[0]), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 4373522395, metricType: dto.MetricType_GAUGE})
+		convey.So(readMetric(metrics[1]), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 4371380437, metricType: dto.MetricType_GAUGE})
+		convey.So(readMetric(metrics[2]), convey.ShouldResemble, MetricResult{labels: labelMap{}, value: 2141958, metricType: dto.MetricType_GAUGE})
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+	})
+
+	convey.Convey("SHOW ENGINE INNODB STATUS is parsed before 8.0.30", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(innodbRedoLogStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+		mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+			AddRow("InnoDB", "", innodbStatusLogSection))
+
+		metrics, err := CollectOnce(context.Background(), ScrapeInnodbRedoLog{}, db, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldHaveLength, 3)
+		convey.So(readMetric(metrics[2]).value, convey.ShouldEqual, 2141958)
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+	})
+
+	convey.Convey("Nothing is collected without a LOG section", t, func() {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(innodbRedoLogStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+		mock.ExpectQuery(sanitizeQuery(engineInnodbStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+			AddRow("InnoDB", "", "garbled"))
+
+		metrics, err := CollectOnce(context.Background(), ScrapeInnodbRedoLog{}, db, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldBeEmpty)
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+	})
+}
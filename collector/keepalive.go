@@ -0,0 +1,162 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// keepaliveTicker returns the ticks of the keep-warm loop and a function
+// stopping them, replaced in tests.
+var keepaliveTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(interval)
+	return t.C, t.Stop
+}
+
+// warmDBIdleIntervals is the number of keep-warm intervals after which a
+// pool that no scrape used is closed, e.g. of a /probe target that is no
+// longer scraped.
+const warmDBIdleIntervals = 10
+
+// warmDB is a connection pool kept open across scrapes.
+type warmDB struct {
+	db  *sql.DB
+	dsn string
+	// lastUsed is the time of the last scrape using the pool, guarded by
+	// warmDBs.
+	lastUsed time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// close stops the keep-warm loop of the pool and closes it.
+func (w *warmDB) close() {
+	close(w.stop)
+	<-w.done
+	w.closeDB()
+}
+
+// closeDB closes the statements of exporter.prepared_statements prepared on
+// the pool, which are reused across scrapes, and the connections of the pool.
+func (w *warmDB) closeDB() {
+	preparedStatements.closeDB(w.db)
+	w.db.Close()
+}
+
+// warmDBs are the pools of exporter.keepalive_interval by dsnKey. A rotated
+// password replaces the pool of the server, so that the old pool does not
+// keep pinging with stale credentials.
+var warmDBs = struct {
+	sync.Mutex
+	dbs map[string]*warmDB
+}{dbs: map[string]*warmDB{}}
+
+// openWarmDB returns the pool of dsn that is kept open across scrapes,
+// opening it and starting its keep-warm loop on first use, or when the
+// password of dsn changed.
+func openWarmDB(dsn string, interval time.Duration, logger log.Logger) (*sql.DB, error) {
+	key := dsnKey(dsn)
+	warmDBs.Lock()
+	w, ok := warmDBs.dbs[key]
+	if ok && w.dsn == dsn {
+		w.lastUsed = time.Now()
+		warmDBs.Unlock()
+		return w.db, nil
+	}
+	if ok {
+		delete(warmDBs.dbs, key)
+		// Deferred before the unlock, so that the keep-warm loop, which
+		// may be pinging, is stopped without holding the lock.
+		defer w.close()
+		level.Debug(logger).Log("msg", "Replacing connection pool after the DSN changed")
+	}
+	defer warmDBs.Unlock()
+	// Close sets closed before closing the pools, so no pool is opened
+	// after them.
+	if isClosed() {
+		return nil, ErrClosed
+	}
+	db, err := openCountingDB(mysqlDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(*exporterMaxOpenConns)
+	db.SetMaxIdleConns(*exporterMaxIdleConns)
+	db.SetConnMaxLifetime(*exporterConnMaxLifetime)
+
+	w = &warmDB{db: db, dsn: dsn, lastUsed: time.Now(), stop: make(chan struct{}), done: make(chan struct{})}
+	ticks, stopTicks := keepaliveTicker(interval)
+	go func() {
+		defer close(w.done)
+		defer stopTicks()
+		keepWarm(key, w, interval, ticks, logger)
+	}()
+	warmDBs.dbs[key] = w
+	return db, nil
+}
+
+// keepWarm pings the pool of w on every tick until stop is closed, so that an
+// idle connection survives until the next scrape. A pool no scrape used for
+// warmDBIdleIntervals intervals is closed instead.
+func keepWarm(key string, w *warmDB, interval time.Duration, ticks <-chan time.Time, logger log.Logger) {
+	for {
+		select {
+		case <-w.stop:
+			return
+		case now := <-ticks:
+			if evictWarmDB(key, w, now.Add(-warmDBIdleIntervals*interval)) {
+				level.Debug(logger).Log("msg", "Closing unused connection pool", "idle_intervals", warmDBIdleIntervals)
+				w.closeDB()
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := w.db.PingContext(ctx); err != nil {
+				level.Debug(logger).Log("msg", "Error keeping connection warm", "err", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// evictWarmDB removes w from warmDBs if no scrape used it since before.
+func evictWarmDB(key string, w *warmDB, before time.Time) bool {
+	warmDBs.Lock()
+	defer warmDBs.Unlock()
+	if !w.lastUsed.Before(before) {
+		return false
+	}
+	if warmDBs.dbs[key] == w {
+		delete(warmDBs.dbs, key)
+	}
+	return true
+}
+
+// CloseWarmDBs stops the keep-warm loops of exporter.keepalive_interval and
+// closes their connections.
+func CloseWarmDBs() {
+	warmDBs.Lock()
+	dbs := warmDBs.dbs
+	warmDBs.dbs = map[string]*warmDB{}
+	warmDBs.Unlock()
+	// The keep-warm loops take the lock to close unused pools.
+	for _, w := range dbs {
+		w.close()
+	}
+}
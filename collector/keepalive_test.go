@@ -0,0 +1,158 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestKeepalive(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--exporter.keepalive_interval=30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	mockDB, mock, err := sqlmock.NewWithDSN(addDSNParams("keepalive"), sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	mysqlDriver = mockDB.Driver()
+	defer func() { mysqlDriver = &mysql.MySQLDriver{} }()
+
+	ticks := make(chan time.Time)
+	var (
+		intervals []time.Duration
+		stopped   bool
+	)
+	keepaliveTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+		intervals = append(intervals, interval)
+		return ticks, func() { stopped = true }
+	}
+	defer func() {
+		keepaliveTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+			t := time.NewTicker(interval)
+			return t.C, t.Stop
+		}
+	}()
+
+	// Both scrapes use the pool opened by the first one.
+	for i := 0; i < 2; i++ {
+		mock.ExpectPing()
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	}
+	// The pool is pinged on every tick in between.
+	mock.ExpectPing()
+	mock.ExpectPing()
+
+	e := New(context.Background(), "keepalive", nil, log.NewNopLogger())
+	convey.Convey("Connections are kept warm across scrapes", t, func() {
+		convey.So(collectUp(e), convey.ShouldEqual, 1)
+		convey.So(collectUp(e), convey.ShouldEqual, 1)
+		ticks <- time.Now()
+		ticks <- time.Now()
+		CloseWarmDBs()
+
+		convey.So(intervals, convey.ShouldResemble, []time.Duration{30 * time.Second})
+		convey.So(stopped, convey.ShouldBeTrue)
+		convey.So(warmDBs.dbs, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+// isDBClosed reports whether db was closed, which database/sql only reports
+// with an unexported error.
+func isDBClosed(db *sql.DB) bool {
+	err := db.PingContext(context.Background())
+	return err != nil && err.Error() == "sql: database is closed"
+}
+
+// stubKeepaliveTicker replaces the ticks of keep-warm loops with ticks and
+// returns a function restoring them.
+func stubKeepaliveTicker(ticks chan time.Time) func() {
+	keepaliveTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+		return ticks, func() {}
+	}
+	return func() {
+		keepaliveTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+			t := time.NewTicker(interval)
+			return t.C, t.Stop
+		}
+	}
+}
+
+func TestKeepaliveRotatedPassword(t *testing.T) {
+	defer stubKeepaliveTicker(make(chan time.Time))()
+	defer CloseWarmDBs()
+
+	// Opening a pool does not connect.
+	old, err := openWarmDB("exporter:token1@tcp(db1:3306)/", time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := openWarmDB("exporter:token2@tcp(db1:3306)/", time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := openWarmDB("exporter:token2@tcp(db1:3306)/", time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("A rotated password replaces the pool of the server", t, func() {
+		convey.So(rotated, convey.ShouldNotEqual, old)
+		convey.So(again, convey.ShouldEqual, rotated)
+		convey.So(isDBClosed(old), convey.ShouldBeTrue)
+		convey.So(warmDBs.dbs, convey.ShouldHaveLength, 1)
+	})
+}
+
+func TestKeepaliveClosesUnusedPool(t *testing.T) {
+	ticks := make(chan time.Time)
+	defer stubKeepaliveTicker(ticks)()
+	defer CloseWarmDBs()
+
+	db, err := openWarmDB("exporter@tcp(probed:3306)/", time.Minute, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticks <- time.Now().Add(warmDBIdleIntervals*time.Minute + time.Second)
+	warmDBs.Lock()
+	w := warmDBs.dbs[dsnKey("exporter@tcp(probed:3306)/")]
+	warmDBs.Unlock()
+
+	convey.Convey("A pool no scrape used for a while is closed", t, func() {
+		convey.So(w, convey.ShouldBeNil)
+		// The loop closes the pool after removing it.
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) && !isDBClosed(db) {
+			time.Sleep(time.Millisecond)
+		}
+		convey.So(isDBClosed(db), convey.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,307 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// constLabels are added to all exported metrics.
+var constLabels prometheus.Labels
+
+// SetConstLabels adds labels, e.g. cluster or role, to all exported metrics.
+// Metrics that already have one of the labels keep their own value. It must
+// be called before an Exporter is registered.
+func SetConstLabels(labels prometheus.Labels) error {
+	for name := range labels {
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+	}
+	constLabels = labels
+	return nil
+}
+
+// descRE extracts the quoted fully-qualified name and help from Desc.String().
+var descRE = regexp.MustCompile(`^Desc{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"),`)
+
+// descNameHelp returns the fully-qualified name and help of a descriptor.
+func descNameHelp(desc *prometheus.Desc) (string, string, error) {
+	match := descRE.FindStringSubmatch(desc.String())
+	if match == nil {
+		return "", "", fmt.Errorf("unable to parse descriptor %s", desc)
+	}
+	name, err := strconv.Unquote(match[1])
+	if err != nil {
+		return "", "", err
+	}
+	help, err := strconv.Unquote(match[2])
+	if err != nil {
+		return "", "", err
+	}
+	return name, help, nil
+}
+
+// allCollectors is the collector name of labels dropped from all collectors.
+const allCollectors = "*"
+
+// parseDropLabels parses "<collector>=<label>" entries into the labels to
+// drop per collector name, allCollectors applying to all collectors.
+func parseDropLabels(entries []string) (map[string][]string, error) {
+	dropLabels := map[string][]string{}
+	for _, entry := range entries {
+		collector, label, ok := strings.Cut(entry, "=")
+		if !ok || collector == "" || label == "" {
+			return nil, fmt.Errorf("invalid drop label %q, expected <collector>=<label>", entry)
+		}
+		dropLabels[collector] = append(dropLabels[collector], label)
+	}
+	return dropLabels, nil
+}
+
+// aggregatedMetric accumulates the samples of metrics that collapse into the
+// same series once labels are dropped.
+type aggregatedMetric struct {
+	desc      *prometheus.Desc
+	values    []string
+	valueType prometheus.ValueType
+	value     float64
+	histogram bool
+	count     uint64
+	sum       float64
+	buckets   map[float64]uint64
+	// native holds the summed native buckets of histograms while all of
+	// them have native buckets.
+	native *nativeBuckets
+}
+
+func (a *aggregatedMetric) add(pb *dto.Metric) {
+	switch {
+	case pb.Histogram != nil:
+		a.count += pb.Histogram.GetSampleCount()
+		a.sum += pb.Histogram.GetSampleSum()
+		for _, b := range pb.Histogram.GetBucket() {
+			a.buckets[b.GetUpperBound()] += b.GetCumulativeCount()
+		}
+		if a.native != nil {
+			native, ok := nativeBucketCounts(pb.Histogram)
+			if !ok {
+				a.native = nil
+				break
+			}
+			a.native.zero += native.zero
+			for index, count := range native.counts {
+				a.native.counts[index] += count
+			}
+		}
+	case a.valueType == prometheus.CounterValue:
+		a.value += pb.Counter.GetValue()
+	case a.valueType == prometheus.GaugeValue:
+		a.value = math.Max(a.value, pb.Gauge.GetValue())
+	default:
+		a.value = math.Max(a.value, pb.Untyped.GetValue())
+	}
+}
+
+func (a *aggregatedMetric) metric() (prometheus.Metric, error) {
+	if a.histogram {
+		m, err := prometheus.NewConstHistogram(a.desc, a.count, a.sum, a.buckets, a.values...)
+		if err != nil || a.native == nil {
+			return m, err
+		}
+		return withNativeHistogram(m, nativeHistogram(*a.native)), nil
+	}
+	return prometheus.NewConstMetric(a.desc, a.valueType, a.value, a.values...)
+}
+
+// dropMetricLabels reads metrics from in until it is closed, removes the
+// given labels and sends the result to out. Metrics that end up with identical
+// label sets are merged: counters and histograms are summed, gauges and
+// untyped metrics keep the highest value.
+func dropMetricLabels(in <-chan prometheus.Metric, out chan<- prometheus.Metric, labels []string) error {
+	drop := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		drop[l] = true
+	}
+
+	var (
+		firstErr   error
+		order      []string
+		aggregated = map[string]*aggregatedMetric{}
+	)
+	for m := range in {
+		name, help, err := descNameHelp(m.Desc())
+		if err != nil {
+			firstErr = err
+			continue
+		}
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			firstErr = err
+			continue
+		}
+
+		var names, values []string
+		for _, lp := range pb.GetLabel() {
+			if drop[lp.GetName()] {
+				continue
+			}
+			names = append(names, lp.GetName())
+			values = append(values, lp.GetValue())
+		}
+		key := name + "\xff" + strings.Join(values, "\xff")
+
+		a, ok := aggregated[key]
+		if !ok {
+			a = &aggregatedMetric{
+				desc:      prometheus.NewDesc(name, help, names, nil),
+				values:    values,
+				histogram: pb.Histogram != nil,
+				buckets:   map[float64]uint64{},
+			}
+			switch {
+			case pb.Counter != nil:
+				a.valueType = prometheus.CounterValue
+			case pb.Gauge != nil:
+				a.valueType = prometheus.GaugeValue
+			default:
+				a.valueType = prometheus.UntypedValue
+			}
+			if pb.Histogram.GetSchema() == nativeHistogramSchema && pb.Histogram.Schema != nil {
+				a.native = &nativeBuckets{counts: map[int]uint64{}}
+			}
+			aggregated[key] = a
+			order = append(order, key)
+			// Seed with the first sample so math.Max works for negative values.
+			if pb.Gauge != nil {
+				a.value = pb.Gauge.GetValue()
+			} else if pb.Untyped != nil {
+				a.value = pb.Untyped.GetValue()
+			}
+		}
+		a.add(pb)
+	}
+
+	for _, key := range order {
+		m, err := aggregated[key].metric()
+		if err != nil {
+			firstErr = err
+			continue
+		}
+		out <- m
+	}
+	return firstErr
+}
+
+// labeledMetric is a metric with additional labels.
+type labeledMetric struct {
+	prometheus.Metric
+	desc   *prometheus.Desc
+	labels []*dto.LabelPair
+}
+
+func (m labeledMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m labeledMetric) Write(pb *dto.Metric) error {
+	if err := m.Metric.Write(pb); err != nil {
+		return err
+	}
+	pb.Label = append(pb.Label, m.labels...)
+	sort.Slice(pb.Label, func(i, j int) bool {
+		return pb.Label[i].GetName() < pb.Label[j].GetName()
+	})
+	return nil
+}
+
+// addMetricLabels reads metrics from in until it is closed and sends them to
+// out with the given labels added. Labels a metric already has keep their
+// value.
+func addMetricLabels(in <-chan prometheus.Metric, out chan<- prometheus.Metric, labels map[string]string) {
+	type labeling struct {
+		desc  *prometheus.Desc
+		pairs []*dto.LabelPair
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	labeled := map[*prometheus.Desc]labeling{}
+	for m := range in {
+		l, ok := labeled[m.Desc()]
+		if !ok {
+			pb := &dto.Metric{}
+			if err := m.Write(pb); err != nil {
+				out <- prometheus.NewInvalidMetric(m.Desc(), err)
+				continue
+			}
+			fqName, help, err := descNameHelp(m.Desc())
+			if err != nil {
+				out <- prometheus.NewInvalidMetric(m.Desc(), err)
+				continue
+			}
+			existing := make(map[string]bool, len(pb.GetLabel()))
+			labelNames := make([]string, 0, len(pb.GetLabel())+len(names))
+			for _, lp := range pb.GetLabel() {
+				existing[lp.GetName()] = true
+				labelNames = append(labelNames, lp.GetName())
+			}
+			for _, name := range names {
+				if existing[name] {
+					continue
+				}
+				name, value := name, labels[name]
+				l.pairs = append(l.pairs, &dto.LabelPair{Name: &name, Value: &value})
+				labelNames = append(labelNames, name)
+			}
+			if len(l.pairs) > 0 {
+				l.desc = prometheus.NewDesc(fqName, help, labelNames, nil)
+			}
+			labeled[m.Desc()] = l
+		}
+		if l.desc == nil {
+			out <- m
+			continue
+		}
+		out <- labeledMetric{Metric: m, desc: l.desc, labels: l.pairs}
+	}
+}
+
+// labelMetrics returns a channel whose metrics are sent to ch with the given
+// labels added, and a function closing it that returns once all metrics were
+// forwarded.
+func labelMetrics(ch chan<- prometheus.Metric, labels map[string]string) (chan<- prometheus.Metric, func()) {
+	labeled := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		addMetricLabels(labeled, ch, labels)
+		close(done)
+	}()
+	return labeled, func() {
+		close(labeled)
+		<-done
+	}
+}
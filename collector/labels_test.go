@@ -0,0 +1,308 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseDropLabels(t *testing.T) {
+	convey.Convey("Drop labels parsing", t, func() {
+		got, err := parseDropLabels([]string{"heartbeat=server_id", "heartbeat=foo", "slave_status=master_uuid"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(got, convey.ShouldResemble, map[string][]string{
+			"heartbeat":    {"server_id", "foo"},
+			"slave_status": {"master_uuid"},
+		})
+
+		got, err = parseDropLabels([]string{"*=server_id"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(got, convey.ShouldResemble, map[string][]string{allCollectors: {"server_id"}})
+
+		_, err = parseDropLabels([]string{"heartbeat"})
+		convey.So(err, convey.ShouldNotBeNil)
+		_, err = parseDropLabels([]string{"=server_id"})
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestDropLabelsHeartbeat(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", 1).
+		AddRow("1487597610.000000", "1487598113.448042", 2)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	exporter := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger())
+	exporter.dropLabels = map[string][]string{"heartbeat": {"server_id"}}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	got := map[string][]MetricResult{}
+	for m := range ch {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = append(got[name], readMetric(m))
+	}
+
+	convey.Convey("server_id is dropped and rows are merged", t, func() {
+		convey.So(got["mysql_heartbeat_now_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(got["mysql_heartbeat_stored_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 1487597613.00132, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestDropLabelsAllCollectors(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597610.000000", "1487598113.448042", 2).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	exporter := New(context.Background(), dsn, nil, log.NewNopLogger())
+	exporter.dropLabels = map[string][]string{allCollectors: {"server_id"}}
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err := exporter.scrapeWithDropLabels(context.Background(), ScrapeHeartbeat{}, db, ch); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	got := map[string][]MetricResult{}
+	for m := range ch {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = append(got[name], readMetric(m))
+	}
+
+	convey.Convey("server_id is dropped from all collectors and heartbeat keeps the latest row", t, func() {
+		convey.So(got["mysql_heartbeat_stored_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 1487597613.00132, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(got["mysql_heartbeat_now_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE},
+		})
+		convey.So(exporter.dropLabels[allCollectors], convey.ShouldResemble, []string{"server_id"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestServerNameLabel(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--exporter.server_name_query=SELECT name FROM cmdb.identity",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{"--exporter.server_name_query="})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery("SELECT name FROM cmdb.identity")).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("db-prod-1"))
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	exporter := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		exporter.scrapeDB(context.Background(), db, ch)
+		close(ch)
+	}()
+
+	got := map[string]MetricResult{}
+	for m := range ch {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = readMetric(m)
+	}
+
+	convey.Convey("server_name is added to all metrics", t, func() {
+		convey.So(got, convey.ShouldHaveLength, 8)
+		for _, m := range got {
+			convey.So(m.labels["server_name"], convey.ShouldEqual, "db-prod-1")
+		}
+		convey.So(got["mysql_heartbeat_now_timestamp_seconds"], convey.ShouldResemble, MetricResult{
+			labels: labelMap{"server_id": "1", "server_name": "db-prod-1"}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE,
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestConstLabelsHeartbeat(t *testing.T) {
+	if err := SetConstLabels(prometheus.Labels{"role": "replica", "server_id": "99"}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetConstLabels(nil)
+
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	exporter := New(context.Background(), dsn, nil, log.NewNopLogger())
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		labeled, closeLabeled := labelMetrics(ch, constLabels)
+		if err := exporter.scrapeWithDropLabels(context.Background(), ScrapeHeartbeat{}, db, labeled); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		closeLabeled()
+		close(ch)
+	}()
+
+	got := map[string]MetricResult{}
+	for m := range ch {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = readMetric(m)
+	}
+
+	convey.Convey("Global labels are added next to server_id", t, func() {
+		convey.So(got["mysql_heartbeat_now_timestamp_seconds"], convey.ShouldResemble, MetricResult{
+			labels: labelMap{"role": "replica", "server_id": "1"}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE,
+		})
+		convey.So(got["mysql_heartbeat_parse_errors_total"].labels, convey.ShouldResemble, labelMap{"role": "replica", "server_id": "99"})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestSetConstLabels(t *testing.T) {
+	defer SetConstLabels(nil)
+
+	convey.Convey("Invalid label names are rejected", t, func() {
+		convey.So(SetConstLabels(prometheus.Labels{"0cluster": "prod"}), convey.ShouldNotBeNil)
+		convey.So(SetConstLabels(prometheus.Labels{"__name__": "prod"}), convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Global labels are added to all exporter metrics", t, func() {
+		convey.So(SetConstLabels(prometheus.Labels{"cluster": "prod"}), convey.ShouldBeNil)
+
+		// Nothing listens on port 1, so only the exporter metrics are collected.
+		exporter := New(context.Background(), "root@tcp(127.0.0.1:1)/", nil, log.NewNopLogger())
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.Collect(ch)
+			close(ch)
+		}()
+		var up []MetricResult
+		for m := range ch {
+			if name, _, _ := descNameHelp(m.Desc()); name == "mysql_up" {
+				up = append(up, readMetric(m))
+			}
+		}
+		convey.So(up, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"cluster": "prod"}, value: 0, metricType: dto.MetricType_GAUGE},
+		})
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the slow query log and general log settings and the slow query count.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// logStatus is the Metric subsystem we use.
+	logStatus = "log"
+	// logVariablesQuery selects the log settings.
+	logVariablesQuery = `SELECT @@slow_query_log, @@long_query_time, @@log_output, @@general_log`
+	// logSlowQueriesQuery selects the number of slow queries.
+	logSlowQueriesQuery = `SHOW GLOBAL STATUS LIKE 'Slow_queries'`
+)
+
+// Metric descriptors.
+var (
+	logSlowQueryLogEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logStatus, "slow_query_log_enabled"),
+		"Whether the slow query log is enabled (slow_query_log).",
+		nil, nil,
+	)
+	logLongQueryTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logStatus, "long_query_time_seconds"),
+		"Queries running longer are logged to the slow query log (long_query_time).",
+		nil, nil,
+	)
+	logGeneralLogEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logStatus, "general_log_enabled"),
+		"Whether the general query log is enabled (general_log).",
+		nil, nil,
+	)
+	logOutputInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logStatus, "output_info"),
+		"The destinations of the slow query and general logs (log_output).",
+		[]string{"log_output"}, nil,
+	)
+	logSlowQueriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, logStatus, "slow_queries_total"),
+		"Number of queries that took more than long_query_time (Slow_queries).",
+		nil, nil,
+	)
+)
+
+// ScrapeLogStatus collects whether the slow query log and general log are
+// enabled, so that alerts can fire when slow logging is turned off.
+type ScrapeLogStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeLogStatus) Name() string {
+	return "log_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeLogStatus) Help() string {
+	return "Collect the slow query log and general log settings and the number of slow queries"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeLogStatus) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeLogStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeLogStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var (
+		slowQueryLog, logOutput, generalLog string
+		longQueryTime                       float64
+	)
+	if err := queryScalar(ctx, db, logVariablesQuery, &slowQueryLog, &longQueryTime, &logOutput, &generalLog); err != nil {
+		return wrapDriverError(err)
+	}
+	if value, ok := parseBoolMetric(slowQueryLog); ok {
+		ch <- prometheus.MustNewConstMetric(logSlowQueryLogEnabledDesc, prometheus.GaugeValue, value)
+	}
+	ch <- prometheus.MustNewConstMetric(logLongQueryTimeDesc, prometheus.GaugeValue, longQueryTime)
+	if value, ok := parseBoolMetric(generalLog); ok {
+		ch <- prometheus.MustNewConstMetric(logGeneralLogEnabledDesc, prometheus.GaugeValue, value)
+	}
+	ch <- prometheus.MustNewConstMetric(logOutputInfoDesc, prometheus.GaugeValue, 1, logOutput)
+
+	var name, slowQueries string
+	if err := queryScalar(ctx, db, logSlowQueriesQuery, &name, &slowQueries); err != nil {
+		return wrapDriverError(err)
+	}
+	if value, ok := parseStatus(sql.RawBytes(slowQueries)); ok {
+		ch <- prometheus.MustNewConstMetric(logSlowQueriesDesc, prometheus.CounterValue, value)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeLogStatus{}
@@ -0,0 +1,63 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeLogStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	// Boolean variables are returned as 0 and 1 by SELECT @@var.
+	mock.ExpectQuery(sanitizeQuery(logVariablesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"@@slow_query_log", "@@long_query_time", "@@log_output", "@@general_log"}).
+			AddRow("1", "0.500000", "FILE,TABLE", "0"))
+	mock.ExpectQuery(sanitizeQuery(logSlowQueriesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Slow_queries", "42"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeLogStatus{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0.5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"log_output": "FILE,TABLE"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 42, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Log settings and slow queries are collected", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parseMaxSeries parses "<collector>=<n>" entries into the maximum number of
+// metrics forwarded per scrape of each collector.
+func parseMaxSeries(entries []string) (map[string]int, error) {
+	maxSeries := map[string]int{}
+	for _, entry := range entries {
+		collector, value, ok := strings.Cut(entry, "=")
+		if !ok || collector == "" {
+			return nil, fmt.Errorf("invalid max series %q, expected <collector>=<n>", entry)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid max series %q, expected <collector>=<n>", entry)
+		}
+		maxSeries[collector] = n
+	}
+	return maxSeries, nil
+}
+
+// capMetrics returns a channel whose first max metrics are sent to ch, and a
+// function closing it that returns once all metrics were read and whether
+// any were discarded. Metrics beyond max are read and discarded, so that the
+// scraper does not block.
+func capMetrics(ch chan<- prometheus.Metric, max int) (chan<- prometheus.Metric, func() bool) {
+	capped := make(chan prometheus.Metric)
+	done := make(chan bool)
+	go func() {
+		forwarded := 0
+		discarded := false
+		for m := range capped {
+			if forwarded >= max {
+				discarded = true
+				continue
+			}
+			ch <- m
+			forwarded++
+		}
+		done <- discarded
+	}()
+	return capped, func() bool {
+		close(capped)
+		return <-done
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var tableSeriesDesc = prometheus.NewDesc("mysql_test_table_rows", "Rows of a table.", []string{"table"}, nil)
+
+// tableSeriesScraper sends one metric per table.
+type tableSeriesScraper struct {
+	fakeScraper
+	tables int
+}
+
+func (s tableSeriesScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	for i := 0; i < s.tables; i++ {
+		ch <- prometheus.MustNewConstMetric(tableSeriesDesc, prometheus.GaugeValue, float64(i), "t"+strconv.Itoa(i))
+	}
+	return nil
+}
+
+func TestParseMaxSeries(t *testing.T) {
+	convey.Convey("Max series parsing", t, func() {
+		got, err := parseMaxSeries([]string{"info_schema.tables=1000", "heartbeat=10"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(got, convey.ShouldResemble, map[string]int{"info_schema.tables": 1000, "heartbeat": 10})
+
+		for _, entry := range []string{"info_schema.tables", "=10", "heartbeat=0", "heartbeat=ten"} {
+			_, err = parseMaxSeries([]string{entry})
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestMaxSeries(t *testing.T) {
+	exporter := New(context.Background(), dsn, nil, log.NewNopLogger())
+	exporter.maxSeries = map[string]int{"tables": 3}
+
+	for _, tc := range []struct {
+		tables        int
+		wantForwarded int
+		wantCapped    float64
+	}{
+		{tables: 5, wantForwarded: 3, wantCapped: 1},
+		{tables: 2, wantForwarded: 2, wantCapped: 0},
+	} {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			scraper := tableSeriesScraper{fakeScraper: fakeScraper{name: "tables"}, tables: tc.tables}
+			if err := exporter.scrapeWithDropLabels(context.Background(), scraper, nil, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		forwarded := 0
+		var capped []MetricResult
+		for m := range ch {
+			switch m.Desc() {
+			case tableSeriesDesc:
+				forwarded++
+			case mysqlSeriesCapped:
+				capped = append(capped, readMetric(m))
+			default:
+				t.Errorf("unexpected metric %s", m.Desc())
+			}
+		}
+
+		convey.Convey("Metrics beyond exporter.max_series are discarded: "+strconv.Itoa(tc.tables)+" tables", t, func() {
+			convey.So(forwarded, convey.ShouldEqual, tc.wantForwarded)
+			convey.So(capped, convey.ShouldResemble, []MetricResult{
+				{labels: labelMap{"collector": "collect.tables"}, value: tc.wantCapped, metricType: dto.MetricType_GAUGE},
+			})
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the MyISAM key cache efficiency from `SHOW GLOBAL STATUS LIKE 'Key_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	myisam = "myisam"
+	// Query.
+	keyCacheStatusQuery = `SHOW GLOBAL STATUS LIKE 'Key_%'`
+)
+
+// Metric descriptors.
+var (
+	keyCacheReadRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, myisam, "key_cache_read_requests_total"),
+		"Number of requests to read a key block from the MyISAM key cache (Key_read_requests).",
+		nil, nil,
+	)
+	keyCacheReadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, myisam, "key_cache_reads_total"),
+		"Number of key blocks read from disk into the MyISAM key cache (Key_reads).",
+		nil, nil,
+	)
+	keyCacheWriteRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, myisam, "key_cache_write_requests_total"),
+		"Number of requests to write a key block to the MyISAM key cache (Key_write_requests).",
+		nil, nil,
+	)
+	keyCacheWritesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, myisam, "key_cache_writes_total"),
+		"Number of key blocks written from the MyISAM key cache to disk (Key_writes).",
+		nil, nil,
+	)
+	keyCacheHitRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, myisam, "key_cache_hit_ratio"),
+		"Ratio of key block read requests served from the MyISAM key cache since the server started.",
+		nil, nil,
+	)
+)
+
+// keyCacheCounters are the key cache status variables in the order they are
+// exported.
+var keyCacheCounters = []struct {
+	name string
+	desc *prometheus.Desc
+}{
+	{"Key_read_requests", keyCacheReadRequestsDesc},
+	{"Key_reads", keyCacheReadsDesc},
+	{"Key_write_requests", keyCacheWriteRequestsDesc},
+	{"Key_writes", keyCacheWritesDesc},
+}
+
+// keyCacheHitRatio returns the ratio of read requests not read from disk, of
+// the key cache or the InnoDB buffer pool. It returns false without read
+// requests.
+func keyCacheHitRatio(readRequests, reads float64) (float64, bool) {
+	if readRequests <= 0 {
+		return 0, false
+	}
+	return 1 - reads/readRequests, true
+}
+
+// ScrapeKeyCache collects the MyISAM key cache status.
+type ScrapeKeyCache struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeKeyCache) Name() string {
+	return myisam + ".key_cache"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeKeyCache) Help() string {
+	return "Collect the MyISAM key cache requests, disk reads and writes and hit ratio from SHOW GLOBAL STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeKeyCache) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeKeyCache) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeKeyCache) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, keyCacheStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key    string
+		val    sql.RawBytes
+		status = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			status[key] = value
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	for _, counter := range keyCacheCounters {
+		if value, ok := status[counter.name]; ok {
+			ch <- prometheus.MustNewConstMetric(counter.desc, prometheus.CounterValue, value)
+		}
+	}
+	readRequests, hasReadRequests := status["Key_read_requests"]
+	reads, hasReads := status["Key_reads"]
+	if !hasReadRequests || !hasReads {
+		return nil
+	}
+	if ratio, ok := keyCacheHitRatio(readRequests, reads); ok {
+		ch <- prometheus.MustNewConstMetric(keyCacheHitRatioDesc, prometheus.GaugeValue, ratio)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeKeyCache{}
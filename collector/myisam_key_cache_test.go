@@ -0,0 +1,88 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeKeyCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Variable_name", "Value"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("Key_blocks_not_flushed", "0").
+		AddRow("Key_blocks_unused", "6698").
+		AddRow("Key_blocks_used", "12").
+		AddRow("Key_read_requests", "2000").
+		AddRow("Key_reads", "50").
+		AddRow("Key_write_requests", "400").
+		AddRow("Key_writes", "100")
+	mock.ExpectQuery(sanitizeQuery(keyCacheStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeKeyCache{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 2000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 50, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 400, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{}, value: 0.975, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Key cache status is collected", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestKeyCacheHitRatio(t *testing.T) {
+	convey.Convey("The hit ratio is skipped without read requests", t, func() {
+		ratio, ok := keyCacheHitRatio(2000, 50)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 0.975)
+
+		ratio, ok = keyCacheHitRatio(10, 0)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(ratio, convey.ShouldEqual, 1)
+
+		_, ok = keyCacheHitRatio(0, 0)
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+}
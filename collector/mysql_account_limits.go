@@ -0,0 +1,184 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the connection limits of `mysql.user` and the connections of
+// `information_schema.processlist` per user.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	account = "account"
+	// Queries.
+	accountLimitsQuery = `
+		SELECT user, max_user_connections, max_connections
+		  FROM mysql.user
+		`
+	accountConnectionsQuery = `
+		SELECT user, COUNT(*)
+		  FROM information_schema.processlist
+		  GROUP BY user
+		`
+	accountGlobalLimitQuery = `SELECT @@max_user_connections`
+)
+
+// Metric descriptors.
+var (
+	accountConnectionsCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, account, "connections_current"),
+		"Number of connections of the user in the processlist.",
+		[]string{"user"}, nil,
+	)
+	accountMaxUserConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, account, "max_user_connections"),
+		"Lowest limit of simultaneous connections of the accounts of the user, the global max_user_connections for accounts without a limit.",
+		[]string{"user"}, nil,
+	)
+	accountMaxConnectionsPerHourDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, account, "max_connections_per_hour"),
+		"Lowest limit of connections per hour of the accounts of the user.",
+		[]string{"user"}, nil,
+	)
+)
+
+// accountLimits are the lowest non-zero limits of the accounts of a user,
+// 0 meaning unlimited.
+type accountLimits struct {
+	maxUserConnections    uint64
+	maxConnectionsPerHour uint64
+	connections           float64
+}
+
+// lowerLimit returns the lower of two limits where 0 is unlimited.
+func lowerLimit(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// ScrapeAccountLimits collects the connections of each user against the
+// connection limits of its accounts.
+type ScrapeAccountLimits struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeAccountLimits) Name() string {
+	return mysqlSubsystem + ".account_limits"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeAccountLimits) Help() string {
+	return "Collect the connections per user from information_schema.processlist and the connection limits of its accounts from mysql.user"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeAccountLimits) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeAccountLimits) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeAccountLimits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var globalLimit uint64
+	if err := db.QueryRowContext(ctx, accountGlobalLimitQuery).Scan(&globalLimit); err != nil {
+		return wrapDriverError(err)
+	}
+
+	// Without SELECT on mysql.user the error is classified as a
+	// configuration error, reported with the missing grant.
+	limitRows, err := db.QueryContext(ctx, accountLimitsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer limitRows.Close()
+
+	var (
+		user                          string
+		maxUserConns, maxConnsPerHour uint64
+		accounts                      = map[string]*accountLimits{}
+	)
+	for limitRows.Next() {
+		if err := limitRows.Scan(&user, &maxUserConns, &maxConnsPerHour); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		// Accounts of the same user on different hosts share the processlist
+		// user, so the connections are compared to the lowest limit.
+		limits, ok := accounts[user]
+		if !ok {
+			limits = &accountLimits{}
+			accounts[user] = limits
+		}
+		// The global max_user_connections applies to accounts without a limit.
+		if maxUserConns == 0 {
+			maxUserConns = globalLimit
+		}
+		limits.maxUserConnections = lowerLimit(limits.maxUserConnections, maxUserConns)
+		limits.maxConnectionsPerHour = lowerLimit(limits.maxConnectionsPerHour, maxConnsPerHour)
+	}
+	if err := limitRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	connRows, err := db.QueryContext(ctx, accountConnectionsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer connRows.Close()
+
+	var connections float64
+	for connRows.Next() {
+		if err := connRows.Scan(&user, &connections); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		// Threads of users without an account, e.g. system user, are skipped.
+		if limits, ok := accounts[user]; ok {
+			limits.connections = connections
+		}
+	}
+	if err := connRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	users := make([]string, 0, len(accounts))
+	for user := range accounts {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		limits := accounts[user]
+		ch <- prometheus.MustNewConstMetric(accountConnectionsCurrentDesc, prometheus.GaugeValue, limits.connections, user)
+		if limits.maxUserConnections > 0 {
+			ch <- prometheus.MustNewConstMetric(accountMaxUserConnectionsDesc, prometheus.GaugeValue, float64(limits.maxUserConnections), user)
+		}
+		if limits.maxConnectionsPerHour > 0 {
+			ch <- prometheus.MustNewConstMetric(accountMaxConnectionsPerHourDesc, prometheus.GaugeValue, float64(limits.maxConnectionsPerHour), user)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeAccountLimits{}
@@ -0,0 +1,98 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeAccountLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(accountGlobalLimitQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@max_user_connections"}).AddRow("50"))
+	limitRows := sqlmock.NewRows([]string{"user", "max_user_connections", "max_connections"}).
+		AddRow("app", "20", "0").
+		AddRow("app", "10", "1000").
+		AddRow("batch", "0", "0").
+		AddRow("idle", "5", "0")
+	mock.ExpectQuery(sanitizeQuery(accountLimitsQuery)).WillReturnRows(limitRows)
+	connRows := sqlmock.NewRows([]string{"user", "COUNT(*)"}).
+		AddRow("app", "8").
+		AddRow("batch", "3").
+		AddRow("system user", "2")
+	mock.ExpectQuery(sanitizeQuery(accountConnectionsQuery)).WillReturnRows(connRows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeAccountLimits{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	expected := []MetricResult{
+		{labels: labelMap{"user": "app"}, value: 8, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app"}, value: 10, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "app"}, value: 1000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "batch"}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "batch"}, value: 50, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "idle"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"user": "idle"}, value: 5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Connections are compared to the lowest limit per user", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeAccountLimitsPermissionDenied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(accountGlobalLimitQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@max_user_connections"}).AddRow("0"))
+	mock.ExpectQuery(sanitizeQuery(accountLimitsQuery)).
+		WillReturnError(&mysql.MySQLError{Number: 1142, Message: "SELECT command denied to user 'exporter'@'localhost' for table 'user'"})
+
+	_, err = CollectOnce(context.Background(), ScrapeAccountLimits{}, db, log.NewNopLogger())
+	convey.Convey("Missing SELECT on mysql.user is a configuration error", t, func() {
+		convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+		_, denied := permissionDenied(err)
+		convey.So(denied, convey.ShouldBeTrue)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
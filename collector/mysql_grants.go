@@ -0,0 +1,150 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the number of accounts with dangerous grants from `mysql.user`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// security is the Metric subsystem we use.
+	security = "security"
+	// mysqlGrantsQuery selects the host and the global privileges of 5.6 of
+	// all accounts, but not their names.
+	mysqlGrantsQuery = `
+		SELECT
+		  Host, Select_priv, Insert_priv, Update_priv, Delete_priv, Create_priv, Drop_priv, Reload_priv,
+		  Shutdown_priv, Process_priv, File_priv, Grant_priv, References_priv, Index_priv, Alter_priv,
+		  Show_db_priv, Super_priv, Create_tmp_table_priv, Lock_tables_priv, Execute_priv, Repl_slave_priv,
+		  Repl_client_priv, Create_view_priv, Show_view_priv, Create_routine_priv, Alter_routine_priv,
+		  Create_user_priv, Event_priv, Trigger_priv, Create_tablespace_priv
+		  FROM mysql.user
+		`
+	// mysqlGrantsSuperColumn is the index of Super_priv in mysqlGrantsQuery.
+	mysqlGrantsSuperColumn = 16
+	// mysqlGrantsGrantColumn is the index of Grant_priv in mysqlGrantsQuery,
+	// which GRANT ALL ON *.* does not set.
+	mysqlGrantsGrantColumn = 11
+)
+
+// Metric descriptors.
+var (
+	securityAccountsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, security, "accounts"),
+		"Number of accounts in mysql.user.",
+		nil, nil,
+	)
+	securityAccountsWithSuperDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, security, "accounts_with_super"),
+		"Number of accounts with the SUPER privilege.",
+		nil, nil,
+	)
+	securityAccountsWithAllPrivilegesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, security, "accounts_with_all_privileges"),
+		"Number of accounts with ALL PRIVILEGES on *.*.",
+		nil, nil,
+	)
+	securityAccountsWildcardHostDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, security, "accounts_wildcard_host"),
+		"Number of accounts whose host contains the % wildcard.",
+		nil, nil,
+	)
+)
+
+// ScrapeGrants collects the number of accounts with dangerous grants for
+// security audits. Unlike mysql.user, it exports no account names.
+type ScrapeGrants struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeGrants) Name() string {
+	return mysqlSubsystem + ".grants"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeGrants) Help() string {
+	return "Collect the number of accounts with SUPER, ALL PRIVILEGES or a wildcard host from mysql.user"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeGrants) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeGrants) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeGrants) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	// Without SELECT on mysql.user the error is reported as permission denied.
+	rows, err := db.QueryContext(ctx, mysqlGrantsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	values := make([]string, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var accounts, super, allPrivileges, wildcardHost float64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		accounts++
+		if strings.Contains(values[0], "%") {
+			wildcardHost++
+		}
+		if values[mysqlGrantsSuperColumn] == "Y" {
+			super++
+		}
+		all := true
+		for i, value := range values[1:] {
+			if i+1 != mysqlGrantsGrantColumn && value != "Y" {
+				all = false
+				break
+			}
+		}
+		if all {
+			allPrivileges++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(securityAccountsDesc, prometheus.GaugeValue, accounts)
+	ch <- prometheus.MustNewConstMetric(securityAccountsWithSuperDesc, prometheus.GaugeValue, super)
+	ch <- prometheus.MustNewConstMetric(securityAccountsWithAllPrivilegesDesc, prometheus.GaugeValue, allPrivileges)
+	ch <- prometheus.MustNewConstMetric(securityAccountsWildcardHostDesc, prometheus.GaugeValue, wildcardHost)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeGrants{}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+var mysqlGrantsColumns = []string{
+	"Host", "Select_priv", "Insert_priv", "Update_priv", "Delete_priv", "Create_priv", "Drop_priv", "Reload_priv",
+	"Shutdown_priv", "Process_priv", "File_priv", "Grant_priv", "References_priv", "Index_priv", "Alter_priv",
+	"Show_db_priv", "Super_priv", "Create_tmp_table_priv", "Lock_tables_priv", "Execute_priv", "Repl_slave_priv",
+	"Repl_client_priv", "Create_view_priv", "Show_view_priv", "Create_routine_priv", "Alter_routine_priv",
+	"Create_user_priv", "Event_priv", "Trigger_priv", "Create_tablespace_priv",
+}
+
+// mysqlGrantsRow returns a mysql.user row with the given privileges set.
+func mysqlGrantsRow(host string, all bool, privileges ...string) []driver.Value {
+	row := []driver.Value{host}
+	for _, column := range mysqlGrantsColumns[1:] {
+		value := "N"
+		if all {
+			value = "Y"
+		}
+		for _, privilege := range privileges {
+			if column == privilege {
+				value = "Y"
+			}
+		}
+		row = append(row, value)
+	}
+	return row
+}
+
+func TestScrapeGrants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	adminRow := mysqlGrantsRow("%", true)
+	adminRow[mysqlGrantsGrantColumn] = "N"
+	rows := sqlmock.NewRows(mysqlGrantsColumns).
+		// root@localhost, GRANT ALL ON *.* WITH GRANT OPTION.
+		AddRow(mysqlGrantsRow("localhost", true)...).
+		// admin@%, GRANT ALL ON *.*.
+		AddRow(adminRow...).
+		// app@10.0.%, privileges on its schema only.
+		AddRow(mysqlGrantsRow("10.0.%", false)...).
+		// exporter@localhost.
+		AddRow(mysqlGrantsRow("localhost", false, "Process_priv", "Repl_client_priv", "Select_priv")...).
+		// legacy@%, SUPER for replication tooling.
+		AddRow(mysqlGrantsRow("%", false, "Super_priv", "Repl_slave_priv")...)
+	mock.ExpectQuery(sanitizeQuery(mysqlGrantsQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeGrants{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{}, value: 5, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeGrantsPermissionDenied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(mysqlGrantsQuery)).
+		WillReturnError(&mysql.MySQLError{Number: 1142, Message: "SELECT command denied to user 'exporter'@'localhost' for table 'user'"})
+
+	_, err = CollectOnce(context.Background(), ScrapeGrants{}, db, log.NewNopLogger())
+	convey.Convey("Missing SELECT on mysql.user is a permission error", t, func() {
+		_, denied := permissionDenied(err)
+		convey.So(denied, convey.ShouldBeTrue)
+		convey.So(errorClass(err), convey.ShouldEqual, ErrConfig)
+	})
+}
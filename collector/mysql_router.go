@@ -0,0 +1,140 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the Group Replication members seen through a MySQL Router connection.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// mysqlRouter is the Metric subsystem we use.
+	mysqlRouter = "mysql_router"
+	// routedServerQuery identifies the server the router sent the connection to.
+	routedServerQuery = `SELECT @@hostname, @@port, @@server_uuid`
+)
+
+// Metric descriptors.
+var (
+	mysqlRouterRoutedToDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlRouter, "routed_to_info"),
+		"The server the MySQL Router connection of the exporter is routed to.",
+		[]string{"hostname", "port", "server_uuid"}, nil,
+	)
+	mysqlRouterGroupMemberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, mysqlRouter, "group_member_routed"),
+		"Group Replication members seen through MySQL Router, 1 for the member the connection is routed to.",
+		[]string{"member_id", "member_host", "member_port", "member_state", "member_role"}, nil,
+	)
+)
+
+// ScrapeRouterGroupMembers collects `performance_schema.replication_group_members`
+// through a MySQL Router connection, marking the member the router picked.
+type ScrapeRouterGroupMembers struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeRouterGroupMembers) Name() string {
+	return mysqlRouter + ".group_members"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeRouterGroupMembers) Help() string {
+	return "Collect the Group Replication members and the member a MySQL Router connection is routed to"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeRouterGroupMembers) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeRouterGroupMembers) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeRouterGroupMembers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	membersRows, err := db.QueryContext(ctx, perfReplicationGroupMembersQuery)
+	if err != nil {
+		var mysqlErr *MySQL.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "Group Replication is not available, skipping")
+			return nil
+		}
+		return wrapDriverError(err)
+	}
+	defer membersRows.Close()
+
+	columnNames, err := membersRows.Columns()
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	var members [][]string
+	for membersRows.Next() {
+		scanArgs := make([]interface{}, len(columnNames))
+		for i := range scanArgs {
+			scanArgs[i] = &sql.RawBytes{}
+		}
+		if err := membersRows.Scan(scanArgs...); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		memberID := columnValue(scanArgs, columnNames, "MEMBER_ID")
+		if memberID == "" {
+			continue
+		}
+		members = append(members, []string{
+			memberID,
+			columnValue(scanArgs, columnNames, "MEMBER_HOST"),
+			columnValue(scanArgs, columnNames, "MEMBER_PORT"),
+			columnValue(scanArgs, columnNames, "MEMBER_STATE"),
+			columnValue(scanArgs, columnNames, "MEMBER_ROLE"),
+		})
+	}
+	if err := membersRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	if len(members) == 0 {
+		level.Debug(logger).Log("msg", "Group Replication is not running, skipping")
+		return nil
+	}
+
+	var hostname, port, serverUUID string
+	if err := db.QueryRowContext(ctx, routedServerQuery).Scan(&hostname, &port, &serverUUID); err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		mysqlRouterRoutedToDesc, prometheus.GaugeValue, 1, hostname, port, serverUUID,
+	)
+	for _, member := range members {
+		routed := 0.0
+		if member[0] == serverUUID {
+			routed = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			mysqlRouterGroupMemberDesc, prometheus.GaugeValue, routed, member...,
+		)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeRouterGroupMembers{}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricNamespace replaces namespace in the names of the exported metrics.
+// Descriptors are built with namespace and renamed on collection.
+var metricNamespace = namespace
+
+// SetNamespace sets the namespace of all exported metrics, e.g. "corp" turns
+// mysql_heartbeat_now_timestamp_seconds into corp_heartbeat_now_timestamp_seconds.
+// It must be called before an Exporter is registered.
+func SetNamespace(ns string) {
+	metricNamespace = ns
+}
+
+// renamedMetric is a metric exposed under a different descriptor.
+type renamedMetric struct {
+	prometheus.Metric
+	desc *prometheus.Desc
+}
+
+func (m renamedMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// renameDesc returns desc with namespace replaced by metricNamespace. All
+// labels of the returned descriptor are variable labels.
+func renameDesc(desc *prometheus.Desc, labels []string) (*prometheus.Desc, error) {
+	name, help, err := descNameHelp(desc)
+	if err != nil {
+		return nil, err
+	}
+	if metricNamespace == namespace || !strings.HasPrefix(name, namespace+"_") {
+		return desc, nil
+	}
+	name = prometheus.BuildFQName(metricNamespace, "", strings.TrimPrefix(name, namespace+"_"))
+	return prometheus.NewDesc(name, help, labels, nil), nil
+}
+
+// renameMetrics reads metrics from in until it is closed and sends them to out
+// with namespace replaced by metricNamespace.
+func renameMetrics(in <-chan prometheus.Metric, out chan<- prometheus.Metric) {
+	renamed := map[*prometheus.Desc]*prometheus.Desc{}
+	for m := range in {
+		desc, ok := renamed[m.Desc()]
+		if !ok {
+			pb := &dto.Metric{}
+			if err := m.Write(pb); err != nil {
+				out <- prometheus.NewInvalidMetric(m.Desc(), err)
+				continue
+			}
+			labels := make([]string, 0, len(pb.GetLabel()))
+			for _, lp := range pb.GetLabel() {
+				labels = append(labels, lp.GetName())
+			}
+			var err error
+			if desc, err = renameDesc(m.Desc(), labels); err != nil {
+				out <- prometheus.NewInvalidMetric(m.Desc(), err)
+				continue
+			}
+			renamed[m.Desc()] = desc
+		}
+		out <- renamedMetric{Metric: m, desc: desc}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetNamespaceHeartbeat(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetNamespace("corp")
+	defer SetNamespace(namespace)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	scraped := make(chan prometheus.Metric)
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeHeartbeat{}).Scrape(context.Background(), db, scraped, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(scraped)
+	}()
+	go func() {
+		renameMetrics(scraped, ch)
+		close(ch)
+	}()
+
+	var names []string
+	for m := range ch {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	convey.Convey("Metrics are exported in the custom namespace", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{
+			"corp_heartbeat_now_timestamp_seconds",
+			"corp_heartbeat_stored_timestamp_seconds",
+			"corp_heartbeat_worst_lag_seconds",
+			"corp_heartbeat_server_last_seen_timestamp_seconds",
+			"corp_heartbeat_server_count",
+			"corp_heartbeat_parse_errors_total",
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestSetNamespaceDescribe(t *testing.T) {
+	SetNamespace("corp")
+	defer SetNamespace(namespace)
+
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		New(context.Background(), dsn, nil, log.NewNopLogger()).Describe(ch)
+		close(ch)
+	}()
+
+	var names []string
+	for desc := range ch {
+		name, _, err := descNameHelp(desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	convey.Convey("Exporter metrics are described in the custom namespace", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{
+			"corp_up",
+			"corp_exporter_collector_duration_seconds",
+			"corp_exporter_collector_success",
+			"corp_exporter_scrape_deadline_seconds",
+			"corp_exporter_enabled_collectors",
+			"corp_exporter_scrape_permission_denied",
+			"corp_exporter_scrape_slow",
+			"corp_exporter_scrape_truncated",
+			"corp_exporter_series_capped",
+			"corp_exporter_db_pool_open_connections",
+			"corp_exporter_db_pool_in_use",
+			"corp_exporter_db_pool_idle",
+			"corp_exporter_db_pool_wait_count",
+			"corp_exporter_db_pool_wait_duration_seconds",
+			"corp_exporter_scraper_skipped_version_total",
+			"corp_exporter_scrape_error_total",
+			"corp_exporter_scrape_retries_total",
+			"corp_exporter_last_scrape_succeeded",
+			"corp_exporter_last_scrape_error_timestamp_seconds",
+			"corp_exporter_scraper_circuit_open",
+			"corp_exporter_queries_total",
+			"corp_exporter_ping_failures_total",
+		})
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Tunable flags.
+var (
+	exporterNativeHistograms = kingpin.Flag(
+		"exporter.native_histograms",
+		"Add a native histogram to latency histograms, e.g. of info_schema.query_response_time, for Prometheus servers scraping native histograms.",
+	).Default("false").Bool()
+)
+
+const (
+	// nativeHistogramSchema is the resolution of the native histograms
+	// converted from the fixed buckets of MySQL: the bounds of consecutive
+	// buckets grow by a factor of 2^(2^-3), about 9%.
+	nativeHistogramSchema = 3
+	// nativeHistogramZeroThreshold is the width of the zero bucket, the
+	// default of client_golang.
+	nativeHistogramZeroThreshold = 2.938735877055719e-39
+)
+
+// newLatencyHistogram returns the histogram of the cumulative counts of
+// fixed buckets like NewConstHistogram. With exporter.native_histograms the
+// histogram carries a native histogram of the same buckets as well, which
+// Prometheus ingests instead of the classic buckets when it scrapes native
+// histograms. Text format scrapes only see the classic buckets.
+func newLatencyHistogram(desc *prometheus.Desc, count uint64, sum float64, buckets map[float64]uint64, labelValues ...string) (prometheus.Metric, error) {
+	classic, err := prometheus.NewConstHistogram(desc, count, sum, buckets, labelValues...)
+	if err != nil || !*exporterNativeHistograms {
+		return classic, err
+	}
+	return withNativeHistogram(classic, nativeHistogram(classicBucketCounts(buckets))), nil
+}
+
+// nativeBuckets are the counts of the buckets of a native histogram of
+// nativeHistogramSchema by bucket index, and the count of the zero bucket.
+type nativeBuckets struct {
+	counts map[int]uint64
+	zero   uint64
+}
+
+// nativeBucketIndex returns the index of the native bucket holding v, which
+// covers (2^((index-1)/2^schema), 2^(index/2^schema)].
+func nativeBucketIndex(v float64) int {
+	return int(math.Ceil(math.Log2(v) * (1 << nativeHistogramSchema)))
+}
+
+// classicBucketCounts converts the cumulative counts of fixed buckets by
+// upper bound to native buckets. The count of a fixed bucket goes to the
+// native bucket of its upper bound. Native histograms have no +Inf bucket,
+// so the count above the highest finite bound goes to the next native bucket.
+func classicBucketCounts(buckets map[float64]uint64) nativeBuckets {
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+
+	native := nativeBuckets{counts: map[int]uint64{}}
+	var cumulative uint64
+	highest := math.MinInt32
+	for _, bound := range bounds {
+		count := buckets[bound] - cumulative
+		cumulative = buckets[bound]
+		switch {
+		case bound <= nativeHistogramZeroThreshold:
+			native.zero += count
+		case math.IsInf(bound, 1):
+			if count > 0 && highest > math.MinInt32 {
+				native.counts[highest+1] += count
+			}
+		default:
+			index := nativeBucketIndex(bound)
+			native.counts[index] += count
+			if index > highest {
+				highest = index
+			}
+		}
+	}
+	return native
+}
+
+// nativeBucketCounts returns the buckets of the native histogram of h, or
+// false if h has none of nativeHistogramSchema.
+func nativeBucketCounts(h *dto.Histogram) (nativeBuckets, bool) {
+	if h.Schema == nil || h.GetSchema() != nativeHistogramSchema {
+		return nativeBuckets{}, false
+	}
+	native := nativeBuckets{counts: map[int]uint64{}, zero: h.GetZeroCount()}
+	index := 0
+	var count int64
+	deltas := h.GetPositiveDelta()
+	for _, span := range h.GetPositiveSpan() {
+		index += int(span.GetOffset())
+		for i := uint32(0); i < span.GetLength() && len(deltas) > 0; i++ {
+			count += deltas[0]
+			deltas = deltas[1:]
+			native.counts[index] += uint64(count)
+			index++
+		}
+	}
+	return native, true
+}
+
+// nativeHistogram returns the native part of a histogram of buckets. Empty
+// buckets are left out, starting a new span.
+func nativeHistogram(native nativeBuckets) *dto.Histogram {
+	indexes := make([]int, 0, len(native.counts))
+	for index, count := range native.counts {
+		if count > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	schema, zeroThreshold, zero := int32(nativeHistogramSchema), nativeHistogramZeroThreshold, native.zero
+	h := &dto.Histogram{Schema: &schema, ZeroThreshold: &zeroThreshold, ZeroCount: &zero}
+	var previous int64
+	for i, index := range indexes {
+		if i == 0 || index != indexes[i-1]+1 {
+			offset, length := int32(index), uint32(0)
+			if i > 0 {
+				offset = int32(index - indexes[i-1] - 1)
+			}
+			h.PositiveSpan = append(h.PositiveSpan, &dto.BucketSpan{Offset: &offset, Length: &length})
+		}
+		*h.PositiveSpan[len(h.PositiveSpan)-1].Length++
+		count := int64(native.counts[index])
+		h.PositiveDelta = append(h.PositiveDelta, count-previous)
+		previous = count
+	}
+	return h
+}
+
+// withNativeHistogram adds the native part of native to the histogram m.
+func withNativeHistogram(m prometheus.Metric, native *dto.Histogram) prometheus.Metric {
+	return nativeHistogramMetric{Metric: m, native: native}
+}
+
+type nativeHistogramMetric struct {
+	prometheus.Metric
+	native *dto.Histogram
+}
+
+func (m nativeHistogramMetric) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+	out.Histogram.Schema = m.native.Schema
+	out.Histogram.ZeroThreshold = m.native.ZeroThreshold
+	out.Histogram.ZeroCount = m.native.ZeroCount
+	out.Histogram.PositiveSpan = m.native.PositiveSpan
+	out.Histogram.PositiveDelta = m.native.PositiveDelta
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewLatencyHistogram(t *testing.T) {
+	desc := prometheus.NewDesc("mysql_test_latency_seconds", "Test latency.", nil, nil)
+	// Cumulative counts of a query_response_time like distribution.
+	buckets := map[float64]uint64{
+		0.000001:    1,
+		0.00001:     5,
+		0.0001:      30,
+		0.001:       80,
+		0.01:        95,
+		0.1:         99,
+		1:           99,
+		10:          100,
+		math.Inf(1): 102,
+	}
+
+	write := func() *dto.Histogram {
+		m, err := newLatencyHistogram(desc, 102, 3.5, buckets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatal(err)
+		}
+		return pb.Histogram
+	}
+
+	convey.Convey("Classic histograms by default", t, func() {
+		h := write()
+		convey.So(h.Schema, convey.ShouldBeNil)
+		convey.So(h.PositiveSpan, convey.ShouldBeEmpty)
+		convey.So(h.GetSampleCount(), convey.ShouldEqual, 102)
+		convey.So(h.GetBucket(), convey.ShouldHaveLength, len(buckets))
+	})
+
+	if _, err := kingpin.CommandLine.Parse([]string{"--exporter.native_histograms"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	convey.Convey("Native histograms with exporter.native_histograms", t, func() {
+		h := write()
+		convey.So(h.GetSchema(), convey.ShouldEqual, nativeHistogramSchema)
+		convey.So(h.GetZeroCount(), convey.ShouldEqual, 0)
+		convey.So(h.PositiveSpan, convey.ShouldNotBeEmpty)
+		// The classic buckets are kept for text format scrapes.
+		convey.So(h.GetBucket(), convey.ShouldHaveLength, len(buckets))
+
+		native, ok := nativeBucketCounts(h)
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(native.counts, convey.ShouldResemble, map[int]uint64{
+			nativeBucketIndex(0.000001): 1,
+			nativeBucketIndex(0.00001):  4,
+			nativeBucketIndex(0.0001):   25,
+			nativeBucketIndex(0.001):    50,
+			nativeBucketIndex(0.01):     15,
+			nativeBucketIndex(0.1):      4,
+			nativeBucketIndex(10):       1,
+			nativeBucketIndex(10) + 1:   2,
+		})
+		var total uint64
+		for _, count := range native.counts {
+			total += count
+		}
+		convey.So(total+native.zero, convey.ShouldEqual, h.GetSampleCount())
+	})
+}
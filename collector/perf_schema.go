@@ -15,3 +15,7 @@ package collector
 
 // Subsystem.
 const performanceSchema = "perf_schema"
+
+// perfSchemaEnabledQuery checks whether performance_schema is enabled. The
+// tables are empty when it is off.
+const perfSchemaEnabledQuery = `SELECT @@performance_schema`
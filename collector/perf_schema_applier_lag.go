@@ -0,0 +1,131 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the apply lag of replication channels from
+// `performance_schema.replication_applier_status_by_worker`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfApplierLagQuery = `
+	SELECT
+		CHANNEL_NAME,
+		LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP,
+		LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP
+	FROM performance_schema.replication_applier_status_by_worker
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaApplierLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "applier_lag_seconds"),
+		"Seconds between the original commit of the last transaction applied on the channel and the end of its apply.",
+		[]string{"channel_name"}, nil,
+	)
+)
+
+// ScrapeApplierLag collects the apply lag of the last applied transaction of
+// each replication channel. Unlike Seconds_Behind_Source it stays accurate
+// on idle replicas, as it is computed from the commit timestamps of the
+// transaction.
+type ScrapeApplierLag struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeApplierLag) Name() string {
+	return performanceSchema + ".applier_lag"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeApplierLag) Help() string {
+	return "Collect the apply lag of replication channels from performance_schema.replication_applier_status_by_worker"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeApplierLag) Version() float64 {
+	return 8.0
+}
+
+// Stability of the Scraper.
+func (ScrapeApplierLag) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeApplierLag) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	rows, err := db.QueryContext(ctx, perfApplierLagQuery)
+	if err != nil {
+		// The transaction timestamps were added in 8.0.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && (mysqlErr.Number == 1054 || mysqlErr.Number == 1146) {
+			level.Debug(logger).Log("msg", "Replication applier timestamps are not available", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer rows.Close()
+
+	type lastApplied struct {
+		commit, endApply float64
+	}
+	// The workers of a multi-threaded replica apply transactions in
+	// parallel, the worker that finished last has the latest transaction.
+	channels := map[string]lastApplied{}
+	var channelName, originalCommit, endTime string
+	for rows.Next() {
+		if err := rows.Scan(&channelName, &originalCommit, &endTime); err != nil {
+			return err
+		}
+		endApply, ok := parseApplierTimestamp(endTime)
+		if !ok {
+			// The worker did not apply a transaction yet.
+			continue
+		}
+		commit, ok := parseApplierTimestamp(originalCommit)
+		if !ok {
+			continue
+		}
+		if last, ok := channels[channelName]; !ok || endApply > last.endApply {
+			channels[channelName] = lastApplied{commit: commit, endApply: endApply}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		last := channels[name]
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaApplierLagDesc, prometheus.GaugeValue, last.endApply-last.commit, name,
+		)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeApplierLag{}
@@ -0,0 +1,104 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeApplierLag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	timeZero := "0000-00-00 00:00:00.000000"
+	stubTime := time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string {
+		return stubTime.Add(d).Format(timeLayout)
+	}
+
+	columns := []string{
+		"CHANNEL_NAME",
+		"LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
+		"LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP",
+	}
+	rows := sqlmock.NewRows(columns).
+		// Parallel workers, the second finished last.
+		AddRow("source_a", at(0), at(1500*time.Millisecond)).
+		AddRow("source_a", at(2*time.Second), at(2500*time.Millisecond)).
+		AddRow("source_a", timeZero, timeZero).
+		// No transaction applied yet.
+		AddRow("source_b", timeZero, timeZero).
+		AddRow("", at(0), at(3*time.Second))
+	mock.ExpectQuery(sanitizeQuery(perfApplierLagQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeApplierLag{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"channel_name": ""}, value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_a"}, value: 0.5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeApplierLagIdle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	timeZero := "0000-00-00 00:00:00.000000"
+	columns := []string{
+		"CHANNEL_NAME",
+		"LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
+		"LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP",
+	}
+	mock.ExpectQuery(sanitizeQuery(perfApplierLagQuery)).WillReturnRows(sqlmock.NewRows(columns).AddRow("", timeZero, timeZero))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeApplierLag{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	convey.Convey("No metrics before a transaction is applied", t, func() {
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.clone_status` and `performance_schema.clone_progress`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	clone = "clone"
+	// Queries.
+	perfCloneStatusQuery = `
+		SELECT STATE, ERROR_NO
+		  FROM performance_schema.clone_status
+		`
+	perfCloneProgressQuery = `
+		SELECT STAGE, STATE, ESTIMATE, DATA
+		  FROM performance_schema.clone_progress
+		`
+)
+
+// Metric descriptors.
+var (
+	cloneStateInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "state_info"),
+		"State of the current or last clone operation.",
+		[]string{"state"}, nil,
+	)
+	cloneErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "error_number"),
+		"Error number of the current or last clone operation, 0 without error.",
+		nil, nil,
+	)
+	cloneStageStateInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "stage_state_info"),
+		"State of each stage of the current or last clone operation.",
+		[]string{"stage", "state"}, nil,
+	)
+	cloneEstimateBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "estimate_bytes"),
+		"Estimated amount of data to transfer in the clone stage.",
+		[]string{"stage"}, nil,
+	)
+	cloneDataBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "data_bytes"),
+		"Amount of data transferred so far in the clone stage.",
+		[]string{"stage"}, nil,
+	)
+	cloneProgressRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, clone, "progress_ratio"),
+		"Ratio of transferred to estimated data in the clone stage.",
+		[]string{"stage"}, nil,
+	)
+)
+
+// ScrapeClonePlugin collects the progress of clone operations of the clone plugin.
+type ScrapeClonePlugin struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeClonePlugin) Name() string {
+	return performanceSchema + ".clone"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeClonePlugin) Help() string {
+	return "Collect the state and progress of clone operations from performance_schema.clone_status and clone_progress"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeClonePlugin) Version() float64 {
+	return 8.0
+}
+
+// Stability of the Scraper.
+func (ScrapeClonePlugin) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeClonePlugin) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, perfCloneStatusQuery)
+	if err != nil {
+		// The tables only exist while the clone plugin is installed.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "Clone plugin is not installed", "err", err)
+			return nil
+		}
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		state, stage   string
+		errorNo        float64
+		estimate, data uint64
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&state, &errorNo); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(cloneStateInfoDesc, prometheus.GaugeValue, 1, state)
+		ch <- prometheus.MustNewConstMetric(cloneErrorDesc, prometheus.GaugeValue, errorNo)
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	progressRows, err := db.QueryContext(ctx, perfCloneProgressQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer progressRows.Close()
+
+	for progressRows.Next() {
+		if err := progressRows.Scan(&stage, &state, &estimate, &data); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(cloneStageStateInfoDesc, prometheus.GaugeValue, 1, stage, state)
+		ch <- prometheus.MustNewConstMetric(cloneEstimateBytesDesc, prometheus.GaugeValue, float64(estimate), stage)
+		ch <- prometheus.MustNewConstMetric(cloneDataBytesDesc, prometheus.GaugeValue, float64(data), stage)
+		// Stages without an estimate, e.g. those not started yet, have no ratio.
+		if estimate > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				cloneProgressRatioDesc, prometheus.GaugeValue, float64(data)/float64(estimate), stage,
+			)
+		}
+	}
+	return wrapDriverError(progressRows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapeClonePlugin{}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeClonePlugin(t *testing.T) {
+	statusColumns := []string{"STATE", "ERROR_NO"}
+	progressColumns := []string{"STAGE", "STATE", "ESTIMATE", "DATA"}
+
+	for _, tt := range []struct {
+		name     string
+		status   *sqlmock.Rows
+		progress *sqlmock.Rows
+		expected []MetricResult
+	}{
+		{
+			name:   "in progress",
+			status: sqlmock.NewRows(statusColumns).AddRow("In Progress", "0"),
+			progress: sqlmock.NewRows(progressColumns).
+				AddRow("DROP DATA", "Completed", "0", "0").
+				AddRow("FILE COPY", "In Progress", "4000", "1000").
+				AddRow("PAGE COPY", "Not Started", "0", "0"),
+			expected: []MetricResult{
+				{labels: labelMap{"state": "In Progress"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "DROP DATA", "state": "Completed"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "DROP DATA"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "DROP DATA"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "FILE COPY", "state": "In Progress"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "FILE COPY"}, value: 4000, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "FILE COPY"}, value: 1000, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "FILE COPY"}, value: 0.25, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "PAGE COPY", "state": "Not Started"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "PAGE COPY"}, value: 0, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"stage": "PAGE COPY"}, value: 0, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name:     "idle",
+			status:   sqlmock.NewRows(statusColumns),
+			progress: sqlmock.NewRows(progressColumns),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(sanitizeQuery(perfCloneStatusQuery)).WillReturnRows(tt.status)
+			mock.ExpectQuery(sanitizeQuery(perfCloneProgressQuery)).WillReturnRows(tt.progress)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeClonePlugin{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("Clone metrics are collected", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeClonePluginNotInstalled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfCloneStatusQuery)).
+		WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'performance_schema.clone_status' doesn't exist"})
+
+	metrics, err := CollectOnce(context.Background(), ScrapeClonePlugin{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("expected no error without the clone plugin, got %s", err)
+	}
+	if len(metrics) != 0 {
+		t.Errorf("expected no metrics, got %d", len(metrics))
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
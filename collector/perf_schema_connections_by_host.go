@@ -0,0 +1,190 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.hosts` and `performance_schema.users`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Background threads have no host and user.
+	perfHostsQuery = `
+	SELECT HOST, CURRENT_CONNECTIONS, TOTAL_CONNECTIONS
+	  FROM performance_schema.hosts
+	  WHERE HOST IS NOT NULL
+	`
+	perfUsersQuery = `
+	SELECT USER, CURRENT_CONNECTIONS, TOTAL_CONNECTIONS
+	  FROM performance_schema.users
+	  WHERE USER IS NOT NULL
+	`
+)
+
+// Tunable flags.
+var (
+	perfConnectionsByHostTopN = kingpin.Flag(
+		"collect.perf_schema.connections_by_host.top_n",
+		"Only collect the N hosts and N users with the most current connections, 0 collects all",
+	).Default("0").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaHostConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "host_connections"),
+		"The number of current connections from the client host.",
+		[]string{"host"}, nil,
+	)
+	performanceSchemaHostConnectionsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "host_connections_total"),
+		"The total number of connections from the client host.",
+		[]string{"host"}, nil,
+	)
+	performanceSchemaUserConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "user_connections"),
+		"The number of current connections of the user.",
+		[]string{"user"}, nil,
+	)
+	performanceSchemaUserConnectionsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "user_connections_total"),
+		"The total number of connections of the user.",
+		[]string{"user"}, nil,
+	)
+)
+
+// clientConnections are the connections of a host or user.
+type clientConnections struct {
+	name           string
+	current, total uint64
+}
+
+// topConnections returns the n clients with the most current connections,
+// then the most total connections, then by name. n of 0 returns all.
+func topConnections(clients []clientConnections, n int) []clientConnections {
+	sort.SliceStable(clients, func(i, j int) bool {
+		if clients[i].current != clients[j].current {
+			return clients[i].current > clients[j].current
+		}
+		if clients[i].total != clients[j].total {
+			return clients[i].total > clients[j].total
+		}
+		return clients[i].name < clients[j].name
+	})
+	if n > 0 && n < len(clients) {
+		clients = clients[:n]
+	}
+	return clients
+}
+
+// ScrapeConnectionsByHost collects the connections by client host and user,
+// to find the clients driving the connection load.
+type ScrapeConnectionsByHost struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeConnectionsByHost) Name() string {
+	return performanceSchema + ".connections_by_host"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeConnectionsByHost) Help() string {
+	return "Collect the current and total connections by host and user from performance_schema.hosts and performance_schema.users"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeConnectionsByHost) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeConnectionsByHost) Stability() string {
+	return StabilityExperimental
+}
+
+// ValidateConfig checks collect.perf_schema.connections_by_host.top_n.
+func (ScrapeConnectionsByHost) ValidateConfig() error {
+	if n := *perfConnectionsByHostTopN; n < 0 {
+		err := fmt.Errorf("collect.perf_schema.connections_by_host.top_n must not be negative, got %d", n)
+		return newConfigError("top_n", ReasonOutOfRange, err)
+	}
+	return nil
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (s ScrapeConnectionsByHost) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	if err := s.ValidateConfig(); err != nil {
+		return newScrapeError(ErrConfig, err)
+	}
+	var enabled bool
+	if err := db.QueryRowContext(ctx, perfSchemaEnabledQuery).Scan(&enabled); err != nil {
+		return wrapDriverError(err)
+	}
+	if !enabled {
+		level.Debug(logger).Log("msg", "performance_schema is disabled, skipping connections by host")
+		return nil
+	}
+
+	for _, table := range []struct {
+		query                  string
+		currentDesc, totalDesc *prometheus.Desc
+	}{
+		{perfHostsQuery, performanceSchemaHostConnectionsDesc, performanceSchemaHostConnectionsTotalDesc},
+		{perfUsersQuery, performanceSchemaUserConnectionsDesc, performanceSchemaUserConnectionsTotalDesc},
+	} {
+		clients, err := queryClientConnections(ctx, db, table.query)
+		if err != nil {
+			return err
+		}
+		for _, c := range topConnections(clients, *perfConnectionsByHostTopN) {
+			ch <- prometheus.MustNewConstMetric(table.currentDesc, prometheus.GaugeValue, float64(c.current), c.name)
+			ch <- prometheus.MustNewConstMetric(table.totalDesc, prometheus.CounterValue, float64(c.total), c.name)
+		}
+	}
+	return nil
+}
+
+// queryClientConnections returns the connections of the hosts or users of
+// query.
+func queryClientConnections(ctx context.Context, db *sql.DB, query string) ([]clientConnections, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, wrapDriverError(err)
+	}
+	defer rows.Close()
+
+	var clients []clientConnections
+	for rows.Next() {
+		var c clientConnections
+		if err := rows.Scan(&c.name, &c.current, &c.total); err != nil {
+			return nil, newScrapeError(ErrParse, err)
+		}
+		clients = append(clients, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapDriverError(err)
+	}
+	return clients, nil
+}
+
+// check interface
+var _ Scraper = ScrapeConnectionsByHost{}
@@ -0,0 +1,126 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeConnectionsByHost(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		topN     string
+		expected []MetricResult
+	}{
+		{
+			name: "all hosts and users",
+			topN: "0",
+			expected: []MetricResult{
+				{labels: labelMap{"host": "app2"}, value: 40, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"host": "app2"}, value: 900, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"host": "app1"}, value: 10, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"host": "app1"}, value: 5000, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"host": "localhost"}, value: 10, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"host": "localhost"}, value: 200, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"user": "app"}, value: 50, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"user": "app"}, value: 5900, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"user": "exporter"}, value: 10, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"user": "exporter"}, value: 200, metricType: dto.MetricType_COUNTER},
+			},
+		},
+		{
+			name: "busiest host and user",
+			topN: "1",
+			expected: []MetricResult{
+				{labels: labelMap{"host": "app2"}, value: 40, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"host": "app2"}, value: 900, metricType: dto.MetricType_COUNTER},
+				{labels: labelMap{"user": "app"}, value: 50, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"user": "app"}, value: 5900, metricType: dto.MetricType_COUNTER},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kingpin.CommandLine.Parse([]string{"--collect.perf_schema.connections_by_host.top_n", tt.topN})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kingpin.CommandLine.Parse([]string{})
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+			// Hosts with equal current connections are ordered by total connections.
+			mock.ExpectQuery(sanitizeQuery(perfHostsQuery)).WillReturnRows(
+				sqlmock.NewRows([]string{"HOST", "CURRENT_CONNECTIONS", "TOTAL_CONNECTIONS"}).
+					AddRow("localhost", 10, 200).
+					AddRow("app1", 10, 5000).
+					AddRow("app2", 40, 900))
+			mock.ExpectQuery(sanitizeQuery(perfUsersQuery)).WillReturnRows(
+				sqlmock.NewRows([]string{"USER", "CURRENT_CONNECTIONS", "TOTAL_CONNECTIONS"}).
+					AddRow("exporter", 10, 200).
+					AddRow("app", 50, 5900))
+
+			metrics, err := CollectOnce(context.Background(), ScrapeConnectionsByHost{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("Metrics comparison", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
+
+func TestScrapeConnectionsByHostDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(0))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeConnectionsByHost{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	convey.Convey("No metrics without performance_schema", t, func() {
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
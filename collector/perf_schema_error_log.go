@@ -0,0 +1,111 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.error_log`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	MySQL "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfErrorLogQuery = `
+	SELECT PRIO, ERROR_CODE, COUNT(*)
+	  FROM performance_schema.error_log
+	  WHERE LOGGED > NOW(6) - INTERVAL %d SECOND
+	  GROUP BY PRIO, ERROR_CODE
+	`
+
+// Tunable flags.
+var (
+	perfErrorLogWindow = kingpin.Flag(
+		"collect.perf_schema.error_log.window",
+		"Only count error log events logged within this many seconds.",
+	).Default("3600").Int()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaErrorLogEventsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "error_log_events"),
+		"The number of error log events within the window by priority and error code.",
+		[]string{"prio", "error_code"}, nil,
+	)
+)
+
+// ScrapePerfErrorLog collects from `performance_schema.error_log`.
+type ScrapePerfErrorLog struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfErrorLog) Name() string {
+	return "perf_schema.error_log"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfErrorLog) Help() string {
+	return "Collect metrics from performance_schema.error_log"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfErrorLog) Version() float64 {
+	return 8.0
+}
+
+// Stability of the Scraper.
+func (ScrapePerfErrorLog) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfErrorLog) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	perfErrorLogRows, err := db.QueryContext(ctx, fmt.Sprintf(perfErrorLogQuery, *perfErrorLogWindow))
+	if err != nil {
+		// Check for error 1146: Table doesn't exist (before MySQL 8.0.22).
+		// Scraper versions have no patch level, so 8.0.0 to 8.0.21 end up
+		// here.
+		var mysqlErr *MySQL.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "performance_schema.error_log is not available.")
+			return nil
+		}
+		return err
+	}
+	defer perfErrorLogRows.Close()
+
+	var (
+		prio, errorCode string
+		count           uint64
+	)
+	for perfErrorLogRows.Next() {
+		if err := perfErrorLogRows.Scan(&prio, &errorCode, &count); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaErrorLogEventsDesc, prometheus.GaugeValue, float64(count),
+			prio, errorCode,
+		)
+	}
+	return perfErrorLogRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfErrorLog{}
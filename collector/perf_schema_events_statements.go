@@ -167,6 +167,29 @@ func (ScrapePerfEventsStatements) Version() float64 {
 	return 5.6
 }
 
+// ValidateConfig checks that the limits are not negative.
+func (ScrapePerfEventsStatements) ValidateConfig() error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"limit", *perfEventsStatementsLimit},
+		{"timelimit", *perfEventsStatementsTimeLimit},
+		{"digest_text_limit", *perfEventsStatementsDigestTextLimit},
+	}
+	var errs ConfigErrors
+	for _, l := range limits {
+		if l.value < 0 {
+			err := fmt.Errorf("collect.perf_schema.eventsstatements.%s must not be negative, got %d", l.name, l.value)
+			errs = append(errs, newConfigError(l.name, ReasonOutOfRange, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfEventsStatements) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
 	perfQuery := fmt.Sprintf(
@@ -18,8 +18,11 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -32,6 +35,18 @@ const perfFileEventsQuery = `
 	  FROM performance_schema.file_summary_by_event_name
 	`
 
+// Tunable flags.
+var (
+	performanceSchemaFileEventsRemovePrefix = kingpin.Flag(
+		"collect.perf_schema.file_events.remove_prefix",
+		"Remove instrument prefix in performance_schema.file_summary_by_event_name, e.g. wait/io/file/",
+	).Default("").String()
+	performanceSchemaFileEventsMinTime = kingpin.Flag(
+		"collect.perf_schema.file_events.min_time",
+		"Skip event names whose total wait time is below this duration",
+	).Default("0s").Duration()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaFileEventsDesc = prometheus.NewDesc(
@@ -71,7 +86,17 @@ func (ScrapePerfFileEvents) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfFileEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var enabled bool
+	if err := db.QueryRowContext(ctx, perfSchemaEnabledQuery).Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		level.Debug(logger).Log("msg", "performance_schema is disabled, skipping file events")
+		return nil
+	}
+
 	// Timers here are returned in picoseconds.
+	minTime := performanceSchemaFileEventsMinTime.Seconds() * picoSeconds
 	perfSchemaFileEventsRows, err := db.QueryContext(ctx, perfFileEventsQuery)
 	if err != nil {
 		return err
@@ -93,6 +118,11 @@ func (ScrapePerfFileEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- pr
 		); err != nil {
 			return err
 		}
+		if float64(timeRead+timeWrite+timeMisc) < minTime {
+			continue
+		}
+
+		eventName := strings.TrimPrefix(eventName, *performanceSchemaFileEventsRemovePrefix)
 		ch <- prometheus.MustNewConstMetric(
 			performanceSchemaFileEventsDesc, prometheus.CounterValue, float64(countRead),
 			eventName, "read",
@@ -126,7 +156,7 @@ func (ScrapePerfFileEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<- pr
 			eventName, "misc",
 		)
 	}
-	return nil
+	return perfSchemaFileEventsRows.Err()
 }
 
 // check interface
@@ -0,0 +1,120 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfFileEvents(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.file_events.remove_prefix=wait/io/file/",
+		"--collect.perf_schema.file_events.min_time=1s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.file_events.remove_prefix=",
+		"--collect.perf_schema.file_events.min_time=0s",
+	})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"EVENT_NAME",
+		"COUNT_READ", "SUM_TIMER_READ", "SUM_NUMBER_OF_BYTES_READ",
+		"COUNT_WRITE", "SUM_TIMER_WRITE", "SUM_NUMBER_OF_BYTES_WRITE",
+		"COUNT_MISC", "SUM_TIMER_MISC",
+	}
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(perfFileEventsQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("wait/io/file/innodb/innodb_data_file", "10", "2000000000000", "163840", "20", "3000000000000", "327680", "5", "500000000000").
+		AddRow("wait/io/file/sql/FRM", "1", "1000000", "4096", "0", "0", "0", "1", "1000000"))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfFileEvents{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	eventName := "innodb/innodb_data_file"
+	metricExpected := []MetricResult{
+		{labels: labelMap{"event_name": eventName, "mode": "read"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "read"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "read"}, value: 163840, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "write"}, value: 20, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "write"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "write"}, value: 327680, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "misc"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"event_name": eventName, "mode": "misc"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		convey.So(got, convey.ShouldResemble, metricExpected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfFileEventsDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(0))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfFileEvents{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Nothing is collected when performance_schema is off", t, func() {
+		var metrics int
+		for range ch {
+			metrics++
+		}
+		convey.So(metrics, convey.ShouldEqual, 0)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the relay log positions of `performance_schema.log_status`.
+
+package collector
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// relayLog is the Metric subsystem we use.
+	relayLog = "relay_log"
+	// perfLogStatusQuery selects the replication positions of log_status.
+	perfLogStatusQuery = `SELECT REPLICATION FROM performance_schema.log_status`
+)
+
+// Metric descriptors.
+var (
+	relayLogPositionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, relayLog, "position"),
+		"Position up to which the relay log file of the channel was written.",
+		[]string{"channel_name"}, nil,
+	)
+	relayLogFileNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, relayLog, "file_number"),
+		"The number of the relay log file the channel writes to.",
+		[]string{"channel_name"}, nil,
+	)
+)
+
+// logStatusNumber is a number of the log_status JSON columns, which some
+// versions quote.
+type logStatusNumber struct {
+	value float64
+	set   bool
+}
+
+func (n *logStatusNumber) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" || len(data) == 0 {
+		return nil
+	}
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid log_status number %s: %w", data, err)
+	}
+	n.value, n.set = v, true
+	return nil
+}
+
+// logStatusReplication is the REPLICATION column of log_status. Unknown
+// fields are ignored.
+type logStatusReplication struct {
+	Channels []struct {
+		ChannelName      string          `json:"channel_name"`
+		RelayLogFile     string          `json:"relay_log_file"`
+		RelayLogPosition logStatusNumber `json:"relay_log_position"`
+	} `json:"channels"`
+}
+
+// relayLogFileNumber returns the number of a relay log file name, e.g. 2
+// for "./relay-bin.000002".
+func relayLogFileNumber(file string) (float64, bool) {
+	i := strings.LastIndex(file, ".")
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(file[i+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return float64(n), true
+}
+
+// ScrapeRelayLogStatus collects the relay log positions of the replication
+// channels, which log_status reports without pt-heartbeat.
+type ScrapeRelayLogStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeRelayLogStatus) Name() string {
+	return performanceSchema + ".log_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeRelayLogStatus) Help() string {
+	return "Collect the relay log positions of the replication channels from performance_schema.log_status"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeRelayLogStatus) Version() float64 {
+	return 8.0
+}
+
+// Stability of the Scraper.
+func (ScrapeRelayLogStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeRelayLogStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var replication sql.NullString
+	err := db.QueryRowContext(ctx, perfLogStatusQuery).Scan(&replication)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		// log_status was added in 8.0.14 and needs BACKUP_ADMIN.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && (mysqlErr.Number == 1146 || mysqlErr.Number == 1142 || mysqlErr.Number == 1227) {
+			level.Debug(logger).Log("msg", "performance_schema.log_status is not available", "err", err)
+			return nil
+		}
+		return err
+	}
+	if !replication.Valid || replication.String == "" {
+		return nil
+	}
+
+	var status logStatusReplication
+	if err := json.Unmarshal([]byte(replication.String), &status); err != nil {
+		return newScrapeError(ErrParse, fmt.Errorf("parsing log_status replication: %w", err))
+	}
+	for _, channel := range status.Channels {
+		if channel.RelayLogPosition.set {
+			ch <- prometheus.MustNewConstMetric(
+				relayLogPositionDesc, prometheus.GaugeValue, channel.RelayLogPosition.value, channel.ChannelName,
+			)
+		}
+		if number, ok := relayLogFileNumber(channel.RelayLogFile); ok {
+			ch <- prometheus.MustNewConstMetric(
+				relayLogFileNumberDesc, prometheus.GaugeValue, number, channel.ChannelName,
+			)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeRelayLogStatus{}
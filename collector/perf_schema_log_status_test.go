@@ -0,0 +1,121 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const logStatusReplicationFixture = `{
+  "channels": [
+    {"channel_name": "", "relay_log_file": "./relay-bin.000002", "relay_log_position": 1543,
+     "relay_master_log_file": "binlog.000007", "exec_master_log_position": 1219},
+    {"channel_name": "source_2", "relay_log_file": "./relay-bin-source_2.000011", "relay_log_position": "157"}
+  ]
+}`
+
+func TestScrapeRelayLogStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"REPLICATION"}).AddRow(logStatusReplicationFixture))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeRelayLogStatus{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"channel_name": ""}, value: 1543, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 2, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_2"}, value: 157, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_2"}, value: 11, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeRelayLogStatusUnavailable(t *testing.T) {
+	for name, setup := range map[string]func(sqlmock.Sqlmock){
+		"not a replica": func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"REPLICATION"}).AddRow(`{"channels": []}`))
+		},
+		"no row": func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"REPLICATION"}))
+		},
+		"missing BACKUP_ADMIN": func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+				WillReturnError(&mysql.MySQLError{Number: 1227, Message: "Access denied; you need (at least one of) the BACKUP_ADMIN privilege(s) for this operation"})
+		},
+		"missing table": func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+				WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'performance_schema.log_status' doesn't exist"})
+		},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		setup(mock)
+
+		metrics, err := CollectOnce(context.Background(), ScrapeRelayLogStatus{}, db, log.NewNopLogger())
+		convey.Convey("No metrics are collected: "+name, t, func() {
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(metrics, convey.ShouldBeEmpty)
+		})
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestScrapeRelayLogStatusInvalidJSON(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfLogStatusQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"REPLICATION"}).AddRow(`{"channels": [{"relay_log_position": "n/a"}]}`))
+
+	_, err = CollectOnce(context.Background(), ScrapeRelayLogStatus{}, db, log.NewNopLogger())
+	convey.Convey("Invalid JSON is a parse error", t, func() {
+		convey.So(errorClass(err), convey.ShouldEqual, ErrParse)
+	})
+}
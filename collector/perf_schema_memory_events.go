@@ -28,7 +28,7 @@ import (
 const perfMemoryEventsQuery = `
 	SELECT
 		EVENT_NAME, SUM_NUMBER_OF_BYTES_ALLOC, SUM_NUMBER_OF_BYTES_FREE,
-		CURRENT_NUMBER_OF_BYTES_USED
+		CURRENT_NUMBER_OF_BYTES_USED, HIGH_NUMBER_OF_BYTES_USED
 	FROM performance_schema.memory_summary_global_by_event_name
 		where COUNT_ALLOC > 0;
 `
@@ -39,6 +39,10 @@ var (
 		"collect.perf_schema.memory_events.remove_prefix",
 		"Remove instrument prefix in performance_schema.memory_summary_global_by_event_name",
 	).Default("memory/").String()
+	performanceSchemaMemoryEventsOnlyAllocated = kingpin.Flag(
+		"collect.perf_schema.memory_events.only_allocated",
+		"Only collect events currently holding memory in performance_schema.memory_summary_global_by_event_name, bounding the number of series",
+	).Default("false").Bool()
 )
 
 // Metric descriptors.
@@ -58,6 +62,11 @@ var (
 		"The number of bytes currently allocated by events.",
 		[]string{"event_name"}, nil,
 	)
+	performanceSchemaMemoryHighWaterMarkBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "memory_events_high_water_mark_bytes"),
+		"The highest number of bytes allocated by events at once.",
+		[]string{"event_name"}, nil,
+	)
 )
 
 // ScrapePerfMemoryEvents collects from `performance_schema.memory_summary_global_by_event_name`.
@@ -91,14 +100,18 @@ func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 		bytesAlloc   uint64
 		bytesFree    uint64
 		currentBytes int64
+		highBytes    int64
 	)
 
 	for perfSchemaMemoryEventsRows.Next() {
 		if err := perfSchemaMemoryEventsRows.Scan(
-			&eventName, &bytesAlloc, &bytesFree, &currentBytes,
+			&eventName, &bytesAlloc, &bytesFree, &currentBytes, &highBytes,
 		); err != nil {
 			return err
 		}
+		if *performanceSchemaMemoryEventsOnlyAllocated && currentBytes <= 0 {
+			continue
+		}
 
 		eventName := strings.TrimPrefix(eventName, *performanceSchemaMemoryEventsRemovePrefix)
 		ch <- prometheus.MustNewConstMetric(
@@ -110,8 +123,11 @@ func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 		ch <- prometheus.MustNewConstMetric(
 			perforanceSchemaMemoryUsedBytesDesc, prometheus.GaugeValue, float64(currentBytes), eventName,
 		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMemoryHighWaterMarkBytesDesc, prometheus.GaugeValue, float64(highBytes), eventName,
+		)
 	}
-	return nil
+	return perfSchemaMemoryEventsRows.Err()
 }
 
 // check interface
@@ -43,13 +43,14 @@ func TestScrapePerfMemoryEvents(t *testing.T) {
 		"SUM_NUMBER_OF_BYTES_ALLOC",
 		"SUM_NUMBER_OF_BYTES_FREE",
 		"CURRENT_NUMBER_OF_BYTES_USED",
+		"HIGH_NUMBER_OF_BYTES_USED",
 	}
 
 	rows := sqlmock.NewRows(columns).
-		AddRow("memory/innodb/event1", "1001", "500", "501").
-		AddRow("memory/performance_schema/event1", "6000", "7", "-83904").
-		AddRow("memory/innodb/event2", "2002", "1000", "1002").
-		AddRow("memory/sql/event1", "30", "4", "26")
+		AddRow("memory/innodb/event1", "1001", "500", "501", "800").
+		AddRow("memory/performance_schema/event1", "6000", "7", "-83904", "5993").
+		AddRow("memory/innodb/event2", "2002", "1000", "1002", "1002").
+		AddRow("memory/sql/event1", "30", "4", "26", "30")
 	mock.ExpectQuery(sanitizeQuery(perfMemoryEventsQuery)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -64,15 +65,19 @@ func TestScrapePerfMemoryEvents(t *testing.T) {
 		{labels: labelMap{"event_name": "innodb/event1"}, value: 1001, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "innodb/event1"}, value: 500, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "innodb/event1"}, value: 501, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "innodb/event1"}, value: 800, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"event_name": "performance_schema/event1"}, value: 6000, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "performance_schema/event1"}, value: 7, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "performance_schema/event1"}, value: -83904, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "performance_schema/event1"}, value: 5993, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"event_name": "innodb/event2"}, value: 2002, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "innodb/event2"}, value: 1000, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "innodb/event2"}, value: 1002, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "innodb/event2"}, value: 1002, metricType: dto.MetricType_GAUGE},
 		{labels: labelMap{"event_name": "sql/event1"}, value: 30, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "sql/event1"}, value: 4, metricType: dto.MetricType_COUNTER},
 		{labels: labelMap{"event_name": "sql/event1"}, value: 26, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "sql/event1"}, value: 30, metricType: dto.MetricType_GAUGE},
 	}
 	convey.Convey("Metrics comparison", t, func() {
 		for _, expect := range metricExpected {
@@ -86,3 +91,52 @@ func TestScrapePerfMemoryEvents(t *testing.T) {
 		t.Errorf("there were unfulfilled exceptions: %s", err)
 	}
 }
+
+func TestScrapePerfMemoryEventsOnlyAllocated(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{"--collect.perf_schema.memory_events.only_allocated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"EVENT_NAME",
+		"SUM_NUMBER_OF_BYTES_ALLOC",
+		"SUM_NUMBER_OF_BYTES_FREE",
+		"CURRENT_NUMBER_OF_BYTES_USED",
+		"HIGH_NUMBER_OF_BYTES_USED",
+	}
+	rows := sqlmock.NewRows(columns).
+		AddRow("memory/innodb/buf_buf_pool", "137428992", "0", "137428992", "137428992").
+		AddRow("memory/sql/THD::main_mem_root", "5000", "5000", "0", "2048").
+		AddRow("memory/performance_schema/table_handles", "6000", "7", "-83904", "5993").
+		AddRow("memory/sql/TABLE", "30", "4", "26", "30")
+	mock.ExpectQuery(sanitizeQuery(perfMemoryEventsQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfMemoryEvents{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	events := map[string]bool{}
+	for m := range ch {
+		events[readMetric(m).labels["event_name"]] = true
+	}
+	convey.Convey("Only events currently holding memory are collected", t, func() {
+		convey.So(events, convey.ShouldResemble, map[string]bool{"innodb/buf_buf_pool": true, "sql/TABLE": true})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
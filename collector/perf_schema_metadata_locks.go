@@ -0,0 +1,174 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape pending locks from `performance_schema.metadata_locks`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// perfMetadataLockInstrumentQuery returns whether metadata locks are
+// instrumented. The instrument is disabled by default before MySQL 8.0,
+// metadata_locks is then empty.
+const perfMetadataLockInstrumentQuery = `
+	SELECT ENABLED
+	  FROM performance_schema.setup_instruments
+	  WHERE NAME = 'wait/lock/metadata/sql/mdl'
+	`
+
+const perfMetadataLockPendingQuery = `
+	SELECT LOCK_TYPE, COUNT(*)
+	  FROM performance_schema.metadata_locks
+	  WHERE LOCK_STATUS = 'PENDING'
+	  GROUP BY LOCK_TYPE
+	`
+
+const perfMetadataLockPendingDetailedQuery = `
+	SELECT OBJECT_SCHEMA, OBJECT_NAME, LOCK_TYPE, COUNT(*)
+	  FROM performance_schema.metadata_locks
+	  WHERE LOCK_STATUS = 'PENDING'
+	  GROUP BY OBJECT_SCHEMA, OBJECT_NAME, LOCK_TYPE
+	`
+
+// Tunable flags.
+var (
+	perfMetadataLocksDetailed = kingpin.Flag(
+		"collect.perf_schema.metadata_locks.detailed",
+		"Break pending metadata locks down by object_schema and object_name in addition to lock_type",
+	).Default("false").Bool()
+)
+
+// Metric descriptors.
+var (
+	performanceSchemaMetadataLockPendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "metadata_lock_pending"),
+		"The number of pending metadata lock requests, by lock type.",
+		[]string{"lock_type"}, nil,
+	)
+	performanceSchemaMetadataLockPendingDetailedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "metadata_lock_pending"),
+		"The number of pending metadata lock requests, by object and lock type.",
+		[]string{"object_schema", "object_name", "lock_type"}, nil,
+	)
+)
+
+// ScrapeMetadataLocks collects the pending metadata lock requests, which
+// leave queries "Waiting for table metadata lock".
+type ScrapeMetadataLocks struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeMetadataLocks) Name() string {
+	return performanceSchema + ".metadata_locks"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeMetadataLocks) Help() string {
+	return "Collect the number of pending metadata lock requests from performance_schema.metadata_locks"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeMetadataLocks) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeMetadataLocks) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeMetadataLocks) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var enabled string
+	err := queryScalar(ctx, db, perfMetadataLockInstrumentQuery, &enabled)
+	var mysqlErr *mysql.MySQLError
+	if errors.Is(err, errNoScalarRow) || (errors.As(err, &mysqlErr) && mysqlErr.Number == 1146) {
+		level.Debug(logger).Log("msg", "Metadata lock instrumentation is not available", "err", err)
+		return nil
+	}
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	if on, _ := parseBoolMetric(enabled); on == 0 {
+		level.Debug(logger).Log("msg", "Metadata lock instrument wait/lock/metadata/sql/mdl is disabled")
+		return nil
+	}
+
+	if *perfMetadataLocksDetailed {
+		return scrapeMetadataLockPendingDetailed(ctx, db, ch)
+	}
+	return scrapeMetadataLockPending(ctx, db, ch)
+}
+
+// scrapeMetadataLockPendingDetailed sends the pending metadata lock requests
+// by object and lock type.
+func scrapeMetadataLockPendingDetailed(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	lockRows, err := db.QueryContext(ctx, perfMetadataLockPendingDetailedQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer lockRows.Close()
+
+	var (
+		objectSchema, objectName sql.NullString
+		lockType                 string
+		count                    uint64
+	)
+	for lockRows.Next() {
+		// Locks on e.g. the global read lock have no object.
+		if err := lockRows.Scan(&objectSchema, &objectName, &lockType, &count); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMetadataLockPendingDetailedDesc, prometheus.GaugeValue, float64(count),
+			objectSchema.String, objectName.String, lockType,
+		)
+	}
+	return wrapDriverError(lockRows.Err())
+}
+
+// scrapeMetadataLockPending sends the pending metadata lock requests by lock
+// type.
+func scrapeMetadataLockPending(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	lockRows, err := db.QueryContext(ctx, perfMetadataLockPendingQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer lockRows.Close()
+
+	var (
+		lockType string
+		count    uint64
+	)
+	for lockRows.Next() {
+		if err := lockRows.Scan(&lockType, &count); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMetadataLockPendingDesc, prometheus.GaugeValue, float64(count), lockType,
+		)
+	}
+	return wrapDriverError(lockRows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapeMetadataLocks{}
@@ -0,0 +1,106 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeMetadataLocks(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+
+	for _, detailed := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		mock.ExpectQuery(sanitizeQuery(perfMetadataLockInstrumentQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"ENABLED"}).AddRow("YES"))
+		var expected []MetricResult
+		if detailed {
+			if _, err := kingpin.CommandLine.Parse([]string{"--collect.perf_schema.metadata_locks.detailed"}); err != nil {
+				t.Fatal(err)
+			}
+			rows := sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "LOCK_TYPE", "COUNT(*)"}).
+				AddRow("shop", "orders", "SHARED_READ", "3").
+				AddRow("shop", "orders", "EXCLUSIVE", "1").
+				AddRow(nil, nil, "SHARED", "2")
+			mock.ExpectQuery(sanitizeQuery(perfMetadataLockPendingDetailedQuery)).WillReturnRows(rows)
+			expected = []MetricResult{
+				{labels: labelMap{"object_schema": "shop", "object_name": "orders", "lock_type": "SHARED_READ"}, value: 3, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"object_schema": "shop", "object_name": "orders", "lock_type": "EXCLUSIVE"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"object_schema": "", "object_name": "", "lock_type": "SHARED"}, value: 2, metricType: dto.MetricType_GAUGE},
+			}
+		} else {
+			rows := sqlmock.NewRows([]string{"LOCK_TYPE", "COUNT(*)"}).
+				AddRow("EXCLUSIVE", "1").
+				AddRow("SHARED_READ", "3")
+			mock.ExpectQuery(sanitizeQuery(perfMetadataLockPendingQuery)).WillReturnRows(rows)
+			expected = []MetricResult{
+				{labels: labelMap{"lock_type": "EXCLUSIVE"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{"lock_type": "SHARED_READ"}, value: 3, metricType: dto.MetricType_GAUGE},
+			}
+		}
+
+		metrics, err := CollectOnce(context.Background(), ScrapeMetadataLocks{}, db, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("error calling function on test: %s", err)
+		}
+		var got []MetricResult
+		for _, m := range metrics {
+			got = append(got, readMetric(m))
+		}
+		convey.Convey("Pending metadata locks are collected", t, func() {
+			convey.So(got, convey.ShouldResemble, expected)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+}
+
+func TestScrapeMetadataLocksInstrumentDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfMetadataLockInstrumentQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"ENABLED"}).AddRow("NO"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeMetadataLocks{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	if len(metrics) != 0 {
+		t.Errorf("expected no metrics with the instrument disabled, got %d", len(metrics))
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
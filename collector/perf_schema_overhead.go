@@ -0,0 +1,121 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the memory and instruments used by performance_schema itself.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfOverheadMemoryQuery = `
+	SELECT
+		EVENT_NAME, CURRENT_NUMBER_OF_BYTES_USED
+	FROM performance_schema.memory_summary_global_by_event_name
+		WHERE EVENT_NAME LIKE 'memory/performance_schema/%'
+`
+
+const perfOverheadInstrumentsQuery = `
+	SELECT
+		COUNT(*), COALESCE(SUM(ENABLED = 'YES'), 0), COALESCE(SUM(TIMED = 'YES'), 0)
+	FROM performance_schema.setup_instruments
+`
+
+// Metric descriptors.
+var (
+	performanceSchemaOverheadMemoryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "overhead_memory_bytes"),
+		"The number of bytes currently allocated by performance_schema itself.",
+		[]string{"event_name"}, nil,
+	)
+	performanceSchemaOverheadInstrumentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "overhead_instruments"),
+		"The number of instruments in performance_schema.setup_instruments, by state.",
+		[]string{"state"}, nil,
+	)
+)
+
+// ScrapePerfOverhead collects the memory performance_schema allocates for
+// itself and the number of enabled instruments.
+type ScrapePerfOverhead struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfOverhead) Name() string {
+	return "perf_schema.overhead"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfOverhead) Help() string {
+	return "Collect the memory and instruments used by performance_schema itself"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfOverhead) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapePerfOverhead) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfOverhead) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	memoryRows, err := db.QueryContext(ctx, perfOverheadMemoryQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer memoryRows.Close()
+
+	var (
+		eventName    string
+		currentBytes int64
+	)
+	for rows := 0; memoryRows.Next(); rows++ {
+		if err := checkCtx(ctx, rows); err != nil {
+			return err
+		}
+		if err := memoryRows.Scan(&eventName, &currentBytes); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaOverheadMemoryDesc, prometheus.GaugeValue, float64(currentBytes), eventName,
+		)
+	}
+	if err := memoryRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	var total, enabled, timed uint64
+	if err := db.QueryRowContext(ctx, perfOverheadInstrumentsQuery).Scan(&total, &enabled, &timed); err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		performanceSchemaOverheadInstrumentsDesc, prometheus.GaugeValue, float64(total), "total",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		performanceSchemaOverheadInstrumentsDesc, prometheus.GaugeValue, float64(enabled), "enabled",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		performanceSchemaOverheadInstrumentsDesc, prometheus.GaugeValue, float64(timed), "timed",
+	)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapePerfOverhead{}
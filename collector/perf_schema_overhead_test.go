@@ -0,0 +1,68 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfOverhead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	memoryRows := sqlmock.NewRows([]string{"EVENT_NAME", "CURRENT_NUMBER_OF_BYTES_USED"}).
+		AddRow("memory/performance_schema/events_statements_history", "1416000").
+		AddRow("memory/performance_schema/table_handles", "9232384")
+	mock.ExpectQuery(sanitizeQuery(perfOverheadMemoryQuery)).WillReturnRows(memoryRows)
+	instrumentRows := sqlmock.NewRows([]string{"COUNT(*)", "enabled", "timed"}).
+		AddRow("1232", "567", "402")
+	mock.ExpectQuery(sanitizeQuery(perfOverheadInstrumentsQuery)).WillReturnRows(instrumentRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfOverhead{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"event_name": "memory/performance_schema/events_statements_history"}, value: 1416000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"event_name": "memory/performance_schema/table_handles"}, value: 9232384, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "total"}, value: 1232, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "enabled"}, value: 567, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"state": "timed"}, value: 402, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.replication_applier_filters` and
+// `performance_schema.replication_applier_global_filters`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfReplicationApplierFiltersQuery = `
+	SELECT CHANNEL_NAME, FILTER_NAME, FILTER_RULE, ACTIVE_SINCE
+	  FROM performance_schema.replication_applier_filters
+	`
+
+const perfReplicationApplierGlobalFiltersQuery = `
+	SELECT FILTER_NAME, FILTER_RULE, ACTIVE_SINCE
+	  FROM performance_schema.replication_applier_global_filters
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationFilterDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_filter"),
+		"A replication filter of a channel, always 1.",
+		[]string{"channel_name", "filter_name", "filter_rule"}, nil,
+	)
+	performanceSchemaReplicationFilterActiveSinceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_filter_active_since_timestamp_seconds"),
+		"When the replication filter of a channel was configured.",
+		[]string{"channel_name", "filter_name", "filter_rule"}, nil,
+	)
+	performanceSchemaReplicationGlobalFilterDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_global_filter"),
+		"A global replication filter, copied to new channels, always 1.",
+		[]string{"filter_name", "filter_rule"}, nil,
+	)
+	performanceSchemaReplicationGlobalFilterActiveSinceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_global_filter_active_since_timestamp_seconds"),
+		"When the global replication filter was configured.",
+		[]string{"filter_name", "filter_rule"}, nil,
+	)
+)
+
+// ScrapeReplicationFilters collects the replication filters, which silently
+// skip the changes they match.
+type ScrapeReplicationFilters struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeReplicationFilters) Name() string {
+	return performanceSchema + ".replication_applier_filters"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeReplicationFilters) Help() string {
+	return "Collect the replication filters from performance_schema.replication_applier_filters and replication_applier_global_filters"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeReplicationFilters) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeReplicationFilters) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeReplicationFilters) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	globalRows, err := db.QueryContext(ctx, perfReplicationApplierGlobalFiltersQuery)
+	if err != nil {
+		// The filter tables were added in 8.0.11.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "Replication filter tables are not available", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer globalRows.Close()
+
+	var channelName, filterName, filterRule, activeSince string
+	for globalRows.Next() {
+		if err := globalRows.Scan(&filterName, &filterRule, &activeSince); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationGlobalFilterDesc, prometheus.GaugeValue, 1, filterName, filterRule,
+		)
+		if since, ok := parseApplierTimestamp(activeSince); ok {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationGlobalFilterActiveSinceDesc, prometheus.GaugeValue, since, filterName, filterRule,
+			)
+		}
+	}
+	if err := globalRows.Err(); err != nil {
+		return err
+	}
+
+	channelRows, err := db.QueryContext(ctx, perfReplicationApplierFiltersQuery)
+	if err != nil {
+		return err
+	}
+	defer channelRows.Close()
+
+	for channelRows.Next() {
+		if err := channelRows.Scan(&channelName, &filterName, &filterRule, &activeSince); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationFilterDesc, prometheus.GaugeValue, 1, channelName, filterName, filterRule,
+		)
+		if since, ok := parseApplierTimestamp(activeSince); ok {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationFilterActiveSinceDesc, prometheus.GaugeValue, since, channelName, filterName, filterRule,
+			)
+		}
+	}
+	return channelRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeReplicationFilters{}
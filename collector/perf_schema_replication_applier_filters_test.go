@@ -0,0 +1,88 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeReplicationFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierGlobalFiltersQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"FILTER_NAME", "FILTER_RULE", "ACTIVE_SINCE"}).
+			AddRow("REPLICATE_DO_DB", "shop", "2023-05-10 08:00:00.000000"))
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierFiltersQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"CHANNEL_NAME", "FILTER_NAME", "FILTER_RULE", "ACTIVE_SINCE"}).
+			AddRow("", "REPLICATE_DO_DB", "shop", "2023-05-10 08:00:00.000000").
+			AddRow("", "REPLICATE_IGNORE_TABLE", "shop.sessions", "2023-05-11 09:30:00.500000"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeReplicationFilters{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"filter_name": "REPLICATE_DO_DB", "filter_rule": "shop"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"filter_name": "REPLICATE_DO_DB", "filter_rule": "shop"}, value: 1683705600, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "filter_name": "REPLICATE_DO_DB", "filter_rule": "shop"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "filter_name": "REPLICATE_DO_DB", "filter_rule": "shop"}, value: 1683705600, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "filter_name": "REPLICATE_IGNORE_TABLE", "filter_rule": "shop.sessions"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "filter_name": "REPLICATE_IGNORE_TABLE", "filter_rule": "shop.sessions"}, value: 1683797400.5, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeReplicationFiltersMissingTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierGlobalFiltersQuery)).
+		WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'performance_schema.replication_applier_global_filters' doesn't exist"})
+
+	metrics, err := CollectOnce(context.Background(), ScrapeReplicationFilters{}, db, log.NewNopLogger())
+	convey.Convey("Servers without the filter tables are skipped", t, func() {
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
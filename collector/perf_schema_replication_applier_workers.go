@@ -0,0 +1,210 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.replication_applier_status_by_coordinator` and
+// `performance_schema.replication_applier_status_by_worker`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfReplicationApplierCoordinatorQuery = `
+	SELECT
+		CHANNEL_NAME,
+		SERVICE_STATE,
+		LAST_PROCESSED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP,
+		LAST_PROCESSED_TRANSACTION_END_BUFFER_TIMESTAMP
+	FROM performance_schema.replication_applier_status_by_coordinator
+	`
+
+const perfReplicationApplierWorkersQuery = `
+	SELECT
+		CHANNEL_NAME,
+		WORKER_ID,
+		SERVICE_STATE,
+		LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP,
+		LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP,
+		LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP
+	FROM performance_schema.replication_applier_status_by_worker
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationApplierCoordinatorRunningDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_running"),
+		"Whether the coordinator thread of the channel is running (1) or not (0).",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierCoordinatorLastProcessedTransactionEndBufferDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_last_processed_transaction_end_buffer_timestamp_seconds"),
+		"When the coordinator finished writing the last processed transaction to a worker's buffer.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierCoordinatorLastProcessedTransactionLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_coordinator_last_processed_transaction_lag_seconds"),
+		"Seconds between the original commit of the last processed transaction and its hand-off to a worker.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationApplierWorkerRunningDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_worker_running"),
+		"Whether the applier worker thread is running (1) or not (0).",
+		[]string{"channel_name", "worker_id"}, nil,
+	)
+	performanceSchemaReplicationApplierWorkerLastAppliedTransactionEndApplyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_worker_last_applied_transaction_end_apply_timestamp_seconds"),
+		"When the worker finished applying its last applied transaction.",
+		[]string{"channel_name", "worker_id"}, nil,
+	)
+	performanceSchemaReplicationApplierWorkerLastAppliedTransactionApplyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_worker_last_applied_transaction_apply_seconds"),
+		"Seconds the worker spent applying its last applied transaction.",
+		[]string{"channel_name", "worker_id"}, nil,
+	)
+	performanceSchemaReplicationApplierWorkerLastAppliedTransactionLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_applier_worker_last_applied_transaction_lag_seconds"),
+		"Seconds between the original commit of the worker's last applied transaction and the end of its apply.",
+		[]string{"channel_name", "worker_id"}, nil,
+	)
+)
+
+// parseApplierTimestamp parses a replication applier timestamp as seconds
+// since the epoch. It returns false for unset ('0000-00-00 ...') timestamps.
+func parseApplierTimestamp(s string) (float64, bool) {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil || t.IsZero() {
+		return 0, false
+	}
+	return float64(t.UnixNano()) / 1e9, true
+}
+
+// ScrapeReplicaWorkers collects the progress of the coordinator and workers
+// of multi-threaded replicas.
+type ScrapeReplicaWorkers struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeReplicaWorkers) Name() string {
+	return performanceSchema + ".replication_applier_workers"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeReplicaWorkers) Help() string {
+	return "Collect per worker parallel apply metrics from performance_schema.replication_applier_status_by_coordinator and by_worker"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeReplicaWorkers) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeReplicaWorkers) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeReplicaWorkers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	coordinatorRows, err := db.QueryContext(ctx, perfReplicationApplierCoordinatorQuery)
+	if err != nil {
+		// Before 8.0 the tables lack the transaction timestamps, and without
+		// performance_schema they may not exist at all.
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && (mysqlErr.Number == 1054 || mysqlErr.Number == 1146) {
+			level.Debug(logger).Log("msg", "Replication applier tables are not available", "err", err)
+			return nil
+		}
+		return err
+	}
+	defer coordinatorRows.Close()
+
+	var (
+		channelName, workerID, serviceState string
+		originalCommit, startApply, endTime string
+		coordinators                        int
+	)
+	for coordinatorRows.Next() {
+		if err := coordinatorRows.Scan(&channelName, &serviceState, &originalCommit, &endTime); err != nil {
+			return err
+		}
+		coordinators++
+		running, _ := parseBoolMetric(serviceState)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierCoordinatorRunningDesc, prometheus.GaugeValue, running, channelName,
+		)
+		endBuffer, ok := parseApplierTimestamp(endTime)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierCoordinatorLastProcessedTransactionEndBufferDesc, prometheus.GaugeValue, endBuffer, channelName,
+		)
+		if commit, commitOK := parseApplierTimestamp(originalCommit); ok && commitOK {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationApplierCoordinatorLastProcessedTransactionLagDesc, prometheus.GaugeValue, endBuffer-commit, channelName,
+			)
+		}
+	}
+	if err := coordinatorRows.Err(); err != nil {
+		return err
+	}
+	// The coordinator table is empty unless replica_parallel_workers > 0, the
+	// worker table then only describes the single applier thread.
+	if coordinators == 0 {
+		level.Debug(logger).Log("msg", "Parallel replication is not configured")
+		return nil
+	}
+
+	workerRows, err := db.QueryContext(ctx, perfReplicationApplierWorkersQuery)
+	if err != nil {
+		return err
+	}
+	defer workerRows.Close()
+
+	for workerRows.Next() {
+		if err := workerRows.Scan(&channelName, &workerID, &serviceState, &originalCommit, &startApply, &endTime); err != nil {
+			return err
+		}
+		running, _ := parseBoolMetric(serviceState)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierWorkerRunningDesc, prometheus.GaugeValue, running, channelName, workerID,
+		)
+		endApply, ok := parseApplierTimestamp(endTime)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationApplierWorkerLastAppliedTransactionEndApplyDesc, prometheus.GaugeValue, endApply, channelName, workerID,
+		)
+		if !ok {
+			// The worker did not apply a transaction yet.
+			continue
+		}
+		if start, startOK := parseApplierTimestamp(startApply); startOK {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationApplierWorkerLastAppliedTransactionApplyDesc, prometheus.GaugeValue, endApply-start, channelName, workerID,
+			)
+		}
+		if commit, commitOK := parseApplierTimestamp(originalCommit); commitOK {
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationApplierWorkerLastAppliedTransactionLagDesc, prometheus.GaugeValue, endApply-commit, channelName, workerID,
+			)
+		}
+	}
+	return workerRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeReplicaWorkers{}
@@ -0,0 +1,145 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeReplicaWorkers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	timeZero := "0000-00-00 00:00:00.000000"
+	stubTime := time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string {
+		return stubTime.Add(d).Format(timeLayout)
+	}
+
+	coordinatorColumns := []string{
+		"CHANNEL_NAME",
+		"SERVICE_STATE",
+		"LAST_PROCESSED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
+		"LAST_PROCESSED_TRANSACTION_END_BUFFER_TIMESTAMP",
+	}
+	coordinatorRows := sqlmock.NewRows(coordinatorColumns).
+		AddRow("", "ON", at(0), at(time.Second))
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierCoordinatorQuery)).WillReturnRows(coordinatorRows)
+
+	workerColumns := []string{
+		"CHANNEL_NAME",
+		"WORKER_ID",
+		"SERVICE_STATE",
+		"LAST_APPLIED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
+		"LAST_APPLIED_TRANSACTION_START_APPLY_TIMESTAMP",
+		"LAST_APPLIED_TRANSACTION_END_APPLY_TIMESTAMP",
+	}
+	workerRows := sqlmock.NewRows(workerColumns).
+		AddRow("", "1", "ON", at(0), at(time.Second), at(1500*time.Millisecond)).
+		AddRow("", "2", "ON", at(0), at(2*time.Second), at(3*time.Second)).
+		AddRow("", "3", "ON", at(time.Second), at(4*time.Second), at(5*time.Second)).
+		AddRow("", "4", "OFF", timeZero, timeZero, timeZero)
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierWorkersQuery)).WillReturnRows(workerRows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeReplicaWorkers{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	epoch := float64(stubTime.Unix())
+	coordinator := labelMap{"channel_name": ""}
+	worker := func(id string) labelMap {
+		return labelMap{"channel_name": "", "worker_id": id}
+	}
+	metricExpected := []MetricResult{
+		{labels: coordinator, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: coordinator, value: epoch + 1, metricType: dto.MetricType_GAUGE},
+		{labels: coordinator, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("1"), value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("1"), value: epoch + 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: worker("1"), value: 0.5, metricType: dto.MetricType_GAUGE},
+		{labels: worker("1"), value: 1.5, metricType: dto.MetricType_GAUGE},
+		{labels: worker("2"), value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("2"), value: epoch + 3, metricType: dto.MetricType_GAUGE},
+		{labels: worker("2"), value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("2"), value: 3, metricType: dto.MetricType_GAUGE},
+		{labels: worker("3"), value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("3"), value: epoch + 5, metricType: dto.MetricType_GAUGE},
+		{labels: worker("3"), value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: worker("3"), value: 4, metricType: dto.MetricType_GAUGE},
+		{labels: worker("4"), value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: worker("4"), value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeReplicaWorkersNotParallel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"CHANNEL_NAME",
+		"SERVICE_STATE",
+		"LAST_PROCESSED_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP",
+		"LAST_PROCESSED_TRANSACTION_END_BUFFER_TIMESTAMP",
+	}
+	mock.ExpectQuery(sanitizeQuery(perfReplicationApplierCoordinatorQuery)).WillReturnRows(sqlmock.NewRows(columns))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeReplicaWorkers{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("No metrics without parallel replication", t, func() {
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
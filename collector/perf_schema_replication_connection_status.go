@@ -0,0 +1,148 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.replication_connection_status`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfReplicationConnectionStatusQuery = `
+	SELECT
+	    CHANNEL_NAME,
+	    SERVICE_STATE,
+	    COUNT_RECEIVED_HEARTBEATS,
+	    LAST_HEARTBEAT_TIMESTAMP,
+	    LAST_ERROR_NUMBER,
+	    LAST_ERROR_TIMESTAMP
+	  FROM performance_schema.replication_connection_status
+	`
+
+// replicationConnectionServiceStates are the values of SERVICE_STATE.
+var replicationConnectionServiceStates = []string{"ON", "CONNECTING", "OFF"}
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationConnectionServiceStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_connection_service_state"),
+		"Whether the I/O thread of the channel is in the given state (ON, CONNECTING or OFF).",
+		[]string{"channel_name", "state"}, nil,
+	)
+	performanceSchemaReplicationConnectionReceivedHeartbeatsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_connection_received_heartbeats_total"),
+		"The total number of heartbeat signals that the replica received since it was last restarted or reset.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationConnectionLastHeartbeatTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_connection_last_heartbeat_timestamp_seconds"),
+		"A timestamp that shows when the most recent heartbeat signal was received, 0 if there was none.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationConnectionLastErrorNumberDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_connection_last_error_number"),
+		"The error number of the most recent error that caused the I/O thread to stop, 0 if there was none.",
+		[]string{"channel_name"}, nil,
+	)
+	performanceSchemaReplicationConnectionLastErrorTimestampDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_connection_last_error_timestamp_seconds"),
+		"A timestamp that shows when the most recent I/O error took place, 0 if there was none.",
+		[]string{"channel_name"}, nil,
+	)
+)
+
+// ScrapePerfReplicationConnectionStatus collects from `performance_schema.replication_connection_status`.
+type ScrapePerfReplicationConnectionStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfReplicationConnectionStatus) Name() string {
+	return performanceSchema + ".replication_connection_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfReplicationConnectionStatus) Help() string {
+	return "Collect metrics from performance_schema.replication_connection_status"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfReplicationConnectionStatus) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapePerfReplicationConnectionStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfReplicationConnectionStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	perfReplicationConnectionStatusRows, err := db.QueryContext(ctx, perfReplicationConnectionStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer perfReplicationConnectionStatusRows.Close()
+
+	var (
+		channelName, serviceState                  string
+		lastHeartbeatTimestamp, lastErrorTimestamp string
+		receivedHeartbeats                         uint64
+		lastErrorNumber                            uint64
+	)
+	for perfReplicationConnectionStatusRows.Next() {
+		if err := perfReplicationConnectionStatusRows.Scan(
+			&channelName, &serviceState, &receivedHeartbeats, &lastHeartbeatTimestamp, &lastErrorNumber, &lastErrorTimestamp,
+		); err != nil {
+			return err
+		}
+
+		// A zero timestamp means that no error has been recorded.
+		lastErrorSeconds := 0.0
+		if t, err := time.Parse(timeLayout, lastErrorTimestamp); err == nil && !t.IsZero() {
+			lastErrorSeconds = float64(t.UnixNano()) / 1e9
+		}
+		lastHeartbeatSeconds, _ := parseApplierTimestamp(lastHeartbeatTimestamp)
+
+		for _, state := range replicationConnectionServiceStates {
+			value := 0.0
+			if strings.EqualFold(serviceState, state) {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(
+				performanceSchemaReplicationConnectionServiceStateDesc, prometheus.GaugeValue, value, channelName, state,
+			)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationConnectionReceivedHeartbeatsDesc, prometheus.CounterValue, float64(receivedHeartbeats), channelName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationConnectionLastHeartbeatTimestampDesc, prometheus.GaugeValue, lastHeartbeatSeconds, channelName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationConnectionLastErrorNumberDesc, prometheus.GaugeValue, float64(lastErrorNumber), channelName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaReplicationConnectionLastErrorTimestampDesc, prometheus.GaugeValue, lastErrorSeconds, channelName,
+		)
+	}
+	return perfReplicationConnectionStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapePerfReplicationConnectionStatus{}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfReplicationConnectionStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"CHANNEL_NAME",
+		"SERVICE_STATE",
+		"COUNT_RECEIVED_HEARTBEATS",
+		"LAST_HEARTBEAT_TIMESTAMP",
+		"LAST_ERROR_NUMBER",
+		"LAST_ERROR_TIMESTAMP",
+	}
+	rows := sqlmock.NewRows(columns).
+		AddRow("", "ON", 1024, "2019-03-14 00:00:10.000000", 0, "0000-00-00 00:00:00.000000").
+		AddRow("source_b", "CONNECTING", 12, "0000-00-00 00:00:00.000000", 2003, "2019-03-14 00:00:00.001000")
+	mock.ExpectQuery(sanitizeQuery(perfReplicationConnectionStatusQuery)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfReplicationConnectionStatus{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"channel_name": "", "state": "ON"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "state": "CONNECTING"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "", "state": "OFF"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 1024, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"channel_name": ""}, value: 1.55252161e+9, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": ""}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b", "state": "ON"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b", "state": "CONNECTING"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b", "state": "OFF"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b"}, value: 12, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"channel_name": "source_b"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b"}, value: 2003, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"channel_name": "source_b"}, value: 1.552521600001e+9, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
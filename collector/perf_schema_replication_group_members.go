@@ -16,15 +16,47 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
+
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
-	"strings"
 )
 
 const perfReplicationGroupMembersQuery = `
   SELECT * FROM performance_schema.replication_group_members
 	`
 
+// replicationGroupMemberStates are the MEMBER_STATE values exported as
+// replication_group_member_state.
+var replicationGroupMemberStates = []string{"ONLINE", "RECOVERING", "ERROR", "OFFLINE", "UNREACHABLE"}
+
+// Metric descriptors.
+var (
+	performanceSchemaReplicationGroupMembersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_members"),
+		"Number of members of the replication group as seen by this server.",
+		nil, nil,
+	)
+	performanceSchemaReplicationGroupMemberStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_state"),
+		"Whether the replication group member is in the state (1) or not (0).",
+		[]string{"member_host", "member_port", "state"}, nil,
+	)
+	performanceSchemaReplicationGroupPrimaryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "replication_group_primary_info"),
+		"The primary member of the replication group, available from MySQL 8.0.",
+		[]string{"member_id", "member_host", "member_port"}, nil,
+	)
+)
+
+// replicationGroupMember is the identity and state of a group member.
+type replicationGroupMember struct {
+	id, host, port, state, role string
+}
+
 // ScrapeReplicationGroupMembers collects from `performance_schema.replication_group_members`.
 type ScrapePerfReplicationGroupMembers struct{}
 
@@ -47,6 +79,11 @@ func (ScrapePerfReplicationGroupMembers) Version() float64 {
 func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
 	perfReplicationGroupMembersRows, err := db.QueryContext(ctx, perfReplicationGroupMembersQuery)
 	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1146 {
+			level.Debug(logger).Log("msg", "Replication group members table is not available", "err", err)
+			return nil
+		}
 		return err
 	}
 	defer perfReplicationGroupMembersRows.Close()
@@ -61,6 +98,7 @@ func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, db *sql.DB,
 		scanArgs[i] = &sql.RawBytes{}
 	}
 
+	var members []replicationGroupMember
 	for perfReplicationGroupMembersRows.Next() {
 		if err := perfReplicationGroupMembersRows.Scan(scanArgs...); err != nil {
 			return err
@@ -68,10 +106,24 @@ func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, db *sql.DB,
 
 		var labelNames = make([]string, len(columnNames))
 		var values = make([]string, len(columnNames))
+		var member replicationGroupMember
 		for i, columnName := range columnNames {
 			labelNames[i] = strings.ToLower(columnName)
 			values[i] = string(*scanArgs[i].(*sql.RawBytes))
+			switch labelNames[i] {
+			case "member_id":
+				member.id = values[i]
+			case "member_host":
+				member.host = values[i]
+			case "member_port":
+				member.port = values[i]
+			case "member_state":
+				member.state = values[i]
+			case "member_role":
+				member.role = values[i]
+			}
 		}
+		members = append(members, member)
 
 		var performanceSchemaReplicationGroupMembersMemberDesc = prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, performanceSchema, "replication_group_member_info"),
@@ -84,8 +136,34 @@ func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, db *sql.DB,
 		ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupMembersMemberDesc,
 			prometheus.GaugeValue, 1, values...)
 	}
-	return nil
+	if err := perfReplicationGroupMembersRows.Err(); err != nil {
+		return err
+	}
+	// The table is empty unless the group_replication plugin is installed.
+	// Before the member joins a group it only lists itself as OFFLINE.
+	if len(members) == 0 {
+		return nil
+	}
 
+	ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupMembersDesc,
+		prometheus.GaugeValue, float64(len(members)))
+	for _, member := range members {
+		for _, state := range replicationGroupMemberStates {
+			var value float64
+			if member.state == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupMemberStateDesc,
+				prometheus.GaugeValue, value, member.host, member.port, state)
+		}
+	}
+	for _, member := range members {
+		if member.role == "PRIMARY" {
+			ch <- prometheus.MustNewConstMetric(performanceSchemaReplicationGroupPrimaryDesc,
+				prometheus.GaugeValue, 1, member.id, member.host, member.port)
+		}
+	}
+	return nil
 }
 
 // check interface
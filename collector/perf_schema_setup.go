@@ -0,0 +1,133 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.setup_consumers` and `performance_schema.setup_instruments`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfSetupEnabledQuery = `SELECT @@performance_schema`
+
+const perfSetupConsumersQuery = `
+	SELECT NAME, ENABLED
+	  FROM performance_schema.setup_consumers
+	`
+
+const perfSetupInstrumentsQuery = `
+	SELECT
+		SUBSTRING_INDEX(NAME, '/', 1) AS CLASS, COALESCE(SUM(ENABLED = 'YES'), 0)
+	  FROM performance_schema.setup_instruments
+	  GROUP BY CLASS
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaSetupConsumerEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_consumer_enabled"),
+		"Whether the consumer is enabled in performance_schema.setup_consumers.",
+		[]string{"name"}, nil,
+	)
+	performanceSchemaSetupInstrumentsEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "setup_instruments_enabled"),
+		"The number of enabled instruments in performance_schema.setup_instruments, by class.",
+		[]string{"class"}, nil,
+	)
+)
+
+// ScrapePerfSchemaSetup collects which performance_schema consumers and
+// instruments are enabled, which explains empty data of other perf_schema
+// scrapers.
+type ScrapePerfSchemaSetup struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapePerfSchemaSetup) Name() string {
+	return performanceSchema + ".setup"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapePerfSchemaSetup) Help() string {
+	return "Collect the enabled consumers and instruments from performance_schema.setup_consumers and setup_instruments"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapePerfSchemaSetup) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapePerfSchemaSetup) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapePerfSchemaSetup) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var perfSchemaOn bool
+	if err := db.QueryRowContext(ctx, perfSetupEnabledQuery).Scan(&perfSchemaOn); err != nil {
+		return wrapDriverError(err)
+	}
+	if !perfSchemaOn {
+		level.Debug(logger).Log("msg", "performance_schema is disabled")
+		return nil
+	}
+
+	consumerRows, err := db.QueryContext(ctx, perfSetupConsumersQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer consumerRows.Close()
+
+	var name, enabled string
+	for consumerRows.Next() {
+		if err := consumerRows.Scan(&name, &enabled); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		value, _ := parseBoolMetric(enabled)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupConsumerEnabledDesc, prometheus.GaugeValue, value, name,
+		)
+	}
+	if err := consumerRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	instrumentRows, err := db.QueryContext(ctx, perfSetupInstrumentsQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer instrumentRows.Close()
+
+	var (
+		class string
+		count uint64
+	)
+	for instrumentRows.Next() {
+		if err := instrumentRows.Scan(&class, &count); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaSetupInstrumentsEnabledDesc, prometheus.GaugeValue, float64(count), class,
+		)
+	}
+	return wrapDriverError(instrumentRows.Err())
+}
+
+// check interface
+var _ Scraper = ScrapePerfSchemaSetup{}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfSchemaSetup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSetupEnabledQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow("1"))
+	consumerRows := sqlmock.NewRows([]string{"NAME", "ENABLED"}).
+		AddRow("events_statements_current", "YES").
+		AddRow("events_statements_history_long", "NO").
+		AddRow("global_instrumentation", "YES")
+	mock.ExpectQuery(sanitizeQuery(perfSetupConsumersQuery)).WillReturnRows(consumerRows)
+	instrumentRows := sqlmock.NewRows([]string{"CLASS", "COALESCE(SUM(ENABLED = 'YES'), 0)"}).
+		AddRow("memory", "0").
+		AddRow("statement", "210")
+	mock.ExpectQuery(sanitizeQuery(perfSetupInstrumentsQuery)).WillReturnRows(instrumentRows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapePerfSchemaSetup{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	var got []MetricResult
+	for _, m := range metrics {
+		got = append(got, readMetric(m))
+	}
+	expected := []MetricResult{
+		{labels: labelMap{"name": "events_statements_current"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"name": "events_statements_history_long"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"name": "global_instrumentation"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"class": "memory"}, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"class": "statement"}, value: 210, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Enabled consumers and instruments are collected", t, func() {
+		convey.So(got, convey.ShouldResemble, expected)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfSchemaSetupDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSetupEnabledQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow("0"))
+
+	metrics, err := CollectOnce(context.Background(), ScrapePerfSchemaSetup{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	if len(metrics) != 0 {
+		t.Errorf("expected no metrics with performance_schema disabled, got %d", len(metrics))
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
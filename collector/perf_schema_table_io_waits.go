@@ -18,8 +18,11 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -32,6 +35,18 @@ const perfTableIOWaitsQuery = `
 	  WHERE OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema')
 	`
 
+// Tunable flags.
+var (
+	perfTableIOWaitsDatabases = kingpin.Flag(
+		"collect.perf_schema.tableiowaits.databases",
+		"The list of databases to collect table I/O waits for, or '*' for all",
+	).Default("*").String()
+	perfTableIOWaitsMinTime = kingpin.Flag(
+		"collect.perf_schema.tableiowaits.min_time",
+		"Skip tables whose total I/O wait time is below this duration",
+	).Default("0s").Duration()
+)
+
 // Metric descriptors.
 var (
 	performanceSchemaTableWaitsDesc = prometheus.NewDesc(
@@ -66,6 +81,25 @@ func (ScrapePerfTableIOWaits) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfTableIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var enabled bool
+	if err := db.QueryRowContext(ctx, perfSchemaEnabledQuery).Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		level.Debug(logger).Log("msg", "performance_schema is disabled, skipping table I/O waits")
+		return nil
+	}
+
+	var databases map[string]bool
+	if *perfTableIOWaitsDatabases != "*" {
+		databases = map[string]bool{}
+		for _, database := range strings.Split(*perfTableIOWaitsDatabases, ",") {
+			databases[database] = true
+		}
+	}
+	// Timers here are returned in picoseconds.
+	minTime := perfTableIOWaitsMinTime.Seconds() * picoSeconds
+
 	perfSchemaTableWaitsRows, err := db.QueryContext(ctx, perfTableIOWaitsQuery)
 	if err != nil {
 		return err
@@ -85,6 +119,12 @@ func (ScrapePerfTableIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 		); err != nil {
 			return err
 		}
+		if databases != nil && !databases[objectSchema] {
+			continue
+		}
+		if float64(timeFetch+timeInsert+timeUpdate+timeDelete) < minTime {
+			continue
+		}
 		ch <- prometheus.MustNewConstMetric(
 			performanceSchemaTableWaitsDesc, prometheus.CounterValue, float64(countFetch),
 			objectSchema, objectName, "fetch",
@@ -118,7 +158,7 @@ func (ScrapePerfTableIOWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<-
 			objectSchema, objectName, "delete",
 		)
 	}
-	return nil
+	return perfSchemaTableWaitsRows.Err()
 }
 
 // check interface
@@ -0,0 +1,112 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfTableIOWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.tableiowaits.databases=shop,billing",
+		"--collect.perf_schema.tableiowaits.min_time=1s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+	columns := []string{"OBJECT_SCHEMA", "OBJECT_NAME", "COUNT_FETCH", "COUNT_INSERT", "COUNT_UPDATE", "COUNT_DELETE", "SUM_TIMER_FETCH", "SUM_TIMER_INSERT", "SUM_TIMER_UPDATE", "SUM_TIMER_DELETE"}
+	rows := sqlmock.NewRows(columns).
+		// Note, timers are in picoseconds.
+		AddRow("shop", "orders", "1000", "200", "50", "5", "3000000000000", "2000000000000", "500000000000", "100000000000").
+		// Below min_time.
+		AddRow("shop", "countries", "10", "0", "0", "0", "1000000", "0", "0", "0").
+		AddRow("billing", "invoices", "400", "100", "20", "0", "1000000000000", "500000000000", "250000000000", "0").
+		// Not in databases.
+		AddRow("staging", "orders", "1000", "200", "50", "5", "3000000000000", "2000000000000", "500000000000", "100000000000")
+	mock.ExpectQuery(sanitizeQuery(perfTableIOWaitsQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapePerfTableIOWaits{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "fetch"}, value: 1000, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "insert"}, value: 200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "update"}, value: 50, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "delete"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "fetch"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "insert"}, value: 2, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "update"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "shop", "name": "orders", "operation": "delete"}, value: 0.1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "fetch"}, value: 400, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "insert"}, value: 100, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "update"}, value: 20, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "delete"}, value: 0, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "fetch"}, value: 1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "insert"}, value: 0.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "update"}, value: 0.25, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"schema": "billing", "name": "invoices", "operation": "delete"}, value: 0, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfTableIOWaitsDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(0))
+
+	metrics, err := CollectOnce(context.Background(), ScrapePerfTableIOWaits{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	convey.Convey("No metrics without performance_schema", t, func() {
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -18,8 +18,11 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"strings"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -51,8 +54,37 @@ const perfTableLockWaitsQuery = `
 	  WHERE OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema')
 	`
 
+const perfMetadataLocksQuery = `
+	SELECT
+	    OBJECT_SCHEMA,
+	    OBJECT_NAME,
+	    LOCK_STATUS,
+	    COUNT(*)
+	  FROM performance_schema.metadata_locks
+	  WHERE OBJECT_TYPE = 'TABLE'
+	    AND OBJECT_SCHEMA NOT IN ('mysql', 'performance_schema', 'information_schema')
+	  GROUP BY OBJECT_SCHEMA, OBJECT_NAME, LOCK_STATUS
+	`
+
+// Tunable flags.
+var (
+	perfTableLockWaitsDatabases = kingpin.Flag(
+		"collect.perf_schema.tablelocks.databases",
+		"The list of databases to collect table lock waits for, or '*' for all",
+	).Default("*").String()
+	perfTableLockWaitsMetadataLocks = kingpin.Flag(
+		"collect.perf_schema.tablelocks.metadata_locks",
+		"Also collect the number of table metadata locks by status from performance_schema.metadata_locks (MySQL 5.7+)",
+	).Bool()
+)
+
 // Metric descriptors.
 var (
+	performanceSchemaMetadataLocksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "metadata_locks"),
+		"The number of metadata locks held or requested on each table, by lock status.",
+		[]string{"schema", "name", "status"}, nil,
+	)
 	performanceSchemaSQLTableLockWaitsDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(namespace, performanceSchema, "sql_lock_waits_total"),
 		"The total number of SQL lock wait events for each table and operation.",
@@ -95,6 +127,23 @@ func (ScrapePerfTableLockWaits) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var enabled bool
+	if err := db.QueryRowContext(ctx, perfSchemaEnabledQuery).Scan(&enabled); err != nil {
+		return err
+	}
+	if !enabled {
+		level.Debug(logger).Log("msg", "performance_schema is disabled, skipping table lock waits")
+		return nil
+	}
+
+	var databases map[string]bool
+	if *perfTableLockWaitsDatabases != "*" {
+		databases = map[string]bool{}
+		for _, database := range strings.Split(*perfTableLockWaitsDatabases, ",") {
+			databases[database] = true
+		}
+	}
+
 	perfSchemaTableLockWaitsRows, err := db.QueryContext(ctx, perfTableLockWaitsQuery)
 	if err != nil {
 		return err
@@ -153,6 +202,9 @@ func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<
 		); err != nil {
 			return err
 		}
+		if databases != nil && !databases[objectSchema] {
+			continue
+		}
 		ch <- prometheus.MustNewConstMetric(
 			performanceSchemaSQLTableLockWaitsDesc, prometheus.CounterValue, float64(countReadNormal),
 			objectSchema, objectName, "read_normal",
@@ -234,7 +286,36 @@ func (ScrapePerfTableLockWaits) Scrape(ctx context.Context, db *sql.DB, ch chan<
 			objectSchema, objectName, "write",
 		)
 	}
-	return nil
+	if err := perfSchemaTableLockWaitsRows.Err(); err != nil {
+		return err
+	}
+
+	if !*perfTableLockWaitsMetadataLocks {
+		return nil
+	}
+	metadataLocksRows, err := db.QueryContext(ctx, perfMetadataLocksQuery)
+	if err != nil {
+		return err
+	}
+	defer metadataLocksRows.Close()
+
+	var (
+		lockStatus string
+		count      uint64
+	)
+	for metadataLocksRows.Next() {
+		if err := metadataLocksRows.Scan(&objectSchema, &objectName, &lockStatus, &count); err != nil {
+			return err
+		}
+		if databases != nil && !databases[objectSchema] {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaMetadataLocksDesc, prometheus.GaugeValue, float64(count),
+			objectSchema, objectName, strings.ToLower(lockStatus),
+		)
+	}
+	return metadataLocksRows.Err()
 }
 
 // check interface
@@ -0,0 +1,134 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapePerfTableLockWaits(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.tablelocks.databases=app",
+		"--collect.perf_schema.tablelocks.metadata_locks",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{
+		"--collect.perf_schema.tablelocks.databases=*",
+		"--no-collect.perf_schema.tablelocks.metadata_locks",
+	})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{
+		"OBJECT_SCHEMA", "OBJECT_NAME",
+		"COUNT_READ_NORMAL", "COUNT_READ_WITH_SHARED_LOCKS", "COUNT_READ_HIGH_PRIORITY", "COUNT_READ_NO_INSERT", "COUNT_READ_EXTERNAL",
+		"COUNT_WRITE_ALLOW_WRITE", "COUNT_WRITE_CONCURRENT_INSERT", "COUNT_WRITE_LOW_PRIORITY", "COUNT_WRITE_NORMAL", "COUNT_WRITE_EXTERNAL",
+		"SUM_TIMER_READ_NORMAL", "SUM_TIMER_READ_WITH_SHARED_LOCKS", "SUM_TIMER_READ_HIGH_PRIORITY", "SUM_TIMER_READ_NO_INSERT", "SUM_TIMER_READ_EXTERNAL",
+		"SUM_TIMER_WRITE_ALLOW_WRITE", "SUM_TIMER_WRITE_CONCURRENT_INSERT", "SUM_TIMER_WRITE_LOW_PRIORITY", "SUM_TIMER_WRITE_NORMAL", "SUM_TIMER_WRITE_EXTERNAL",
+	}
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(perfTableLockWaitsQuery)).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("app", "orders", 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, "1000000000000", "2000000000000", "3000000000000", "4000000000000", "5000000000000", "6000000000000", "7000000000000", "8000000000000", "9000000000000", "25000000000000").
+		AddRow("other", "users", 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1))
+	mock.ExpectQuery(sanitizeQuery(perfMetadataLocksQuery)).WillReturnRows(sqlmock.NewRows([]string{"OBJECT_SCHEMA", "OBJECT_NAME", "LOCK_STATUS", "COUNT(*)"}).
+		AddRow("app", "orders", "GRANTED", 3).
+		AddRow("app", "orders", "PENDING", 2).
+		AddRow("other", "users", "PENDING", 1))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfTableLockWaits{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	var (
+		schemas       = map[string]bool{}
+		metadataLocks []MetricResult
+		writeNormal   []MetricResult
+	)
+	for m := range ch {
+		got := readMetric(m)
+		schemas[got.labels["schema"]] = true
+		switch {
+		case m.Desc() == performanceSchemaMetadataLocksDesc:
+			metadataLocks = append(metadataLocks, got)
+		case got.labels["operation"] == "write_normal":
+			writeNormal = append(writeNormal, got)
+		}
+	}
+
+	convey.Convey("Only the included databases are collected", t, func() {
+		convey.So(schemas, convey.ShouldResemble, map[string]bool{"app": true})
+		convey.So(writeNormal, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"schema": "app", "name": "orders", "operation": "write_normal"}, value: 9, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{"schema": "app", "name": "orders", "operation": "write_normal"}, value: 9, metricType: dto.MetricType_COUNTER},
+		})
+		convey.So(metadataLocks, convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"schema": "app", "name": "orders", "status": "granted"}, value: 3, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"schema": "app", "name": "orders", "status": "pending"}, value: 2, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapePerfTableLockWaitsDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(0))
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapePerfTableLockWaits{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	convey.Convey("Nothing is collected when performance_schema is off", t, func() {
+		var metrics int
+		for range ch {
+			metrics++
+		}
+		convey.So(metrics, convey.ShouldEqual, 0)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
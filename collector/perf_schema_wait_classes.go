@@ -0,0 +1,144 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `performance_schema.events_waits_summary_global_by_event_name` by wait class.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const perfWaitClassesQuery = `
+	SELECT EVENT_NAME, COUNT_STAR, SUM_TIMER_WAIT
+	  FROM performance_schema.events_waits_summary_global_by_event_name
+	  WHERE COUNT_STAR > 0
+	`
+
+// Metric descriptors.
+var (
+	performanceSchemaWaitClassEventsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "wait_class_events_total"),
+		"The total events waits by wait class.",
+		[]string{"class"}, nil,
+	)
+	performanceSchemaWaitClassTimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, performanceSchema, "wait_class_seconds_total"),
+		"The total seconds of events waits by wait class.",
+		[]string{"class"}, nil,
+	)
+)
+
+// waitClass returns the top-level class of a wait event, e.g. io for
+// wait/io/file/innodb/innodb_data_file. Events outside of wait/, such as
+// idle, are their own class.
+func waitClass(eventName string) string {
+	parts := strings.SplitN(eventName, "/", 3)
+	if len(parts) < 2 || parts[0] != "wait" {
+		return parts[0]
+	}
+	return parts[1]
+}
+
+// ScrapeWaitClasses collects the waits of
+// `performance_schema.events_waits_summary_global_by_event_name` summed by
+// wait class, a breakdown of where time is spent without the cardinality of
+// perf_schema.eventswaits.
+type ScrapeWaitClasses struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeWaitClasses) Name() string {
+	return performanceSchema + ".wait_classes"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeWaitClasses) Help() string {
+	return "Collect metrics from performance_schema.events_waits_summary_global_by_event_name by wait class"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeWaitClasses) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeWaitClasses) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeWaitClasses) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var enabled bool
+	if err := db.QueryRowContext(ctx, perfSchemaEnabledQuery).Scan(&enabled); err != nil {
+		return wrapDriverError(err)
+	}
+	if !enabled {
+		level.Debug(logger).Log("msg", "performance_schema is disabled, skipping wait classes")
+		return nil
+	}
+
+	// Timers here are returned in picoseconds.
+	waitRows, err := db.QueryContext(ctx, perfWaitClassesQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer waitRows.Close()
+
+	var (
+		eventName   string
+		count, time uint64
+		counts      = map[string]uint64{}
+		times       = map[string]uint64{}
+	)
+	for waitRows.Next() {
+		if err := waitRows.Scan(&eventName, &count, &time); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		class := waitClass(eventName)
+		counts[class] += count
+		times[class] += time
+	}
+	if err := waitRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	// Waits are only counted by enabled instruments.
+	if len(counts) == 0 {
+		level.Debug(logger).Log("msg", "No wait events are instrumented")
+		return nil
+	}
+
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaWaitClassEventsDesc, prometheus.CounterValue, float64(counts[class]), class,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			performanceSchemaWaitClassTimeDesc, prometheus.CounterValue, float64(times[class])/picoSeconds, class,
+		)
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeWaitClasses{}
@@ -0,0 +1,95 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeWaitClasses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+	columns := []string{"EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT"}
+	rows := sqlmock.NewRows(columns).
+		// Note, timers are in picoseconds.
+		AddRow("wait/io/file/innodb/innodb_data_file", "100", "2000000000000").
+		AddRow("wait/io/table/sql/handler", "50", "500000000000").
+		AddRow("wait/lock/table/sql/handler", "10", "100000000000").
+		AddRow("wait/synch/mutex/innodb/buf_pool_mutex", "1000", "250000000000").
+		AddRow("wait/synch/rwlock/sql/LOCK_grant", "200", "50000000000").
+		AddRow("idle", "5", "3000000000000")
+	mock.ExpectQuery(sanitizeQuery(perfWaitClassesQuery)).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeWaitClasses{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"class": "idle"}, value: 5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "idle"}, value: 3, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "io"}, value: 150, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "io"}, value: 2.5, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "lock"}, value: 10, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "lock"}, value: 0.1, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "synch"}, value: 1200, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"class": "synch"}, value: 0.3, metricType: dto.MetricType_COUNTER},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeWaitClassesNotInstrumented(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(perfSchemaEnabledQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@performance_schema"}).AddRow(1))
+	mock.ExpectQuery(sanitizeQuery(perfWaitClassesQuery)).WillReturnRows(sqlmock.NewRows([]string{"EVENT_NAME", "COUNT_STAR", "SUM_TIMER_WAIT"}))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeWaitClasses{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+	convey.Convey("No metrics without instrumented waits", t, func() {
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+)
+
+const pingQuery = `SELECT 1`
+
+// PingDatabase checks that the server answers queries, independently of the
+// enabled scrapers. Errors are returned as a *ScrapeError.
+func PingDatabase(ctx context.Context, db *sql.DB) error {
+	var one int
+	return wrapDriverError(db.QueryRowContext(ctx, pingQuery).Scan(&one))
+}
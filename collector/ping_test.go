@@ -0,0 +1,51 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestPingDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(pingQuery)).WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	mock.ExpectQuery(sanitizeQuery(pingQuery)).WillReturnError(refused)
+
+	convey.Convey("Ping reports connection errors", t, func() {
+		convey.So(PingDatabase(context.Background(), db), convey.ShouldBeNil)
+
+		err := PingDatabase(context.Background(), db)
+		var scrapeErr *ScrapeError
+		convey.So(errors.As(err, &scrapeErr), convey.ShouldBeTrue)
+		convey.So(scrapeErr.Class, convey.ShouldEqual, ErrConnection)
+		convey.So(errors.Is(err, refused), convey.ShouldBeTrue)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
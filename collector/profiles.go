@@ -0,0 +1,113 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape `SHOW PROFILES`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// profiles is the Metric subsystem we use.
+	profiles = "profiles"
+	// profilingQuery checks whether profiling is enabled for the session.
+	profilingQuery = "SELECT @@profiling"
+	profilesQuery  = "SHOW PROFILES"
+)
+
+// Metric descriptors.
+var (
+	profilesQueryDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, profiles, "query_duration_seconds"),
+		"Duration of the statements listed by SHOW PROFILES.",
+		[]string{"query_id"}, nil,
+	)
+)
+
+// ScrapeProfiles collects from `SHOW PROFILES`.
+type ScrapeProfiles struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeProfiles) Name() string {
+	return profiles
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeProfiles) Help() string {
+	return "Collect query durations from SHOW PROFILES of the exporter connection, requires profiling to be enabled for the exporter session, e.g. with profiling=1 in the DSN"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeProfiles) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeProfiles) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (s ScrapeProfiles) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return s.ScrapeConn(ctx, conn, ch, logger)
+}
+
+// ScrapeConn collects data from a single connection. @@profiling and SHOW
+// PROFILES are session scoped, so both must run on the same connection.
+func (ScrapeProfiles) ScrapeConn(ctx context.Context, conn *sql.Conn, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var profiling bool
+	if err := conn.QueryRowContext(ctx, profilingQuery).Scan(&profiling); err != nil {
+		return err
+	}
+	if !profiling {
+		level.Debug(logger).Log("msg", "Profiling is disabled for the session, skipping SHOW PROFILES")
+		return nil
+	}
+
+	profilesRows, err := conn.QueryContext(ctx, profilesQuery)
+	if err != nil {
+		return err
+	}
+	defer profilesRows.Close()
+
+	var (
+		queryID  string
+		duration float64
+		query    string
+	)
+	for profilesRows.Next() {
+		if err := profilesRows.Scan(&queryID, &duration, &query); err != nil {
+			return err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			profilesQueryDurationDesc, prometheus.GaugeValue, duration, queryID,
+		)
+	}
+	return profilesRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeProfiles{}
+var _ ConnScraper = ScrapeProfiles{}
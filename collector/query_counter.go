@@ -0,0 +1,234 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// queryCollectorKey is the context key of the collector label that statements
+// are counted for.
+type queryCollectorKey struct{}
+
+// withQueryCollector returns a context counting the statements run with it
+// for the collector label.
+func withQueryCollector(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, queryCollectorKey{}, label)
+}
+
+// countQuery counts a statement for the collector and target of ctx, if any.
+func countQuery(ctx context.Context) {
+	if label, ok := ctx.Value(queryCollectorKey{}).(string); ok {
+		targetMetricsOf(scrapeTarget(ctx)).queries.WithLabelValues(label).Inc()
+	}
+}
+
+// validateQueryComment checks that comment is a single plain comment, so
+// that prepending it cannot change the meaning of a query.
+func validateQueryComment(comment string) error {
+	if comment == "" {
+		return nil
+	}
+	if len(comment) < 4 || !strings.HasPrefix(comment, "/*") || !strings.HasSuffix(comment, "*/") ||
+		strings.Contains(comment[2:len(comment)-2], "*/") || strings.HasPrefix(comment, "/*!") {
+		return fmt.Errorf("exporter.query_comment must be a single /* ... */ comment, got %q", comment)
+	}
+	return nil
+}
+
+// decorateQuery prepends exporter.query_comment and the routing hint of
+// exporter.route_to to query. The hint comes first, where ProxySQL looks for
+// annotations.
+func decorateQuery(query string) string {
+	for _, comment := range []string{*exporterQueryComment, routeHint()} {
+		if comment != "" {
+			query = comment + " " + query
+		}
+	}
+	return query
+}
+
+// openCountingDB opens a database whose connections count the statements
+// of collectors in mysql_exporter_queries_total and prepend
+// exporter.query_comment and the hint of exporter.route_to to them. Scrapers use the *sql.DB directly, so
+// statements are counted and decorated at the driver instead.
+func openCountingDB(d driver.Driver, dsn string) (*sql.DB, error) {
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: d}
+	if dc, ok := d.(driver.DriverContext); ok {
+		var err error
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(countingConnector{connector}), nil
+}
+
+// dsnConnector is the connector of drivers not implementing
+// driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type countingConnector struct {
+	driver.Connector
+}
+
+func (c countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{conn}, nil
+}
+
+// countingConn counts and decorates the statements run on a connection. It
+// forwards the optional driver interfaces of the wrapped connection,
+// returning driver.ErrSkip where database/sql then falls back to a default.
+type countingConn struct {
+	driver.Conn
+}
+
+func (c countingConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(decorateQuery(query))
+	if err != nil {
+		return nil, err
+	}
+	return countingStmt{stmt}, nil
+}
+
+func (c countingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	pc, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+	stmt, err := pc.PrepareContext(ctx, decorateQuery(query))
+	if err != nil {
+		return nil, err
+	}
+	return countingStmt{stmt}, nil
+}
+
+func (c countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := q.QueryContext(ctx, decorateQuery(query), args)
+	if err != driver.ErrSkip {
+		countQuery(ctx)
+	}
+	return rows, err
+}
+
+func (c countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := e.ExecContext(ctx, decorateQuery(query), args)
+	if err != driver.ErrSkip {
+		countQuery(ctx)
+	}
+	return result, err
+}
+
+func (c countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c countingConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c countingConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c countingConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c countingConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// countingStmt counts the executions of a prepared statement.
+type countingStmt struct {
+	driver.Stmt
+}
+
+func (s countingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	countQuery(ctx)
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values)
+}
+
+func (s countingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	countQuery(ctx)
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	values, err := namedValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+// namedValues converts args for drivers without context support, which
+// cannot take named args.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestScrapeDBCountsQueries(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("query_counter")
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	db, err := openCountingDB(mockDB.Driver(), "query_counter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	e := New(context.Background(), dsn, []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger())
+	counter := targetMetricsOf(e.key).queries.WithLabelValues("collect.heartbeat")
+	before := testutil.ToFloat64(counter)
+	for i := 1; i <= 2; i++ {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33"))
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).
+			WillReturnRows(sqlmock.NewRows([]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}).AddRow("1487597613.001320", "1487598113.448042", 1))
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := e.scrapeDB(context.Background(), db, ch); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		for range ch {
+		}
+
+		// The version query is not issued by a collector.
+		if got := testutil.ToFloat64(counter) - before; got != float64(i) {
+			t.Fatalf("expected %d heartbeat queries after %d scrapes, got %v", i, i, got)
+		}
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestQueryComment(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+
+	for _, prepared := range []bool{false, true} {
+		var issued []string
+		matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+			issued = append(issued, actualSQL)
+			if !strings.HasSuffix(actualSQL, expectedSQL) {
+				return fmt.Errorf("query %q does not end with %q", actualSQL, expectedSQL)
+			}
+			return nil
+		})
+		dsn := fmt.Sprintf("query_comment_%t", prepared)
+		mockDB, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.QueryMatcherOption(matcher))
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		db, err := openCountingDB(mockDB.Driver(), dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parseStmtCacheFlags(t, prepared)
+		if prepared {
+			mock.ExpectPrepare(stmtCacheHeartbeatQuery).ExpectQuery().WillReturnRows(heartbeatRows())
+		} else {
+			mock.ExpectQuery(stmtCacheHeartbeatQuery).WillReturnRows(heartbeatRows())
+		}
+		if err := scrapeHeartbeatOnce(db); err != nil {
+			t.Fatalf("error calling function on test: %s", err)
+		}
+
+		if len(issued) == 0 {
+			t.Fatalf("prepared=%t: no query was issued", prepared)
+		}
+		for _, query := range issued {
+			if !strings.HasPrefix(query, "/* mysqld_exporter */ SELECT") {
+				t.Errorf("prepared=%t: expected the query comment in %q", prepared, query)
+			}
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+		mockDB.Close()
+	}
+}
+
+func TestValidateQueryComment(t *testing.T) {
+	for comment, valid := range map[string]bool{
+		"":                           true,
+		"/* mysqld_exporter */":      true,
+		"/**/":                       true,
+		"mysqld_exporter":            false,
+		"/* a */ SELECT 1; /* b */":  false,
+		"/*! SET sql_log_bin = 0 */": false,
+		"/* unterminated":            false,
+		"-- mysqld_exporter":         false,
+	} {
+		if err := validateQueryComment(comment); (err == nil) != valid {
+			t.Errorf("validateQueryComment(%q) = %v, expected valid %t", comment, err, valid)
+		}
+	}
+}
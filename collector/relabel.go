@@ -0,0 +1,246 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+// Relabel actions.
+const (
+	RelabelReplace   = "replace"
+	RelabelKeep      = "keep"
+	RelabelDrop      = "drop"
+	RelabelLabelDrop = "labeldrop"
+)
+
+// RelabelConfig is a rule rewriting exported metrics, following the
+// metric_relabel_configs of Prometheus. The metric name is the __name__
+// label.
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,flow,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// relabelFile is the layout of the metrics.relabel_config file.
+type relabelFile struct {
+	MetricRelabelConfigs []*RelabelConfig `yaml:"metric_relabel_configs"`
+}
+
+// relabelConfigs are applied to all exported metrics.
+var relabelConfigs []*RelabelConfig
+
+// SetRelabelConfigs sets the rules applied to all exported metrics, in order.
+// It must be called before an Exporter is registered.
+func SetRelabelConfigs(configs []*RelabelConfig) error {
+	for i, c := range configs {
+		if err := c.init(); err != nil {
+			return fmt.Errorf("relabel config %d: %w", i, err)
+		}
+	}
+	relabelConfigs = configs
+	return nil
+}
+
+// LoadRelabelConfigs reads the metric_relabel_configs of a YAML file.
+func LoadRelabelConfigs(path string) ([]*RelabelConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f relabelFile
+	if err := yaml.UnmarshalStrict(content, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, c := range f.MetricRelabelConfigs {
+		if err := c.init(); err != nil {
+			return nil, fmt.Errorf("%s: relabel config %d: %w", path, i, err)
+		}
+	}
+	return f.MetricRelabelConfigs, nil
+}
+
+// init applies the defaults of Prometheus and checks the rule.
+func (c *RelabelConfig) init() error {
+	if c.Action == "" {
+		c.Action = RelabelReplace
+	}
+	if c.Separator == "" {
+		c.Separator = ";"
+	}
+	if c.Regex == "" {
+		c.Regex = "(.*)"
+	}
+	if c.Replacement == "" && c.Action == RelabelReplace {
+		c.Replacement = "$1"
+	}
+	re, err := regexp.Compile("^(?:" + c.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", c.Regex, err)
+	}
+	c.regex = re
+
+	switch c.Action {
+	case RelabelReplace:
+		if c.TargetLabel != model.MetricNameLabel && !model.LabelName(c.TargetLabel).IsValid() {
+			return fmt.Errorf("invalid target_label %q", c.TargetLabel)
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("%s requires source_labels", c.Action)
+		}
+	case RelabelLabelDrop:
+	default:
+		return fmt.Errorf("unknown action %q", c.Action)
+	}
+	return nil
+}
+
+// relabel applies the rules to labels, which include __name__. It returns
+// false if the metric is dropped.
+func relabel(labels map[string]string, configs []*RelabelConfig) bool {
+	for _, c := range configs {
+		values := make([]string, len(c.SourceLabels))
+		for i, name := range c.SourceLabels {
+			values[i] = labels[name]
+		}
+		value := strings.Join(values, c.Separator)
+
+		switch c.Action {
+		case RelabelKeep:
+			if !c.regex.MatchString(value) {
+				return false
+			}
+		case RelabelDrop:
+			if c.regex.MatchString(value) {
+				return false
+			}
+		case RelabelReplace:
+			match := c.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			replaced := string(c.regex.ExpandString(nil, c.Replacement, value, match))
+			if replaced == "" {
+				delete(labels, c.TargetLabel)
+			} else {
+				labels[c.TargetLabel] = replaced
+			}
+		case RelabelLabelDrop:
+			for name := range labels {
+				if name != model.MetricNameLabel && c.regex.MatchString(name) {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+	return labels[model.MetricNameLabel] != ""
+}
+
+// relabelMetric returns m with the rules applied, or nil if it is dropped.
+func relabelMetric(m prometheus.Metric, configs []*RelabelConfig) (prometheus.Metric, error) {
+	name, help, err := descNameHelp(m.Desc())
+	if err != nil {
+		return nil, err
+	}
+	pb := &dto.Metric{}
+	if err := m.Write(pb); err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string, len(pb.GetLabel())+1)
+	for _, lp := range pb.GetLabel() {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	labels[model.MetricNameLabel] = name
+	if !relabel(labels, configs) {
+		return nil, nil
+	}
+
+	name = labels[model.MetricNameLabel]
+	delete(labels, model.MetricNameLabel)
+	names := make([]string, 0, len(labels))
+	for n := range labels {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	values := make([]string, len(names))
+	for i, n := range names {
+		values[i] = labels[n]
+	}
+	desc := prometheus.NewDesc(name, help, names, nil)
+
+	var relabeled prometheus.Metric
+	switch {
+	case pb.Counter != nil:
+		relabeled, err = prometheus.NewConstMetric(desc, prometheus.CounterValue, pb.Counter.GetValue(), values...)
+	case pb.Gauge != nil:
+		relabeled, err = prometheus.NewConstMetric(desc, prometheus.GaugeValue, pb.Gauge.GetValue(), values...)
+	case pb.Histogram != nil:
+		buckets := make(map[float64]uint64, len(pb.Histogram.GetBucket()))
+		for _, b := range pb.Histogram.GetBucket() {
+			buckets[b.GetUpperBound()] = b.GetCumulativeCount()
+		}
+		relabeled, err = prometheus.NewConstHistogram(desc, pb.Histogram.GetSampleCount(), pb.Histogram.GetSampleSum(), buckets, values...)
+		if err == nil && pb.Histogram.Schema != nil {
+			relabeled = withNativeHistogram(relabeled, pb.Histogram)
+		}
+	case pb.Summary != nil:
+		quantiles := make(map[float64]float64, len(pb.Summary.GetQuantile()))
+		for _, q := range pb.Summary.GetQuantile() {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		relabeled, err = prometheus.NewConstSummary(desc, pb.Summary.GetSampleCount(), pb.Summary.GetSampleSum(), quantiles, values...)
+	default:
+		relabeled, err = prometheus.NewConstMetric(desc, prometheus.UntypedValue, pb.Untyped.GetValue(), values...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pb.TimestampMs != nil {
+		relabeled = prometheus.NewMetricWithTimestamp(time.UnixMilli(pb.GetTimestampMs()), relabeled)
+	}
+	return relabeled, nil
+}
+
+// relabelMetrics reads metrics from in until it is closed and sends them to
+// out with the rules applied. Metrics that cannot be relabeled are sent as
+// invalid metrics.
+func relabelMetrics(in <-chan prometheus.Metric, out chan<- prometheus.Metric, configs []*RelabelConfig) {
+	for m := range in {
+		relabeled, err := relabelMetric(m, configs)
+		if err != nil {
+			out <- prometheus.NewInvalidMetric(m.Desc(), err)
+			continue
+		}
+		if relabeled != nil {
+			out <- relabeled
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// relabeledHeartbeat returns the heartbeat metrics of two servers with the
+// rules applied, by metric name.
+func relabeledHeartbeat(t *testing.T, configs []*RelabelConfig) map[string][]MetricResult {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("1487597613.001320", "1487598113.448042", 1).
+		AddRow("1487597610.000000", "1487598113.448042", 2)
+	mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`")).WillReturnRows(rows)
+
+	metrics, err := CollectOnce(context.Background(), ScrapeHeartbeat{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetRelabelConfigs(configs); err != nil {
+		t.Fatal(err)
+	}
+	defer SetRelabelConfigs(nil)
+
+	in := make(chan prometheus.Metric)
+	out := make(chan prometheus.Metric)
+	go func() {
+		relabelMetrics(in, out, relabelConfigs)
+		close(out)
+	}()
+	go func() {
+		for _, m := range metrics {
+			in <- m
+		}
+		close(in)
+	}()
+
+	got := map[string][]MetricResult{}
+	for m := range out {
+		name, _, err := descNameHelp(m.Desc())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[name] = append(got[name], readMetric(m))
+	}
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+	return got
+}
+
+func TestRelabelDrop(t *testing.T) {
+	got := relabeledHeartbeat(t, []*RelabelConfig{
+		{SourceLabels: []string{"__name__", "server_id"}, Regex: "mysql_heartbeat_.*;2", Action: RelabelDrop},
+		{SourceLabels: []string{"__name__"}, Regex: "mysql_heartbeat_stored_timestamp_seconds", Action: RelabelDrop},
+	})
+
+	convey.Convey("Matching metrics are dropped", t, func() {
+		convey.So(got, convey.ShouldNotContainKey, "mysql_heartbeat_stored_timestamp_seconds")
+		convey.So(got["mysql_heartbeat_now_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"server_id": "1"}, value: 1487598113.448042, metricType: dto.MetricType_GAUGE},
+		})
+	})
+}
+
+func TestRelabelRenameLabel(t *testing.T) {
+	got := relabeledHeartbeat(t, []*RelabelConfig{
+		{SourceLabels: []string{"server_id"}, TargetLabel: "source_server_id"},
+		{Regex: "server_id", Action: RelabelLabelDrop},
+		{SourceLabels: []string{"__name__"}, Regex: "mysql_heartbeat_(.*)", TargetLabel: "__name__", Replacement: "pt_heartbeat_$1"},
+	})
+
+	convey.Convey("The label and metric are renamed", t, func() {
+		convey.So(got, convey.ShouldNotContainKey, "mysql_heartbeat_stored_timestamp_seconds")
+		convey.So(got["pt_heartbeat_stored_timestamp_seconds"], convey.ShouldResemble, []MetricResult{
+			{labels: labelMap{"source_server_id": "1"}, value: 1487597613.00132, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{"source_server_id": "2"}, value: 1487597610, metricType: dto.MetricType_GAUGE},
+		})
+	})
+}
+
+func TestLoadRelabelConfigs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "relabel.yml")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	convey.Convey("Rules are loaded with the defaults of Prometheus", t, func() {
+		configs, err := LoadRelabelConfigs(write(`
+metric_relabel_configs:
+  - source_labels: [__name__]
+    regex: mysql_perf_schema_events_statements_.*
+    action: drop
+  - source_labels: [server_id]
+    target_label: source_server_id
+`))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(configs, convey.ShouldHaveLength, 2)
+		convey.So(configs[0].Action, convey.ShouldEqual, RelabelDrop)
+		convey.So(configs[1].Action, convey.ShouldEqual, RelabelReplace)
+		convey.So(configs[1].Replacement, convey.ShouldEqual, "$1")
+	})
+
+	convey.Convey("Invalid rules are rejected", t, func() {
+		for _, content := range []string{
+			"metric_relabel_configs:\n  - action: rename\n",
+			"metric_relabel_configs:\n  - source_labels: [__name__]\n    regex: '('\n    action: drop\n",
+			"metric_relabel_configs:\n  - action: drop\n",
+			"metric_relabel_configs:\n  - target_label: 0label\n",
+			"relabel_configs: []\n",
+		} {
+			_, err := LoadRelabelConfigs(write(content))
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Routes of exporter.route_to.
+const (
+	RouteAny     = "any"
+	RoutePrimary = "primary"
+	RouteReplica = "replica"
+)
+
+// Tunable flags.
+var (
+	exporterRouteTo = kingpin.Flag(
+		"exporter.route_to",
+		"Server that ProxySQL should route the queries of the exporter to: primary, replica, or any to leave it to the query rules. The hostgroups of primary and replica are read from exporter.hostgroup_map.",
+	).Default(RouteAny).Enum(RouteAny, RoutePrimary, RouteReplica)
+)
+
+// HostgroupMap holds the ProxySQL hostgroups of the routes of
+// exporter.route_to.
+type HostgroupMap struct {
+	Primary *int `yaml:"primary,omitempty"`
+	Replica *int `yaml:"replica,omitempty"`
+}
+
+// hostgroups is applied to the queries of all scrapers.
+var hostgroups HostgroupMap
+
+// SetHostgroupMap sets the hostgroups of the routes of exporter.route_to. It
+// fails if the hostgroup of the configured route is missing, so it must be
+// called after parsing the flags, even without a map.
+func SetHostgroupMap(m HostgroupMap) error {
+	if err := validateRoute(*exporterRouteTo, m); err != nil {
+		return err
+	}
+	hostgroups = m
+	return nil
+}
+
+// LoadHostgroupMap reads the hostgroups of routes from a YAML file.
+func LoadHostgroupMap(path string) (HostgroupMap, error) {
+	var m HostgroupMap
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := yaml.UnmarshalStrict(content, &m); err != nil {
+		return m, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// hostgroup returns the hostgroup of route, or false for RouteAny.
+func (m HostgroupMap) hostgroup(route string) (int, bool) {
+	var hostgroup *int
+	switch route {
+	case RoutePrimary:
+		hostgroup = m.Primary
+	case RouteReplica:
+		hostgroup = m.Replica
+	}
+	if hostgroup == nil {
+		return 0, false
+	}
+	return *hostgroup, true
+}
+
+// validateRoute checks that route is known and has a valid hostgroup in m.
+func validateRoute(route string, m HostgroupMap) error {
+	switch route {
+	case RouteAny:
+		return nil
+	case RoutePrimary, RouteReplica:
+	default:
+		err := fmt.Errorf("exporter.route_to must be one of %s, %s or %s, got %q", RouteAny, RoutePrimary, RouteReplica, route)
+		return newConfigError("exporter.route_to", ReasonInvalid, err)
+	}
+	hostgroup, ok := m.hostgroup(route)
+	if !ok {
+		err := fmt.Errorf("exporter.route_to %s has no hostgroup in exporter.hostgroup_map", route)
+		return newConfigError("exporter.route_to", ReasonMissing, err)
+	}
+	if hostgroup < 0 {
+		err := fmt.Errorf("hostgroup of %s must not be negative, got %d", route, hostgroup)
+		return newConfigError("exporter.hostgroup_map", ReasonOutOfRange, err)
+	}
+	return nil
+}
+
+// routeHint returns the ProxySQL annotation routing queries to the hostgroup
+// of exporter.route_to, or "" for RouteAny.
+func routeHint() string {
+	hostgroup, ok := hostgroups.hostgroup(*exporterRouteTo)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("/* hostgroup=%d */", hostgroup)
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestRouteHint(t *testing.T) {
+	_, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--exporter.route_to=primary",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+	primary, replica := 10, 20
+	if err := SetHostgroupMap(HostgroupMap{Primary: &primary, Replica: &replica}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { hostgroups = HostgroupMap{} }()
+
+	var issued []string
+	matcher := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		issued = append(issued, actualSQL)
+		if !strings.HasSuffix(actualSQL, expectedSQL) {
+			return fmt.Errorf("query %q does not end with %q", actualSQL, expectedSQL)
+		}
+		return nil
+	})
+	mockDB, mock, err := sqlmock.NewWithDSN("route_hint", sqlmock.QueryMatcherOption(matcher))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	db, err := openCountingDB(mockDB.Driver(), "route_hint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(stmtCacheHeartbeatQuery).WillReturnRows(heartbeatRows())
+	if err := scrapeHeartbeatOnce(db); err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	convey.Convey("The heartbeat query is routed to the primary hostgroup", t, func() {
+		convey.So(issued, convey.ShouldResemble, []string{"/* hostgroup=10 */ /* mysqld_exporter */ " + stmtCacheHeartbeatQuery})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestSetHostgroupMap(t *testing.T) {
+	defer kingpin.CommandLine.Parse([]string{})
+	defer func() { hostgroups = HostgroupMap{} }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hostgroups.yml")
+	if err := os.WriteFile(path, []byte("primary: 10\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.yml")
+	if err := os.WriteFile(invalid, []byte("writer: 10\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	convey.Convey("Hostgroup maps", t, func() {
+		m, err := LoadHostgroupMap(path)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(*m.Primary, convey.ShouldEqual, 10)
+		convey.So(m.Replica, convey.ShouldBeNil)
+
+		_, err = LoadHostgroupMap(invalid)
+		convey.So(err, convey.ShouldNotBeNil)
+
+		for route, valid := range map[string]bool{RouteAny: true, RoutePrimary: true, RouteReplica: false} {
+			_, err := kingpin.CommandLine.Parse([]string{"--exporter.route_to=" + route})
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(SetHostgroupMap(m) == nil, convey.ShouldEqual, valid)
+		}
+
+		_, err = kingpin.CommandLine.Parse([]string{"--exporter.route_to=writer"})
+		convey.So(err, convey.ShouldNotBeNil)
+
+		negative := -1
+		_, err = kingpin.CommandLine.Parse([]string{"--exporter.route_to=primary"})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(SetHostgroupMap(HostgroupMap{Primary: &negative}), convey.ShouldNotBeNil)
+	})
+}
@@ -16,7 +16,12 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/go-kit/log"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,3 +42,242 @@ type Scraper interface {
 	// Scrape collects data from database connection and sends it over channel as prometheus metric.
 	Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error
 }
+
+// scraperLogger returns the logger passed to a scraper, which logs the
+// collector=<name> field. Scrapers reading a specific table add database and
+// table fields.
+func scraperLogger(base log.Logger, s Scraper) log.Logger {
+	return log.With(base, "collector", s.Name())
+}
+
+// configMu serializes changes of scraper flags by ConfigureScraper and
+// RestoreConfig.
+var configMu sync.Mutex
+
+// ConfigSnapshot holds the values of the collect.<name> flags enabling
+// scrapers and of their collect.<name>.<arg> flags, by flag name.
+type ConfigSnapshot map[string]string
+
+// SnapshotConfig captures the flags of scrapers so that they can be restored
+// with RestoreConfig if reconfiguring them with ConfigureScraper fails.
+func SnapshotConfig(scrapers ...Scraper) ConfigSnapshot {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return snapshotConfig(scrapers)
+}
+
+func snapshotConfig(scrapers []Scraper) ConfigSnapshot {
+	snapshot := ConfigSnapshot{}
+	for _, f := range kingpin.CommandLine.Model().Flags {
+		for _, s := range scrapers {
+			if f.Name == "collect."+s.Name() || strings.HasPrefix(f.Name, "collect."+s.Name()+".") {
+				snapshot[f.Name] = f.Value.String()
+			}
+		}
+	}
+	return snapshot
+}
+
+// RestoreConfig sets the flags captured by SnapshotConfig back to their
+// captured values. All flags are restored even if some fail.
+func RestoreConfig(snapshot ConfigSnapshot) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	return restoreConfig(snapshot)
+}
+
+func restoreConfig(snapshot ConfigSnapshot) error {
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var firstErr error
+	for _, name := range names {
+		f := kingpin.CommandLine.GetFlag(name)
+		if f == nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unknown flag %s", name)
+			}
+			continue
+		}
+		if err := f.Model().Value.Set(snapshot[name]); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("restoring flag %s: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+// ConfigureScraper sets the collect.<name>.<arg> flags of a scraper, e.g.
+// {"database": "ops"} for heartbeat, for programs embedding this package
+// without passing the flags on the command line. Parsing the command line
+// resets the flags, so it must be called afterwards. Either all args are set
+// or, on error, none.
+func ConfigureScraper(s Scraper, args map[string]string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	snapshot := snapshotConfig([]Scraper{s})
+	for _, name := range names {
+		flag := "collect." + s.Name() + "." + name
+		f := kingpin.CommandLine.GetFlag(flag)
+		if f == nil {
+			err := fmt.Errorf("unknown arg %s of scraper %s", name, s.Name())
+			return rollbackConfig(snapshot, err)
+		}
+		if err := f.Model().Value.Set(args[name]); err != nil {
+			return rollbackConfig(snapshot, fmt.Errorf("setting flag %s: %w", flag, err))
+		}
+	}
+	return nil
+}
+
+// rollbackConfig restores snapshot after err and returns err.
+func rollbackConfig(snapshot ConfigSnapshot, err error) error {
+	if restoreErr := restoreConfig(snapshot); restoreErr != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", err, restoreErr)
+	}
+	return err
+}
+
+// ConnScraper is implemented by scrapers whose queries must share a session,
+// e.g. profiles, as SHOW PROFILES only lists the queries of its own session.
+// ScrapeConn is called instead of Scrape with a connection dedicated to the
+// scraper, which goes back to the pool afterwards. Scrapers changing session
+// settings must declare them with SessionIniter, so that the connection is
+// discarded instead and the settings do not leak into other scrapers.
+type ConnScraper interface {
+	Scraper
+
+	// ScrapeConn collects data from a single connection and sends it over channel as prometheus metric.
+	ScrapeConn(ctx context.Context, conn *sql.Conn, ch chan<- prometheus.Metric, logger log.Logger) error
+}
+
+// SessionIniter is implemented by scrapers needing session settings, e.g. a
+// short lock_wait_timeout for heartbeat. Like for a ConnScraper, the scraper gets a
+// connection of its own, on which the statements of SessionInit run before
+// Scrape, and which is discarded afterwards.
+type SessionIniter interface {
+	SessionInit() []string
+}
+
+// StateMutator is implemented by scrapers that may write to the server or
+// change its state. Such scrapers are skipped with exporter.read_only_safe.
+// Scrapers not implementing it are assumed to only read.
+type StateMutator interface {
+	MutatesState() bool
+}
+
+// Scraper stabilities.
+const (
+	StabilityStable       = "stable"
+	StabilityExperimental = "experimental"
+)
+
+// Stabilizer is implemented by scrapers whose metrics may still change.
+// Scrapers of StabilityExperimental are skipped unless
+// exporter.include_experimental is set. Scrapers not implementing it are
+// assumed to be stable.
+type Stabilizer interface {
+	Stability() string
+}
+
+// DeltaTracker is implemented by scrapers with metrics whose change since
+// the previous scrape is exported as a <name>_per_scrape_delta gauge, for
+// setups scraping too rarely to compute a rate. The previous values are kept
+// for a bounded number of series.
+type DeltaTracker interface {
+	DeltaTracked() []*prometheus.Desc
+}
+
+// ConfigValidator is implemented by scrapers whose flags can be invalid in
+// ways kingpin does not catch, e.g. out of range values. ValidateConfig must
+// not connect to the server.
+type ConfigValidator interface {
+	ValidateConfig() error
+}
+
+// Describer is implemented by scrapers whose metrics have fixed descriptors,
+// so that they can be listed without scraping. Scrapers with metric names
+// derived from the server, e.g. global_status, do not implement it.
+type Describer interface {
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+// DescribeScraper returns the descriptors of the metrics of a scraper, or nil
+// if it does not implement Describer.
+func DescribeScraper(s Scraper) []*prometheus.Desc {
+	d, ok := s.(Describer)
+	if !ok {
+		return nil
+	}
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		d.Describe(ch)
+		close(ch)
+	}()
+	var descs []*prometheus.Desc
+	for desc := range ch {
+		descs = append(descs, desc)
+	}
+	return descs
+}
+
+// CollectOnce runs the scraper against db and returns the metrics it sent,
+// together with its error. Metrics sent before an error are returned as well.
+// Like the Exporter, it closes the channel once Scrape returned, so scrapers
+// must not close it themselves.
+func CollectOnce(ctx context.Context, s Scraper, db *sql.DB, logger log.Logger) ([]prometheus.Metric, error) {
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	var metrics []prometheus.Metric
+	go func() {
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		close(done)
+	}()
+	err := s.Scrape(ctx, db, ch, logger)
+	close(ch)
+	<-done
+	return metrics, err
+}
+
+// DefaultScraperPriority is the priority of scrapers not implementing
+// Prioritizer.
+const DefaultScraperPriority = 100
+
+// Prioritizer is implemented by scrapers that should run before or after
+// others. Scrapers with a priority below DefaultScraperPriority run one after
+// another before the others start, so that cheap health signals such as
+// heartbeat do not queue behind them for the connections of the pool and are
+// collected even if the scrape times out. The others run concurrently,
+// started in order of priority.
+type Prioritizer interface {
+	// Priority of the Scraper, lower runs first.
+	Priority() int
+}
+
+func scraperPriority(s Scraper) int {
+	if p, ok := s.(Prioritizer); ok {
+		return p.Priority()
+	}
+	return DefaultScraperPriority
+}
+
+// SortScrapers sorts scrapers by priority and scrapers of equal priority by
+// name.
+func SortScrapers(scrapers []Scraper) {
+	sort.SliceStable(scrapers, func(i, j int) bool {
+		pi, pj := scraperPriority(scrapers[i]), scraperPriority(scrapers[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return scrapers[i].Name() < scrapers[j].Name()
+	})
+}
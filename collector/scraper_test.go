@@ -0,0 +1,324 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// sessionDriver records the connection each statement is executed on.
+type sessionDriver struct {
+	mu     sync.Mutex
+	conns  int
+	closed []int
+	execs  map[string]int
+}
+
+func (d *sessionDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns++
+	return &sessionConn{driver: d, id: d.conns}, nil
+}
+
+type sessionConn struct {
+	driver *sessionDriver
+	id     int
+}
+
+func (c *sessionConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *sessionConn) Close() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.closed = append(c.driver.closed, c.id)
+	return nil
+}
+
+func (c *sessionConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *sessionConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.execs[query] = c.id
+	return driver.RowsAffected(0), nil
+}
+
+var sessionTestDriver = &sessionDriver{execs: map[string]int{}}
+
+func init() {
+	sql.Register("session_test", sessionTestDriver)
+}
+
+// sessionScraper creates a temporary table and relies on it in a later query.
+type sessionScraper struct{ fakeScraper }
+
+func (sessionScraper) ScrapeConn(ctx context.Context, conn *sql.Conn, ch chan<- prometheus.Metric, logger log.Logger) error {
+	for _, query := range []string{"CREATE TEMPORARY TABLE t (id INT)", "SELECT id FROM t"} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionInitScraper is a sessionScraper with session settings.
+type sessionInitScraper struct{ sessionScraper }
+
+func (sessionInitScraper) SessionInit() []string {
+	return []string{"SET SESSION time_zone = '+00:00'"}
+}
+
+func TestRunScraperPinsConn(t *testing.T) {
+	db, err := sql.Open("session_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxIdleConns(1)
+
+	// Let the pool hand out an idle connection to the plain queries.
+	if _, err := db.Exec("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runScraper(context.Background(), sessionScraper{}, db, make(chan prometheus.Metric), log.NewNopLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("SELECT 2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runScraper(context.Background(), sessionInitScraper{}, db, make(chan prometheus.Metric), log.NewNopLogger()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("SELECT 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionTestDriver.mu.Lock()
+	defer sessionTestDriver.mu.Unlock()
+	execs := sessionTestDriver.execs
+	convey.Convey("The queries of a ConnScraper share one connection, which is reused afterwards", t, func() {
+		convey.So(execs["CREATE TEMPORARY TABLE t (id INT)"], convey.ShouldEqual, execs["SELECT 1"])
+		convey.So(execs["SELECT 2"], convey.ShouldEqual, execs["SELECT 1"])
+
+		convey.Convey("The connection is only discarded after session settings", func() {
+			convey.So(execs["SELECT id FROM t"], convey.ShouldEqual, execs["SET SESSION time_zone = '+00:00'"])
+			convey.So(sessionTestDriver.closed, convey.ShouldContain, execs["SET SESSION time_zone = '+00:00'"])
+			convey.So(execs["SELECT 3"], convey.ShouldNotEqual, execs["SET SESSION time_zone = '+00:00'"])
+		})
+	})
+}
+
+// initScraper runs a query, after the session statements of init if any.
+type initScraper struct {
+	fakeScraper
+	init  []string
+	query string
+}
+
+func (s initScraper) SessionInit() []string { return s.init }
+
+func (s initScraper) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	_, err := db.ExecContext(ctx, s.query)
+	return err
+}
+
+func TestRunScraperSessionInit(t *testing.T) {
+	db, err := sql.Open("session_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	const setTimeout = "SET SESSION innodb_lock_wait_timeout = 1"
+	scrapers := []Scraper{
+		initScraper{init: []string{setTimeout}, query: "SELECT 'session'"},
+		initScraper{query: "SELECT 'after'"},
+	}
+	for _, scraper := range scrapers {
+		if err := runScraper(context.Background(), scraper, db, make(chan prometheus.Metric), log.NewNopLogger()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessionTestDriver.mu.Lock()
+	defer sessionTestDriver.mu.Unlock()
+	execs := sessionTestDriver.execs
+	convey.Convey("The session statements run on the connection of the declaring scraper only", t, func() {
+		convey.So(execs["SELECT 'session'"], convey.ShouldEqual, execs[setTimeout])
+		convey.So(sessionTestDriver.closed, convey.ShouldContain, execs[setTimeout])
+		convey.So(execs["SELECT 'after'"], convey.ShouldNotEqual, execs[setTimeout])
+		convey.So(sessionTestDriver.closed, convey.ShouldNotContain, execs["SELECT 'after'"])
+	})
+}
+
+// prioritizedScraper is a fakeScraper with a priority.
+type prioritizedScraper struct {
+	fakeScraper
+	priority int
+}
+
+func (s prioritizedScraper) Priority() int { return s.priority }
+
+func TestSortScrapers(t *testing.T) {
+	scrapers := []Scraper{
+		fakeScraper{name: "slave_status"},
+		prioritizedScraper{fakeScraper: fakeScraper{name: "late"}, priority: 200},
+		fakeScraper{name: "global_status"},
+		ScrapeHeartbeat{},
+		prioritizedScraper{fakeScraper: fakeScraper{name: "binlog_size"}, priority: DefaultScraperPriority},
+		prioritizedScraper{fakeScraper: fakeScraper{name: "early"}, priority: 10},
+	}
+	SortScrapers(scrapers)
+
+	var names []string
+	for _, scraper := range scrapers {
+		names = append(names, scraper.Name())
+	}
+	convey.Convey("Scrapers are sorted by priority, then by name", t, func() {
+		convey.So(names, convey.ShouldResemble, []string{
+			"early", "heartbeat", "binlog_size", "global_status", "slave_status", "late",
+		})
+	})
+}
+
+func TestConfigureScraper(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	convey.Convey("Args of a scraper are set without parsing flags", t, func() {
+		err := ConfigureScraper(ScrapeHeartbeat{}, map[string]string{"database": "ops", "table": "beats"})
+		convey.So(err, convey.ShouldBeNil)
+
+		columns := []string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}
+		rows := sqlmock.NewRows(columns).AddRow("1487598110.000000", "1487598113.000000", 1)
+		mock.ExpectQuery(sanitizeQuery("SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `ops`.`beats`")).WillReturnRows(rows)
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		for range ch {
+		}
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+	})
+
+	convey.Convey("Unknown and invalid args are errors", t, func() {
+		convey.So(ConfigureScraper(ScrapeHeartbeat{}, map[string]string{"databse": "ops"}), convey.ShouldNotBeNil)
+		convey.So(ConfigureScraper(ScrapeHeartbeat{}, map[string]string{"max_lag": "soon"}), convey.ShouldNotBeNil)
+	})
+}
+
+func TestConfigureScraperRollback(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--collect.heartbeat.database=ops"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+
+	convey.Convey("An invalid arg rolls back all args", t, func() {
+		err := ConfigureScraper(ScrapeHeartbeat{}, map[string]string{"database": "other", "max_lag": "soon", "table": "beats"})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(*collectHeartbeatDatabase, convey.ShouldEqual, "ops")
+		convey.So(*collectHeartbeatTable, convey.ShouldEqual, "heartbeat")
+		convey.So(*collectHeartbeatMaxLag, convey.ShouldBeZeroValue)
+	})
+
+	convey.Convey("Snapshots restore the flags of several scrapers", t, func() {
+		utc := *collectServerClockUtc
+		snapshot := SnapshotConfig(ScrapeHeartbeat{}, ScrapeServerClock{})
+		convey.So(snapshot["collect.heartbeat.database"], convey.ShouldEqual, "ops")
+		convey.So(snapshot, convey.ShouldContainKey, "collect.server_clock.utc")
+
+		convey.So(ConfigureScraper(ScrapeHeartbeat{}, map[string]string{"database": "other", "max_lag": "5s"}), convey.ShouldBeNil)
+		convey.So(ConfigureScraper(ScrapeServerClock{}, map[string]string{"utc": fmt.Sprint(!utc)}), convey.ShouldBeNil)
+		convey.So(RestoreConfig(snapshot), convey.ShouldBeNil)
+		convey.So(*collectHeartbeatDatabase, convey.ShouldEqual, "ops")
+		convey.So(*collectHeartbeatMaxLag, convey.ShouldBeZeroValue)
+		convey.So(*collectServerClockUtc, convey.ShouldEqual, utc)
+	})
+}
+
+func TestDescribeScraper(t *testing.T) {
+	convey.Convey("Heartbeat describes all its metrics", t, func() {
+		var names []string
+		for _, desc := range DescribeScraper(ScrapeHeartbeat{}) {
+			name, _, err := descNameHelp(desc)
+			convey.So(err, convey.ShouldBeNil)
+			names = append(names, name)
+		}
+		convey.So(names, convey.ShouldResemble, []string{
+			"mysql_heartbeat_stored_timestamp_seconds",
+			"mysql_heartbeat_now_timestamp_seconds",
+			"mysql_heartbeat_stale",
+			"mysql_heartbeat_lag_threshold_exceeded_total",
+			"mysql_heartbeat_age_seconds",
+			"mysql_heartbeat_relay_position_gap_bytes",
+			"mysql_heartbeat_worst_lag_seconds",
+			"mysql_heartbeat_ts_regressed",
+			"mysql_heartbeat_server_count",
+			"mysql_heartbeat_update_interval_seconds",
+			"mysql_heartbeat_server_last_seen_timestamp_seconds",
+			"mysql_heartbeat_server_missing",
+			"mysql_heartbeat_lag_seconds",
+			"mysql_heartbeat_primary_lag_seconds",
+			"mysql_heartbeat_primary_missing",
+			"mysql_heartbeat_parse_errors_total",
+		})
+	})
+
+	convey.Convey("Scrapers without fixed descriptors describe nothing", t, func() {
+		convey.So(DescribeScraper(ScrapeGlobalStatus{}), convey.ShouldBeNil)
+	})
+}
+
+func TestCollectOnce(t *testing.T) {
+	convey.Convey("The metrics and error of a scrape are returned", t, func() {
+		scrapes := 0
+		metrics, err := CollectOnce(context.Background(), countingScraper{fakeScraper: fakeScraper{name: "counting"}, scrapes: &scrapes}, nil, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(metrics, convey.ShouldHaveLength, 1)
+		convey.So(readMetric(metrics[0]).value, convey.ShouldEqual, 1)
+
+		failed := errors.New("failed")
+		metrics, err = CollectOnce(context.Background(), fakeScraper{name: "failing", err: failed}, nil, log.NewNopLogger())
+		convey.So(err, convey.ShouldEqual, failed)
+		convey.So(metrics, convey.ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the clock skew between the exporter host and the server.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const serverClockQuery = "SELECT UNIX_TIMESTAMP(%s)"
+
+var (
+	collectServerClockUtc = kingpin.Flag(
+		"collect.server_clock.utc",
+		"Use UTC for the current timestamp of the server",
+	).Bool()
+
+	clockSkewDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "clock_skew_seconds"),
+		"Difference between the clock of the server and the clock of the exporter host.",
+		nil, nil,
+	)
+)
+
+// serverClockNow returns the time of the exporter host, replaced in tests.
+var serverClockNow = time.Now
+
+// ScrapeServerClock collects the clock skew between the exporter host and the
+// server.
+type ScrapeServerClock struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeServerClock) Name() string {
+	return "server_clock"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeServerClock) Help() string {
+	return "Collect the clock skew between the exporter host and the server"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeServerClock) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeServerClock) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeServerClock) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	// Compare to the middle of the query to leave out the round trip.
+	before := serverClockNow()
+	serverTs, err := queryScalarFloat(ctx, db, fmt.Sprintf(serverClockQuery, nowExpr(*collectServerClockUtc)))
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	after := serverClockNow()
+	local := before.Add(after.Sub(before) / 2)
+
+	ch <- prometheus.MustNewConstMetric(
+		clockSkewDesc, prometheus.GaugeValue,
+		serverTs-float64(local.UnixNano())/1e9,
+	)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeServerClock{}
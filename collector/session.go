@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errSessionConnUsed = errors.New("session connection of the scraper is gone")
+
+// scrapeSession runs Scrape of the scraper on a database whose only
+// connection is conn, so that all its queries see the session settings of
+// SessionInit.
+func scrapeSession(ctx context.Context, scraper Scraper, conn *sql.Conn, ch chan<- prometheus.Metric, logger log.Logger) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		db := sql.OpenDB(&sessionConnector{conn: driverConn.(driver.Conn)})
+		db.SetMaxOpenConns(1)
+		defer db.Close()
+		defer preparedStatements.closeDB(db)
+		return scraper.Scrape(ctx, db, ch, logger)
+	})
+}
+
+// sessionConnector hands out a connection of another database once. The
+// connection stays owned by that database, which discards it afterwards.
+type sessionConnector struct {
+	conn driver.Conn
+	used bool
+}
+
+func (c *sessionConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.used {
+		return nil, errSessionConnUsed
+	}
+	c.used = true
+	return pinnedConn{c.conn}, nil
+}
+
+func (c *sessionConnector) Driver() driver.Driver {
+	return nil
+}
+
+// pinnedConn is a connection handed out by sessionConnector, which is not
+// closed with the database of the scraper. Like countingConn, it forwards
+// the optional driver interfaces of the wrapped connection.
+type pinnedConn struct {
+	driver.Conn
+}
+
+func (c pinnedConn) Close() error {
+	return nil
+}
+
+func (c pinnedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c pinnedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c pinnedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c pinnedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c pinnedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c pinnedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c pinnedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
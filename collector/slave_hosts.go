@@ -11,7 +11,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Scrape heartbeat data.
+// Scrape `SHOW SLAVE HOSTS`.
 
 package collector
 
@@ -32,6 +32,8 @@ const (
 	// The second column allows gets the server timestamp at the exact same
 	// time the query is run.
 	slaveHostsQuery = "SHOW SLAVE HOSTS"
+	// replicasQuery replaces slaveHostsQuery from MySQL 8.0.22.
+	replicasQuery = "SHOW REPLICAS"
 )
 
 // Metric descriptors.
@@ -41,6 +43,11 @@ var (
 		"Information about running slaves",
 		[]string{"server_id", "slave_host", "port", "master_id", "slave_uuid"}, nil,
 	)
+	SlaveHostsCount = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", slavehosts),
+		"Number of replicas registered with the source.",
+		nil, nil,
+	)
 )
 
 // ScrapeSlaveHosts scrapes metrics about the replicating slaves.
@@ -65,7 +72,10 @@ func (ScrapeSlaveHosts) Version() float64 {
 func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
 	slaveHostsRows, err := db.QueryContext(ctx, slaveHostsQuery)
 	if err != nil {
-		return err
+		var replicasErr error
+		if slaveHostsRows, replicasErr = db.QueryContext(ctx, replicasQuery); replicasErr != nil {
+			return err
+		}
 	}
 	defer slaveHostsRows.Close()
 
@@ -85,9 +95,12 @@ func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 		return err
 	}
 
-	for slaveHostsRows.Next() {
+	var count int
+	for ; slaveHostsRows.Next(); count++ {
 		// Newer versions of mysql have the following
 		// 		Server_id, Host, Port, Master_id, Slave_UUID
+		// SHOW REPLICAS has the following
+		// 		Server_Id, Host, Port, Source_Id, Replica_UUID
 		// Older versions of mysql have the following
 		// 		Server_id, Host, Port, Rpl_recovery_rank, Master_id
 		// MySQL 5.5 and MariaDB 10.5 have the following
@@ -130,7 +143,11 @@ func (ScrapeSlaveHosts) Scrape(ctx context.Context, db *sql.DB, ch chan<- promet
 			slaveUuid,
 		)
 	}
+	if err := slaveHostsRows.Err(); err != nil {
+		return err
+	}
 
+	ch <- prometheus.MustNewConstMetric(SlaveHostsCount, prometheus.GaugeValue, float64(count))
 	return nil
 }
 
@@ -50,6 +50,30 @@ func columnValue(scanArgs []interface{}, slaveCols []string, colName string) str
 	return string(*scanArgs[columnIndex].(*sql.RawBytes))
 }
 
+// querySlaveStatus runs the SHOW SLAVE STATUS variant supported by the server.
+func querySlaveStatus(ctx context.Context, db *sql.DB) (*sql.Rows, error) {
+	var (
+		slaveStatusRows *sql.Rows
+		err             error
+	)
+	// Try the both syntax for MySQL/Percona and MariaDB
+	for _, query := range slaveStatusQueries {
+		slaveStatusRows, err = db.QueryContext(ctx, query)
+		if err != nil { // MySQL/Percona
+			// Leverage lock-free SHOW SLAVE STATUS by guessing the right suffix
+			for _, suffix := range slaveStatusQuerySuffixes {
+				slaveStatusRows, err = db.QueryContext(ctx, fmt.Sprint(query, suffix))
+				if err == nil {
+					break
+				}
+			}
+		} else { // MariaDB
+			break
+		}
+	}
+	return slaveStatusRows, err
+}
+
 // ScrapeSlaveStatus collects from `SHOW SLAVE STATUS`.
 type ScrapeSlaveStatus struct{}
 
@@ -70,25 +94,7 @@ func (ScrapeSlaveStatus) Version() float64 {
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
 func (ScrapeSlaveStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
-	var (
-		slaveStatusRows *sql.Rows
-		err             error
-	)
-	// Try the both syntax for MySQL/Percona and MariaDB
-	for _, query := range slaveStatusQueries {
-		slaveStatusRows, err = db.QueryContext(ctx, query)
-		if err != nil { // MySQL/Percona
-			// Leverage lock-free SHOW SLAVE STATUS by guessing the right suffix
-			for _, suffix := range slaveStatusQuerySuffixes {
-				slaveStatusRows, err = db.QueryContext(ctx, fmt.Sprint(query, suffix))
-				if err == nil {
-					break
-				}
-			}
-		} else { // MariaDB
-			break
-		}
-	}
+	slaveStatusRows, err := querySlaveStatus(ctx, db)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the configured delay of delayed replicas from `SHOW SLAVE STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	slaveSQLDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "slave", "sql_delay_seconds"),
+		"Configured delay of the replica behind its source (SOURCE_DELAY).",
+		[]string{"master_host", "master_uuid", "channel_name", "connection_name"}, nil,
+	)
+	slaveSQLRemainingDelayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "slave", "sql_remaining_delay_seconds"),
+		"Seconds left until the delayed replica applies the next event, 0 when it is not waiting.",
+		[]string{"master_host", "master_uuid", "channel_name", "connection_name"}, nil,
+	)
+)
+
+// ScrapeSlaveDelay collects SQL_Delay and SQL_Remaining_Delay.
+type ScrapeSlaveDelay struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSlaveDelay) Name() string {
+	return slaveStatus + ".delay"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSlaveDelay) Help() string {
+	return "Collect the configured and remaining delay of delayed replicas from SHOW SLAVE STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSlaveDelay) Version() float64 {
+	return 5.6
+}
+
+// Stability of the Scraper.
+func (ScrapeSlaveDelay) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSlaveDelay) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	slaveStatusRows, err := querySlaveStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer slaveStatusRows.Close()
+
+	slaveCols, err := slaveStatusRows.Columns()
+	if err != nil {
+		return err
+	}
+	if columnIndex(slaveCols, "SQL_Delay") == -1 {
+		level.Debug(logger).Log("msg", "SHOW SLAVE STATUS has no SQL_Delay column")
+		return nil
+	}
+
+	for slaveStatusRows.Next() {
+		scanArgs := make([]interface{}, len(slaveCols))
+		for i := range scanArgs {
+			scanArgs[i] = &sql.RawBytes{}
+		}
+		if err := slaveStatusRows.Scan(scanArgs...); err != nil {
+			return err
+		}
+
+		delay, err := strconv.ParseFloat(columnValue(scanArgs, slaveCols, "SQL_Delay"), 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		// SQL_Remaining_Delay is NULL unless the SQL thread waits for the delay to pass.
+		var remaining float64
+		if v := columnValue(scanArgs, slaveCols, "SQL_Remaining_Delay"); v != "" {
+			if remaining, err = strconv.ParseFloat(v, 64); err != nil {
+				return newScrapeError(ErrParse, err)
+			}
+		}
+		labels := []string{
+			columnValue(scanArgs, slaveCols, "Master_Host"),
+			columnValue(scanArgs, slaveCols, "Master_UUID"),
+			columnValue(scanArgs, slaveCols, "Channel_Name"),
+			columnValue(scanArgs, slaveCols, "Connection_name"),
+		}
+		ch <- prometheus.MustNewConstMetric(slaveSQLDelayDesc, prometheus.GaugeValue, delay, labels...)
+		ch <- prometheus.MustNewConstMetric(slaveSQLRemainingDelayDesc, prometheus.GaugeValue, remaining, labels...)
+	}
+	return slaveStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSlaveDelay{}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSlaveDelay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	columns := []string{"Master_Host", "Master_UUID", "SQL_Delay", "SQL_Remaining_Delay", "Channel_Name"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("10.0.0.1", "3e11fa47-71ca-11e1-9e33-c80aa9429562", "3600", "1200", "delayed").
+		AddRow("10.0.0.2", "8a94f357-aab4-11df-86ab-c80aa9429562", "3600", nil, "caught_up").
+		AddRow("10.0.0.3", "5d8b6a8e-aab4-11df-86ab-c80aa9429562", "0", nil, "")
+	mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = (ScrapeSlaveDelay{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	delayed := labelMap{"master_host": "10.0.0.1", "master_uuid": "3e11fa47-71ca-11e1-9e33-c80aa9429562", "channel_name": "delayed", "connection_name": ""}
+	caughtUp := labelMap{"master_host": "10.0.0.2", "master_uuid": "8a94f357-aab4-11df-86ab-c80aa9429562", "channel_name": "caught_up", "connection_name": ""}
+	notDelayed := labelMap{"master_host": "10.0.0.3", "master_uuid": "5d8b6a8e-aab4-11df-86ab-c80aa9429562", "channel_name": "", "connection_name": ""}
+	expected := []MetricResult{
+		{labels: delayed, value: 3600, metricType: dto.MetricType_GAUGE},
+		{labels: delayed, value: 1200, metricType: dto.MetricType_GAUGE},
+		{labels: caughtUp, value: 3600, metricType: dto.MetricType_GAUGE},
+		{labels: caughtUp, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: notDelayed, value: 0, metricType: dto.MetricType_GAUGE},
+		{labels: notDelayed, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range expected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, more := <-ch
+		convey.So(more, convey.ShouldBeFalse)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the growth of Relay_Log_Space from `SHOW SLAVE STATUS`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const serverIDQuery = `SELECT @@server_id`
+
+// Metric descriptors.
+var (
+	slaveRelayLogSpaceGrowthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "relay_log_space_growth_bytes_per_second"),
+		"Growth rate of Relay_Log_Space since the previous scrape.",
+		[]string{"master_host", "master_uuid", "channel_name", "connection_name"}, nil,
+	)
+	slaveRelayLogSpaceGrowingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, slaveStatus, "relay_log_space_growing"),
+		"Whether Relay_Log_Space grew since the previous scrape, set even when Seconds_Behind_Master is NULL.",
+		[]string{"master_host", "master_uuid", "channel_name", "connection_name"}, nil,
+	)
+)
+
+// relayLogSpaceSample is a Relay_Log_Space value and the time it was read.
+type relayLogSpaceSample struct {
+	space float64
+	time  time.Time
+}
+
+// relayLogSpaceSamples keeps the previous sample of each replication channel
+// across scrapes, keyed by the replica server_id and the channel labels.
+var relayLogSpaceSamples = struct {
+	sync.Mutex
+	samples map[string]relayLogSpaceSample
+}{samples: map[string]relayLogSpaceSample{}}
+
+// relayLogSpaceNow returns the sample time, replaced in tests.
+var relayLogSpaceNow = time.Now
+
+// ScrapeSlaveRelayLogSpace collects the growth of Relay_Log_Space.
+type ScrapeSlaveRelayLogSpace struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeSlaveRelayLogSpace) Name() string {
+	return slaveStatus + ".relay_log_space"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeSlaveRelayLogSpace) Help() string {
+	return "Collect the growth rate of Relay_Log_Space from SHOW SLAVE STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeSlaveRelayLogSpace) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeSlaveRelayLogSpace) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeSlaveRelayLogSpace) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var serverID string
+	if err := db.QueryRowContext(ctx, serverIDQuery).Scan(&serverID); err != nil {
+		return err
+	}
+
+	slaveStatusRows, err := querySlaveStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer slaveStatusRows.Close()
+
+	slaveCols, err := slaveStatusRows.Columns()
+	if err != nil {
+		return err
+	}
+	if columnIndex(slaveCols, "Relay_Log_Space") == -1 {
+		level.Debug(logger).Log("msg", "SHOW SLAVE STATUS has no Relay_Log_Space column")
+		return nil
+	}
+
+	now := relayLogSpaceNow()
+	for slaveStatusRows.Next() {
+		scanArgs := make([]interface{}, len(slaveCols))
+		for i := range scanArgs {
+			scanArgs[i] = &sql.RawBytes{}
+		}
+		if err := slaveStatusRows.Scan(scanArgs...); err != nil {
+			return err
+		}
+
+		space, err := strconv.ParseFloat(columnValue(scanArgs, slaveCols, "Relay_Log_Space"), 64)
+		if err != nil {
+			return err
+		}
+		labels := []string{
+			columnValue(scanArgs, slaveCols, "Master_Host"),
+			columnValue(scanArgs, slaveCols, "Master_UUID"),
+			columnValue(scanArgs, slaveCols, "Channel_Name"),
+			columnValue(scanArgs, slaveCols, "Connection_name"),
+		}
+		key := serverID + "\xff" + strings.Join(labels, "\xff")
+
+		relayLogSpaceSamples.Lock()
+		prev, ok := relayLogSpaceSamples.samples[key]
+		relayLogSpaceSamples.samples[key] = relayLogSpaceSample{space: space, time: now}
+		relayLogSpaceSamples.Unlock()
+
+		elapsed := now.Sub(prev.time).Seconds()
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		growth := (space - prev.space) / elapsed
+		growing := 0.0
+		if growth > 0 {
+			growing = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			slaveRelayLogSpaceGrowthDesc, prometheus.GaugeValue, growth, labels...,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			slaveRelayLogSpaceGrowingDesc, prometheus.GaugeValue, growing, labels...,
+		)
+	}
+	return slaveStatusRows.Err()
+}
+
+// check interface
+var _ Scraper = ScrapeSlaveRelayLogSpace{}
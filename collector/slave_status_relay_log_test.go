@@ -0,0 +1,74 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeSlaveRelayLogSpace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	start := time.Unix(1487598000, 0)
+	defer func() { relayLogSpaceNow = time.Now }()
+
+	columns := []string{"Master_Host", "Slave_IO_Running", "Seconds_Behind_Master", "Relay_Log_Space"}
+	for _, space := range []string{"1000", "3000"} {
+		mock.ExpectQuery(sanitizeQuery(serverIDQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@server_id"}).AddRow("7"))
+		mock.ExpectQuery(sanitizeQuery("SHOW SLAVE STATUS")).WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("10.0.0.1", "No", nil, space))
+	}
+
+	scrape := func(now time.Time) []MetricResult {
+		relayLogSpaceNow = func() time.Time { return now }
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err := (ScrapeSlaveRelayLogSpace{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+		var got []MetricResult
+		for m := range ch {
+			got = append(got, readMetric(m))
+		}
+		return got
+	}
+
+	labels := labelMap{"master_host": "10.0.0.1", "master_uuid": "", "channel_name": "", "connection_name": ""}
+	convey.Convey("Growth is reported with a NULL Seconds_Behind_Master", t, func() {
+		convey.So(scrape(start), convey.ShouldBeEmpty)
+		convey.So(scrape(start.Add(10*time.Second)), convey.ShouldResemble, []MetricResult{
+			{labels: labels, value: 200, metricType: dto.MetricType_GAUGE},
+			{labels: labels, value: 1, metricType: dto.MetricType_GAUGE},
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
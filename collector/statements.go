@@ -0,0 +1,120 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/alecthomas/kingpin/v2"
+)
+
+// Tunable flags.
+var (
+	exporterPreparedStatements = kingpin.Flag(
+		"exporter.prepared_statements",
+		"Prepare the queries of scrapers that support it once per connection and reuse them.",
+	).Default("false").Bool()
+)
+
+// preparedStatements is shared by all scrapers.
+var preparedStatements = newStmtCache()
+
+// stmtCache holds prepared statements per database handle, keyed by the final
+// query string.
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[*sql.DB]map[string]*sql.Stmt
+}
+
+func newStmtCache() *stmtCache {
+	return &stmtCache{stmts: map[*sql.DB]map[string]*sql.Stmt{}}
+}
+
+// queryContext runs query through a cached prepared statement when
+// exporter.prepared_statements is set, and as a plain query otherwise.
+func (c *stmtCache) queryContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
+	if !*exporterPreparedStatements {
+		return db.QueryContext(ctx, query, args...)
+	}
+	stmt, err := c.prepare(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// prepare returns the cached statement for query on db, preparing it on first
+// use.
+func (c *stmtCache) prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	c.mu.Lock()
+	stmt, ok := c.stmts[db][query]
+	c.mu.Unlock()
+	if ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.stmts[db][query]; ok {
+		// Another scraper prepared the same query concurrently.
+		stmt.Close()
+		return cached, nil
+	}
+	if c.stmts[db] == nil {
+		c.stmts[db] = map[string]*sql.Stmt{}
+	}
+	c.stmts[db][query] = stmt
+	return stmt, nil
+}
+
+// closeDB closes and forgets the statements prepared on db.
+func (c *stmtCache) closeDB(db *sql.DB) error {
+	c.mu.Lock()
+	stmts := c.stmts[db]
+	delete(c.stmts, db)
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, stmt := range stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close closes all cached statements.
+func (c *stmtCache) Close() error {
+	c.mu.Lock()
+	dbs := make([]*sql.DB, 0, len(c.stmts))
+	for db := range c.stmts {
+		dbs = append(dbs, db)
+	}
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, db := range dbs {
+		if err := c.closeDB(db); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const stmtCacheHeartbeatQuery = "SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat`.`heartbeat`"
+
+func parseStmtCacheFlags(t testing.TB, prepared bool) {
+	args := []string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--no-exporter.prepared_statements",
+	}
+	if prepared {
+		args[len(args)-1] = "--exporter.prepared_statements"
+	}
+	if _, err := kingpin.CommandLine.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func heartbeatRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"}).
+		AddRow("1487597613.001320", "1487598113.448042", 1)
+}
+
+func scrapeHeartbeatOnce(db *sql.DB) error {
+	ch := make(chan prometheus.Metric)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- (ScrapeHeartbeat{}).Scrape(context.Background(), db, ch, log.NewNopLogger())
+		close(ch)
+	}()
+	for range ch {
+	}
+	return <-errCh
+}
+
+func TestStmtCacheHeartbeat(t *testing.T) {
+	parseStmtCacheFlags(t, true)
+	defer parseStmtCacheFlags(t, false)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	prepare := mock.ExpectPrepare(sanitizeQuery(stmtCacheHeartbeatQuery))
+	prepare.ExpectQuery().WillReturnRows(heartbeatRows())
+	prepare.ExpectQuery().WillReturnRows(heartbeatRows())
+	prepare.WillBeClosed()
+
+	convey.Convey("The heartbeat query is prepared once", t, func() {
+		convey.So(scrapeHeartbeatOnce(db), convey.ShouldBeNil)
+		convey.So(scrapeHeartbeatOnce(db), convey.ShouldBeNil)
+		convey.So(preparedStatements.closeDB(db), convey.ShouldBeNil)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestStmtCacheKeepalive(t *testing.T) {
+	defer parseStmtCacheFlags(t, false)
+	if _, err := kingpin.CommandLine.Parse([]string{
+		"--collect.heartbeat.database=heartbeat",
+		"--collect.heartbeat.table=heartbeat",
+		"--no-collect.heartbeat.utc",
+		"--exporter.prepared_statements",
+		"--exporter.keepalive_interval=30s",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer stubKeepaliveTicker(make(chan time.Time))()
+
+	mockDB, mock, err := sqlmock.NewWithDSN(addDSNParams("stmt_cache_keepalive"))
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer mockDB.Close()
+	mysqlDriver = mockDB.Driver()
+	defer func() { mysqlDriver = &mysql.MySQLDriver{} }()
+
+	versionRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"@@version"}).AddRow("8.0.33")
+	}
+	mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(versionRows())
+	prepare := mock.ExpectPrepare(sanitizeQuery(stmtCacheHeartbeatQuery))
+	prepare.ExpectQuery().WillReturnRows(heartbeatRows())
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(sanitizeQuery(versionQuery)).WillReturnRows(versionRows())
+		prepare.ExpectQuery().WillReturnRows(heartbeatRows())
+	}
+	prepare.WillBeClosed()
+
+	e := New(context.Background(), "stmt_cache_keepalive", []Scraper{ScrapeHeartbeat{}}, log.NewNopLogger())
+	convey.Convey("Scrapes of a warm pool reuse its statements until it is closed", t, func() {
+		for i := 0; i < 3; i++ {
+			convey.So(collectUp(e), convey.ShouldEqual, 1)
+		}
+		db := warmDBs.dbs[e.key].db
+		convey.So(preparedStatements.stmts[db], convey.ShouldHaveLength, 1)
+		CloseWarmDBs()
+		convey.So(preparedStatements.stmts, convey.ShouldNotContainKey, db)
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func BenchmarkHeartbeatScrape(b *testing.B) {
+	for _, prepared := range []bool{false, true} {
+		name := "plain"
+		if prepared {
+			name = "prepared"
+		}
+		b.Run(name, func(b *testing.B) {
+			parseStmtCacheFlags(b, prepared)
+			defer parseStmtCacheFlags(b, false)
+
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				b.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			prepares := 0
+			if prepared {
+				prepare := mock.ExpectPrepare(sanitizeQuery(stmtCacheHeartbeatQuery))
+				prepares++
+				for i := 0; i < b.N; i++ {
+					prepare.ExpectQuery().WillReturnRows(heartbeatRows())
+				}
+			} else {
+				for i := 0; i < b.N; i++ {
+					mock.ExpectQuery(sanitizeQuery(stmtCacheHeartbeatQuery)).WillReturnRows(heartbeatRows())
+				}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := scrapeHeartbeatOnce(db); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(prepares)/float64(b.N), "prepares/op")
+			preparedStatements.closeDB(db)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the usage of the table cache.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	tableCache = "table_cache"
+	// Queries.
+	tableCacheStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name IN (
+		'Open_tables', 'Opened_tables', 'Table_open_cache_hits', 'Table_open_cache_misses'
+	)`
+	tableCacheSizeQuery = `SELECT @@table_open_cache`
+	showOpenTablesQuery = `SHOW OPEN TABLES`
+)
+
+// Tunable flags.
+var (
+	tableCacheCountOnly = kingpin.Flag(
+		"collect.table_cache.count_only",
+		"Only use the Open_tables status variable instead of listing the cache with SHOW OPEN TABLES, which can return many rows",
+	).Default("true").Bool()
+)
+
+// Metric descriptors.
+var (
+	tableCacheOpenTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "open_tables"),
+		"Number of tables open in the table cache (Open_tables).",
+		nil, nil,
+	)
+	tableCacheOpenedTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "opened_tables_total"),
+		"Number of tables that have been opened (Opened_tables).",
+		nil, nil,
+	)
+	tableCacheSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "size"),
+		"Number of open tables the table cache can hold (table_open_cache).",
+		nil, nil,
+	)
+	tableCacheHitRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "hit_ratio"),
+		"Fraction of table cache lookups that found an open table since the server started.",
+		nil, nil,
+	)
+	tableCacheListedTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "listed_tables"),
+		"Number of non-temporary tables listed by SHOW OPEN TABLES.",
+		nil, nil,
+	)
+	tableCacheInUseTablesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tableCache, "in_use_tables"),
+		"Number of tables listed by SHOW OPEN TABLES that are locked or in use.",
+		nil, nil,
+	)
+)
+
+// ScrapeOpenTables collects the usage of the table cache.
+type ScrapeOpenTables struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeOpenTables) Name() string {
+	return tableCache
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeOpenTables) Help() string {
+	return "Collect the number of open and opened tables and the size and hit ratio of the table cache"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeOpenTables) Version() float64 {
+	return 5.1
+}
+
+// Stability of the Scraper.
+func (ScrapeOpenTables) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeOpenTables) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var size float64
+	if err := db.QueryRowContext(ctx, tableCacheSizeQuery).Scan(&size); err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(tableCacheSizeDesc, prometheus.GaugeValue, size)
+
+	statusRows, err := db.QueryContext(ctx, tableCacheStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key, val string
+		status   = map[string]float64{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		status[key] = value
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	if value, ok := status["Open_tables"]; ok {
+		ch <- prometheus.MustNewConstMetric(tableCacheOpenTablesDesc, prometheus.GaugeValue, value)
+	}
+	if value, ok := status["Opened_tables"]; ok {
+		ch <- prometheus.MustNewConstMetric(tableCacheOpenedTablesDesc, prometheus.CounterValue, value)
+	}
+	// Table_open_cache_hits and _misses exist from MySQL 5.6.
+	hits, okHits := status["Table_open_cache_hits"]
+	misses, okMisses := status["Table_open_cache_misses"]
+	if okHits && okMisses && hits+misses > 0 {
+		ch <- prometheus.MustNewConstMetric(tableCacheHitRatioDesc, prometheus.GaugeValue, hits/(hits+misses))
+	}
+
+	if *tableCacheCountOnly {
+		return nil
+	}
+	return scrapeShowOpenTables(ctx, db, ch)
+}
+
+// scrapeShowOpenTables counts the tables listed by SHOW OPEN TABLES.
+func scrapeShowOpenTables(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric) error {
+	openTablesRows, err := db.QueryContext(ctx, showOpenTablesQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer openTablesRows.Close()
+
+	var (
+		database, table string
+		inUse, nameLock int
+		listed, used    float64
+	)
+	for openTablesRows.Next() {
+		if err := checkCtx(ctx, int(listed)); err != nil {
+			return err
+		}
+		if err := openTablesRows.Scan(&database, &table, &inUse, &nameLock); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		listed++
+		if inUse > 0 {
+			used++
+		}
+	}
+	if err := openTablesRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	ch <- prometheus.MustNewConstMetric(tableCacheListedTablesDesc, prometheus.GaugeValue, listed)
+	ch <- prometheus.MustNewConstMetric(tableCacheInUseTablesDesc, prometheus.GaugeValue, used)
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeOpenTables{}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeOpenTables(t *testing.T) {
+	for _, countOnly := range []bool{true, false} {
+		flag := "--collect.table_cache.count_only"
+		if !countOnly {
+			flag = "--no-collect.table_cache.count_only"
+		}
+		if _, err := kingpin.CommandLine.Parse([]string{flag}); err != nil {
+			t.Fatal(err)
+		}
+
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+
+		mock.ExpectQuery(sanitizeQuery(tableCacheSizeQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"@@table_open_cache"}).AddRow(4000))
+		mock.ExpectQuery(sanitizeQuery(tableCacheStatusQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"Variable_name", "Value"}).
+				AddRow("Open_tables", "120").
+				AddRow("Opened_tables", "300").
+				AddRow("Table_open_cache_hits", "900").
+				AddRow("Table_open_cache_misses", "100"))
+		expected := []MetricResult{
+			{labels: labelMap{}, value: 4000, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 120, metricType: dto.MetricType_GAUGE},
+			{labels: labelMap{}, value: 300, metricType: dto.MetricType_COUNTER},
+			{labels: labelMap{}, value: 0.9, metricType: dto.MetricType_GAUGE},
+		}
+		if !countOnly {
+			mock.ExpectQuery(sanitizeQuery(showOpenTablesQuery)).WillReturnRows(
+				sqlmock.NewRows([]string{"Database", "Table", "In_use", "Name_locked"}).
+					AddRow("shop", "orders", 1, 0).
+					AddRow("shop", "items", 0, 0).
+					AddRow("mysql", "user", 0, 0))
+			expected = append(expected,
+				MetricResult{labels: labelMap{}, value: 3, metricType: dto.MetricType_GAUGE},
+				MetricResult{labels: labelMap{}, value: 1, metricType: dto.MetricType_GAUGE},
+			)
+		}
+
+		ch := make(chan prometheus.Metric)
+		go func() {
+			if err = (ScrapeOpenTables{}).Scrape(context.Background(), db, ch, log.NewNopLogger()); err != nil {
+				t.Errorf("error calling function on test: %s", err)
+			}
+			close(ch)
+		}()
+
+		convey.Convey("Table cache usage is collected", t, func() {
+			for _, expect := range expected {
+				got := readMetric(<-ch)
+				convey.So(got, convey.ShouldResemble, expect)
+			}
+			_, ok := <-ch
+			convey.So(ok, convey.ShouldBeFalse)
+		})
+
+		// Ensure all SQL queries were executed
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled exceptions: %s", err)
+		}
+		db.Close()
+	}
+	kingpin.CommandLine.Parse([]string{})
+}
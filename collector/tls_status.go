@@ -0,0 +1,118 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape the TLS status of the exporter connection from `SHOW STATUS LIKE 'Ssl_%'`.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem.
+	tlsStatus = "tls"
+	// Query. Without GLOBAL the status is the one of the session, i.e. of
+	// the connection of the exporter.
+	tlsStatusQuery = `SHOW STATUS LIKE 'Ssl_%'`
+)
+
+// Metric descriptors.
+var (
+	tlsConnectionInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tlsStatus, "connection_info"),
+		"TLS version and cipher of the exporter connection, both empty without TLS (Ssl_version, Ssl_cipher).",
+		[]string{"version", "cipher"}, nil,
+	)
+	tlsVerifyDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tlsStatus, "verify_depth"),
+		"Depth of certificate chain verification of the exporter connection (Ssl_verify_depth).",
+		nil, nil,
+	)
+	tlsVerifyModeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tlsStatus, "verify_mode"),
+		"Certificate verification mode of the exporter connection (Ssl_verify_mode).",
+		nil, nil,
+	)
+)
+
+// ScrapeTLSStatus collects whether and how the exporter connection uses TLS.
+type ScrapeTLSStatus struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTLSStatus) Name() string {
+	return "tls_status"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTLSStatus) Help() string {
+	return "Collect the TLS version, cipher and verification settings of the exporter connection from SHOW STATUS"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTLSStatus) Version() float64 {
+	return 5.0
+}
+
+// Stability of the Scraper.
+func (ScrapeTLSStatus) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTLSStatus) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	statusRows, err := db.QueryContext(ctx, tlsStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key, val string
+		status   = map[string]string{}
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		status[key] = val
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	// The info metric is also exported for plaintext connections, so that
+	// alerts can match an empty cipher.
+	ch <- prometheus.MustNewConstMetric(
+		tlsConnectionInfoDesc, prometheus.GaugeValue, 1, status["Ssl_version"], status["Ssl_cipher"],
+	)
+	for _, gauge := range []struct {
+		name string
+		desc *prometheus.Desc
+	}{
+		{"Ssl_verify_depth", tlsVerifyDepthDesc},
+		{"Ssl_verify_mode", tlsVerifyModeDesc},
+	} {
+		if value, ok := parseStatus(sql.RawBytes(status[gauge.name])); ok {
+			ch <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, value)
+		}
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeTLSStatus{}
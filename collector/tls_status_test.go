@@ -0,0 +1,86 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTLSStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		version  string
+		cipher   string
+		expected []MetricResult
+	}{
+		{
+			name:    "tls",
+			version: "TLSv1.3",
+			cipher:  "TLS_AES_256_GCM_SHA384",
+			expected: []MetricResult{
+				{labels: labelMap{"version": "TLSv1.3", "cipher": "TLS_AES_256_GCM_SHA384"}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 100, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 5, metricType: dto.MetricType_GAUGE},
+			},
+		},
+		{
+			name: "plaintext",
+			expected: []MetricResult{
+				{labels: labelMap{"version": "", "cipher": ""}, value: 1, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 100, metricType: dto.MetricType_GAUGE},
+				{labels: labelMap{}, value: 5, metricType: dto.MetricType_GAUGE},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("error opening a stub database connection: %s", err)
+			}
+			defer db.Close()
+
+			columns := []string{"Variable_name", "Value"}
+			rows := sqlmock.NewRows(columns).
+				AddRow("Ssl_accepts", "0").
+				AddRow("Ssl_cipher", tt.cipher).
+				AddRow("Ssl_verify_depth", "100").
+				AddRow("Ssl_verify_mode", "5").
+				AddRow("Ssl_version", tt.version)
+			mock.ExpectQuery(sanitizeQuery(tlsStatusQuery)).WillReturnRows(rows)
+
+			metrics, err := CollectOnce(context.Background(), ScrapeTLSStatus{}, db, log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("error calling function on test: %s", err)
+			}
+			var got []MetricResult
+			for _, m := range metrics {
+				got = append(got, readMetric(m))
+			}
+			convey.Convey("TLS status is collected", t, func() {
+				convey.So(got, convey.ShouldResemble, tt.expected)
+			})
+
+			// Ensure all SQL queries were executed
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled exceptions: %s", err)
+			}
+		})
+	}
+}
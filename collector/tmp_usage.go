@@ -0,0 +1,158 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape proxies of the disk usage of temporary files and tables.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	tmpDirQuery         = `SELECT @@tmpdir`
+	tmpFilesStatusQuery = `SHOW GLOBAL STATUS WHERE Variable_name = 'Created_tmp_files'`
+	// The InnoDB temporary tablespace holds on-disk internal temporary
+	// tables, ibtmp1 by default.
+	tmpDataFileQuery = `
+		SELECT FILE_NAME, TOTAL_EXTENTS * EXTENT_SIZE, MAXIMUM_SIZE
+		  FROM information_schema.FILES
+		  WHERE TABLESPACE_NAME = 'innodb_temporary'
+		`
+)
+
+// Metric descriptors.
+var (
+	tmpDirInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "dir_info"),
+		"The directories of temporary files (tmpdir), always 1.",
+		[]string{"tmpdir"}, nil,
+	)
+	tmpFilesCreatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "files_created_total"),
+		"Number of temporary files created (Created_tmp_files).",
+		nil, nil,
+	)
+	tmpDataFileBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "data_file_bytes"),
+		"Size of the data files of the InnoDB temporary tablespace.",
+		[]string{"file_name"}, nil,
+	)
+	tmpDataFileMaxBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, tmpTables, "data_file_max_bytes"),
+		"Maximum size of the data files of the InnoDB temporary tablespace, if limited by innodb_temp_data_file_path.",
+		[]string{"file_name"}, nil,
+	)
+)
+
+// ScrapeTmpUsage collects the temporary directories, the number of temporary
+// files created and the size of the InnoDB temporary tablespace, which grows
+// with on-disk internal temporary tables and only shrinks on restart.
+type ScrapeTmpUsage struct{}
+
+// Name of the Scraper. Should be unique.
+func (ScrapeTmpUsage) Name() string {
+	return "tmp_usage"
+}
+
+// Help describes the role of the Scraper.
+func (ScrapeTmpUsage) Help() string {
+	return "Collect tmpdir, Created_tmp_files and the size of the InnoDB temporary tablespace"
+}
+
+// Version of MySQL from which scraper is available.
+func (ScrapeTmpUsage) Version() float64 {
+	return 5.7
+}
+
+// Stability of the Scraper.
+func (ScrapeTmpUsage) Stability() string {
+	return StabilityExperimental
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (ScrapeTmpUsage) Scrape(ctx context.Context, db *sql.DB, ch chan<- prometheus.Metric, logger log.Logger) error {
+	var tmpDir string
+	if err := db.QueryRowContext(ctx, tmpDirQuery).Scan(&tmpDir); err != nil {
+		return wrapDriverError(err)
+	}
+	ch <- prometheus.MustNewConstMetric(tmpDirInfoDesc, prometheus.GaugeValue, 1, tmpDir)
+
+	statusRows, err := db.QueryContext(ctx, tmpFilesStatusQuery)
+	if err != nil {
+		return wrapDriverError(err)
+	}
+	defer statusRows.Close()
+
+	var (
+		key string
+		val sql.RawBytes
+	)
+	for statusRows.Next() {
+		if err := statusRows.Scan(&key, &val); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		if value, ok := parseStatus(val); ok {
+			ch <- prometheus.MustNewConstMetric(tmpFilesCreatedDesc, prometheus.CounterValue, value)
+		}
+	}
+	if err := statusRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+
+	fileRows, err := db.QueryContext(ctx, tmpDataFileQuery)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && (mysqlErr.Number == 1146 || mysqlErr.Number == 1142) {
+			level.Debug(logger).Log("msg", "InnoDB temporary tablespace is not available", "err", err)
+			return nil
+		}
+		return wrapDriverError(err)
+	}
+	defer fileRows.Close()
+
+	var (
+		fileName string
+		size     uint64
+		maxSize  sql.NullInt64
+		found    bool
+	)
+	for fileRows.Next() {
+		if err := fileRows.Scan(&fileName, &size, &maxSize); err != nil {
+			return newScrapeError(ErrParse, err)
+		}
+		found = true
+		ch <- prometheus.MustNewConstMetric(tmpDataFileBytesDesc, prometheus.GaugeValue, float64(size), fileName)
+		// MAXIMUM_SIZE is NULL for autoextending files without max.
+		if maxSize.Valid {
+			ch <- prometheus.MustNewConstMetric(tmpDataFileMaxBytesDesc, prometheus.GaugeValue, float64(maxSize.Int64), fileName)
+		}
+	}
+	if err := fileRows.Err(); err != nil {
+		return wrapDriverError(err)
+	}
+	if !found {
+		level.Debug(logger).Log("msg", "No InnoDB temporary tablespace in information_schema.FILES")
+	}
+	return nil
+}
+
+// check interface
+var _ Scraper = ScrapeTmpUsage{}
@@ -0,0 +1,90 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-kit/log"
+	"github.com/go-sql-driver/mysql"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScrapeTmpUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(tmpDirQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@tmpdir"}).AddRow("/var/tmp"))
+	mock.ExpectQuery(sanitizeQuery(tmpFilesStatusQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Created_tmp_files", "42"))
+	mock.ExpectQuery(sanitizeQuery(tmpDataFileQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"FILE_NAME", "TOTAL_EXTENTS * EXTENT_SIZE", "MAXIMUM_SIZE"}).
+			AddRow("./ibtmp1", 12582912, 1073741824))
+
+	metrics, err := CollectOnce(context.Background(), ScrapeTmpUsage{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	expected := []MetricResult{
+		{labels: labelMap{"tmpdir": "/var/tmp"}, value: 1, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{}, value: 42, metricType: dto.MetricType_COUNTER},
+		{labels: labelMap{"file_name": "./ibtmp1"}, value: 12582912, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"file_name": "./ibtmp1"}, value: 1073741824, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, len(expected))
+		for i, expect := range expected {
+			convey.So(readMetric(metrics[i]), convey.ShouldResemble, expect)
+		}
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestScrapeTmpUsageNoTablespace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(sanitizeQuery(tmpDirQuery)).WillReturnRows(sqlmock.NewRows([]string{"@@tmpdir"}).AddRow("/tmp"))
+	mock.ExpectQuery(sanitizeQuery(tmpFilesStatusQuery)).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+	mock.ExpectQuery(sanitizeQuery(tmpDataFileQuery)).WillReturnError(&mysql.MySQLError{Number: 1146, Message: "Table 'information_schema.FILES' doesn't exist"})
+
+	metrics, err := CollectOnce(context.Background(), ScrapeTmpUsage{}, db, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("error calling function on test: %s", err)
+	}
+
+	convey.Convey("Only tmpdir without status and tablespace", t, func() {
+		convey.So(metrics, convey.ShouldHaveLength, 1)
+		convey.So(readMetric(metrics[0]), convey.ShouldResemble, MetricResult{labels: labelMap{"tmpdir": "/tmp"}, value: 1, metricType: dto.MetricType_GAUGE})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
@@ -57,10 +57,28 @@ var (
 	err error
 )
 
+// Sections used without an auth_module. The exporter section inherits the
+// keys it does not set from the client section, which other MySQL clients
+// read as well.
+const (
+	ClientSection   = "client"
+	ExporterSection = "client.mysqld_exporter"
+)
+
 type Config struct {
 	Sections map[string]MySqlConfig
 }
 
+// DefaultSection returns the [client.mysqld_exporter] section if the config
+// file has one, and the [client] section otherwise.
+func (c *Config) DefaultSection() (string, MySqlConfig, bool) {
+	if section, ok := c.Sections[ExporterSection]; ok {
+		return ExporterSection, section, true
+	}
+	section, ok := c.Sections[ClientSection]
+	return ClientSection, section, ok
+}
+
 type MySqlConfig struct {
 	User                  string `ini:"user"`
 	Password              string `ini:"password"`
@@ -105,16 +123,32 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 		return fmt.Errorf("failed to load %s: %w", filename, err)
 	}
 
-	if host, port, err = net.SplitHostPort(mysqldAddress); err != nil {
-		return fmt.Errorf("failed to parse address: %w", err)
+	// An address of the form unix://<path> connects through a socket.
+	const socketPrefix = "unix://"
+	isSocket := strings.HasPrefix(mysqldAddress, socketPrefix)
+	socket := strings.TrimPrefix(mysqldAddress, socketPrefix)
+	if !isSocket {
+		if host, port, err = net.SplitHostPort(mysqldAddress); err != nil {
+			return fmt.Errorf("failed to parse address: %w", err)
+		}
 	}
 
-	if clientSection := cfg.Section("client"); clientSection != nil {
-		if cfgHost := clientSection.Key("host"); cfgHost.String() == "" {
-			cfgHost.SetValue(host)
-		}
-		if cfgPort := clientSection.Key("port"); cfgPort.String() == "" {
-			cfgPort.SetValue(port)
+	if clientSection := cfg.Section(ClientSection); clientSection != nil {
+		if isSocket {
+			// Key would add empty host and port keys, which fail to map.
+			if clientSection.HasKey("host") || clientSection.HasKey("port") {
+				return fmt.Errorf("socket %s conflicts with the host and port of the client section", socket)
+			}
+			if cfgSocket := clientSection.Key("socket"); cfgSocket.String() == "" {
+				cfgSocket.SetValue(socket)
+			}
+		} else {
+			if cfgHost := clientSection.Key("host"); cfgHost.String() == "" {
+				cfgHost.SetValue(host)
+			}
+			if cfgPort := clientSection.Key("port"); cfgPort.String() == "" {
+				cfgPort.SetValue(port)
+			}
 		}
 		if cfgUser := clientSection.Key("user"); cfgUser.String() == "" {
 			cfgUser.SetValue(mysqldUser)
@@ -85,6 +85,31 @@ func TestValidateConfig(t *testing.T) {
 		convey.So(section.Password, convey.ShouldEqual, "supersecretpassword")
 	})
 
+	convey.Convey("Socket from CLI flags", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		os.Setenv("MYSQLD_EXPORTER_PASSWORD", "supersecretpassword")
+		if err := c.ReloadConfig("", "unix:///var/run/mysqld/mysqld.sock", "testuser", true, log.NewNopLogger()); err != nil {
+			t.Error(err)
+		}
+
+		section := c.GetConfig().Sections["client"]
+		convey.So(section.Socket, convey.ShouldEqual, "/var/run/mysqld/mysqld.sock")
+		convey.So(section.Host, convey.ShouldEqual, "")
+		dsn, err := section.FormDSN("")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "testuser:supersecretpassword@unix(/var/run/mysqld/mysqld.sock)/?tls=skip-verify")
+	})
+
+	convey.Convey("Socket from CLI flags conflicts with host in config file", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		err := c.ReloadConfig("testdata/client.cnf", "unix:///var/run/mysqld/mysqld.sock", "", true, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeError)
+	})
+
 	convey.Convey("Environment variable / CLI flags error without port", t, func() {
 		c := MySqlConfigHandler{
 			Config: &Config{},
@@ -141,6 +166,47 @@ func TestValidateConfig(t *testing.T) {
 	})
 }
 
+func TestDefaultSection(t *testing.T) {
+	convey.Convey("The exporter section takes precedence over the client section", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		if err := c.ReloadConfig("testdata/exporter_client.cnf", "localhost:3306", "", true, log.NewNopLogger()); err != nil {
+			t.Error(err)
+		}
+
+		name, section, ok := c.GetConfig().DefaultSection()
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(name, convey.ShouldEqual, "client.mysqld_exporter")
+		convey.So(section.User, convey.ShouldEqual, "exporter")
+		convey.So(section.Password, convey.ShouldEqual, "abc")
+		convey.So(section.Host, convey.ShouldEqual, "server2")
+	})
+
+	convey.Convey("The client section is used without an exporter section", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		if err := c.ReloadConfig("testdata/client.cnf", "localhost:3306", "", true, log.NewNopLogger()); err != nil {
+			t.Error(err)
+		}
+
+		name, section, ok := c.GetConfig().DefaultSection()
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(name, convey.ShouldEqual, "client")
+		convey.So(section.User, convey.ShouldEqual, "root")
+	})
+
+	convey.Convey("A malformed file is reported", t, func() {
+		c := MySqlConfigHandler{
+			Config: &Config{},
+		}
+		err := c.ReloadConfig("testdata/malformed.cnf", "localhost:3306", "", true, log.NewNopLogger())
+		convey.So(err, convey.ShouldBeError)
+		convey.So(err.Error(), convey.ShouldStartWith, "failed to load testdata/malformed.cnf")
+	})
+}
+
 func TestFormDSN(t *testing.T) {
 	var (
 		c = MySqlConfigHandler{
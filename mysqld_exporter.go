@@ -15,9 +15,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -48,10 +54,15 @@ var (
 		"config.my-cnf",
 		"Path to .my.cnf file to read MySQL credentials from.",
 	).Default(".my.cnf").String()
-	mysqldAddress = kingpin.Flag(
+	mysqldAddressSet bool
+	mysqldAddress    = kingpin.Flag(
 		"mysqld.address",
 		"Address to use for connecting to MySQL",
-	).Default("localhost:3306").String()
+	).Default("localhost:3306").IsSetByUser(&mysqldAddressSet).String()
+	mysqldSocket = kingpin.Flag(
+		"mysqld.socket",
+		"Path of the Unix socket to use for connecting to MySQL instead of mysqld.address.",
+	).String()
 	mysqldUser = kingpin.Flag(
 		"mysqld.username",
 		"Hostname to use for connecting to MySQL",
@@ -60,6 +71,46 @@ var (
 		"tls.insecure-skip-verify",
 		"Ignore certificate and server verification when using a tls connection.",
 	).Bool()
+	configDump = kingpin.Flag(
+		"config.dump",
+		"Print the collector configuration as YAML and exit.",
+	).Bool()
+	configCheck = kingpin.Flag(
+		"config.check",
+		"Validate the collector configuration without connecting to the server and exit.",
+	).Bool()
+	configCheckFormat = kingpin.Flag(
+		"config.check-format",
+		"Format of the errors of config.check: text logs them, json prints them as a JSON array for tooling.",
+	).Default("text").Enum("text", "json")
+	metricsNamespace = kingpin.Flag(
+		"metrics.namespace",
+		"Namespace of the exported metrics.",
+	).Default("mysql").String()
+	metricsConstLabels = kingpin.Flag(
+		"metrics.const_label",
+		"Label added to all exported metrics, in the form <name>=<value>. Can be repeated.",
+	).StringMap()
+	metricsRelabelConfig = kingpin.Flag(
+		"metrics.relabel_config",
+		"Path of a YAML file with metric_relabel_configs applied to all exported metrics.",
+	).Default("").String()
+	metricsHelpConfig = kingpin.Flag(
+		"metrics.help_config",
+		"Path of a YAML file with the help text of global_status and global_variables metrics by variable name.",
+	).Default("").String()
+	exporterHostgroupMap = kingpin.Flag(
+		"exporter.hostgroup_map",
+		"Path of a YAML file with the ProxySQL hostgroups of the primary and replica routes of exporter.route_to.",
+	).Default("").String()
+	collectorsEnableMatching = kingpin.Flag(
+		"collectors.enable-matching",
+		"Enable all collectors whose name fully matches the regular expression, e.g. 'perf_schema\\..*'. Collectors enabled or disabled with their collect.<name> flag keep that setting.",
+	).Default("").String()
+	collectorsDisableMatching = kingpin.Flag(
+		"collectors.disable-matching",
+		"Disable all collectors whose name fully matches the regular expression, applied after collectors.enable-matching. Collectors enabled or disabled with their collect.<name> flag keep that setting.",
+	).Default("").String()
 	toolkitFlags = webflag.AddFlags(kingpin.CommandLine, ":9104")
 	c            = config.MySqlConfigHandler{
 		Config: &config.Config{},
@@ -69,7 +120,9 @@ var (
 // scrapers lists all possible collection methods and if they should be enabled by default.
 var scrapers = map[collector.Scraper]bool{
 	collector.ScrapeGlobalStatus{}:                        true,
+	collector.ScrapeConnectionsHeadroom{}:                 false,
 	collector.ScrapeGlobalVariables{}:                     true,
+	collector.ScrapeSessionBuffers{}:                      false,
 	collector.ScrapeSlaveStatus{}:                         true,
 	collector.ScrapeProcesslist{}:                         false,
 	collector.ScrapeUser{}:                                false,
@@ -87,9 +140,12 @@ var scrapers = map[collector.Scraper]bool{
 	collector.ScrapePerfFileEvents{}:                      false,
 	collector.ScrapePerfFileInstances{}:                   false,
 	collector.ScrapePerfMemoryEvents{}:                    false,
+	collector.ScrapePerfOverhead{}:                        false,
+	collector.ScrapePerfErrorLog{}:                        false,
 	collector.ScrapePerfReplicationGroupMembers{}:         false,
 	collector.ScrapePerfReplicationGroupMemberStats{}:     false,
 	collector.ScrapePerfReplicationApplierStatsByWorker{}: false,
+	collector.ScrapePerfReplicationConnectionStatus{}:     false,
 	collector.ScrapeSysUserSummary{}:                      false,
 	collector.ScrapeUserStat{}:                            false,
 	collector.ScrapeClientStat{}:                          false,
@@ -100,38 +156,152 @@ var scrapers = map[collector.Scraper]bool{
 	collector.ScrapeQueryResponseTime{}:                   true,
 	collector.ScrapeEngineTokudbStatus{}:                  false,
 	collector.ScrapeEngineInnodbStatus{}:                  false,
+	collector.ScrapeInnodbFlush{}:                         false,
 	collector.ScrapeHeartbeat{}:                           false,
 	collector.ScrapeSlaveHosts{}:                          false,
+	collector.ScrapeSlaveRelayLogSpace{}:                  false,
+	collector.ScrapeProfiles{}:                            false,
 	collector.ScrapeReplicaHost{}:                         false,
+	collector.ScrapeRouterGroupMembers{}:                  false,
+	collector.ScrapeServerClock{}:                         false,
+	collector.ScrapeReplicationGTID{}:                     false,
+	collector.ScrapeSchemaObjects{}:                       false,
+	collector.ScrapeGaleraStatus{}:                        false,
+	collector.ScrapeOpenTables{}:                          false,
+	collector.ScrapeInnodbBufferPoolStats{}:               false,
+	collector.ScrapeEngineInnodbMutex{}:                   false,
+	collector.ScrapeSlaveDelay{}:                          false,
+	collector.ScrapeKeyCache{}:                            false,
+	collector.ScrapeConnections{}:                         false,
+	collector.ScrapeReplicaWorkers{}:                      false,
+	collector.ScrapeTableFragmentation{}:                  false,
+	collector.ScrapeApplierLag{}:                          false,
+	collector.ScrapeTempAndSort{}:                         false,
+	collector.ScrapeTLSStatus{}:                           false,
+	collector.ScrapeClonePlugin{}:                         false,
+	collector.ScrapeAccountLimits{}:                       false,
+	collector.ScrapePerfSchemaSetup{}:                     false,
+	collector.ScrapeBinlogEvents{}:                        false,
+	collector.ScrapeOpenFiles{}:                           false,
+	collector.ScrapeInnodbBufferPoolStatus{}:              false,
+	collector.ScrapeMetadataLocks{}:                       false,
+	collector.ScrapeLogStatus{}:                           false,
+	collector.ScrapeThreadPool{}:                          false,
+	collector.ScrapeRelayLogStatus{}:                      false,
+	collector.ScrapeInnodbRedoLog{}:                       false,
+	collector.ScrapeConnectionErrors{}:                    false,
+	collector.ScrapeGrants{}:                              false,
+	collector.ScrapeUptime{}:                              false,
+	collector.ScrapeLongTransactions{}:                    false,
+	collector.ScrapeReplicationFilters{}:                  false,
+	collector.ScrapeTmpUsage{}:                            false,
+	collector.ScrapeWaitClasses{}:                         false,
+	collector.ScrapePreparedStatements{}:                  false,
+	collector.ScrapeSelectScanTypes{}:                     false,
+	collector.ScrapeSchemaSize{}:                          false,
+	collector.ScrapeConnectionsByHost{}:                   false,
 }
 
-func filterScrapers(scrapers []collector.Scraper, collectParams []string) []collector.Scraper {
-	var filteredScrapers []collector.Scraper
+// filterScrapers returns the scrapers to run for a single request. Without
+// "collect[]" query parameters the enabled scrapers run. Otherwise exactly the
+// requested scrapers run, whether or not they are enabled by flag.
+func filterScrapers(enabled, all []collector.Scraper, collectParams []string) ([]collector.Scraper, error) {
+	if len(collectParams) == 0 {
+		return enabled, nil
+	}
 
-	// Check if we have some "collect[]" query parameters.
-	if len(collectParams) > 0 {
-		filters := make(map[string]bool)
-		for _, param := range collectParams {
-			filters[param] = true
-		}
+	filters := make(map[string]bool)
+	for _, param := range collectParams {
+		filters[param] = true
+	}
 
-		for _, scraper := range scrapers {
-			if filters[scraper.Name()] {
-				filteredScrapers = append(filteredScrapers, scraper)
-			}
+	var filteredScrapers []collector.Scraper
+	for _, scraper := range all {
+		if filters[scraper.Name()] {
+			filteredScrapers = append(filteredScrapers, scraper)
+			delete(filters, scraper.Name())
+		}
+	}
+	if len(filters) > 0 {
+		unknown := make([]string, 0, len(filters))
+		for name := range filters {
+			unknown = append(unknown, name)
+		}
+		valid := make([]string, 0, len(all))
+		for _, scraper := range all {
+			valid = append(valid, scraper.Name())
 		}
+		sort.Strings(unknown)
+		sort.Strings(valid)
+		return nil, fmt.Errorf("unknown collectors: %s; valid collectors: %s", strings.Join(unknown, ", "), strings.Join(valid, ", "))
+	}
+	return filteredScrapers, nil
+}
+
+// mysqldTarget returns the address the config is loaded with, which is
+// unix://<socket> when connecting through a socket.
+func mysqldTarget(address string, addressSet bool, socket string) (string, error) {
+	if socket == "" {
+		return address, nil
 	}
-	if len(filteredScrapers) == 0 {
-		return scrapers
+	if addressSet {
+		return "", errors.New("--mysqld.socket and --mysqld.address are mutually exclusive")
 	}
-	return filteredScrapers
+	return "unix://" + socket, nil
 }
 
 func init() {
 	prometheus.MustRegister(version.NewCollector("mysqld_exporter"))
 }
 
-func newHandler(scrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
+// scrapeContext returns the context of a scrape. It is cancelled when the
+// connection of the request gets closed and, if Prometheus sent its scrape
+// timeout, when the timeout minus timeout-offset expires.
+func scrapeContext(r *http.Request, logger log.Logger) (context.Context, context.CancelFunc) {
+	ctx := r.Context()
+	v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if v == "" {
+		return context.WithCancel(ctx)
+	}
+	timeoutSeconds, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		level.Error(logger).Log("msg", "Failed to parse timeout from Prometheus header", "err", err)
+		return context.WithCancel(ctx)
+	}
+	if *timeoutOffset >= timeoutSeconds {
+		// Ignore timeout offset if it doesn't leave time to scrape.
+		level.Error(logger).Log("msg", "Timeout offset should be lower than prometheus scrape timeout", "offset", *timeoutOffset, "prometheus_scrape_timeout", timeoutSeconds)
+	} else {
+		// Subtract timeout offset from timeout.
+		timeoutSeconds -= *timeoutOffset
+	}
+	return context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
+}
+
+// configErrorFlag returns the flag of an error of
+// collector.ValidateConfiguration.
+func configErrorFlag(err error) string {
+	var configErr *collector.ConfigError
+	if errors.As(err, &configErr) {
+		return configErr.Flag()
+	}
+	return ""
+}
+
+// setHostgroupMap loads the hostgroups of exporter.route_to from path, if
+// set. The route is validated even without a map.
+func setHostgroupMap(path string) error {
+	hostgroups := collector.HostgroupMap{}
+	if path != "" {
+		var err error
+		if hostgroups, err = collector.LoadHostgroupMap(path); err != nil {
+			return err
+		}
+	}
+	return collector.SetHostgroupMap(hostgroups)
+}
+
+func newHandler(enabledScrapers, allScrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var dsn string
 		var err error
@@ -142,41 +312,23 @@ func newHandler(scrapers []collector.Scraper, logger log.Logger) http.HandlerFun
 		}
 
 		cfg := c.GetConfig()
-		cfgsection, ok := cfg.Sections["client"]
+		name, cfgsection, ok := cfg.DefaultSection()
 		if !ok {
-			level.Error(logger).Log("msg", "Failed to parse section [client] from config file", "err", err)
+			level.Error(logger).Log("msg", fmt.Sprintf("Failed to parse section [%s] from config file", name), "err", err)
 		}
 		if dsn, err = cfgsection.FormDSN(target); err != nil {
-			level.Error(logger).Log("msg", "Failed to form dsn from section [client]", "err", err)
+			level.Error(logger).Log("msg", fmt.Sprintf("Failed to form dsn from section [%s]", name), "err", err)
 		}
 
-		collect := q["collect[]"]
-
-		// Use request context for cancellation when connection gets closed.
-		ctx := r.Context()
-		// If a timeout is configured via the Prometheus header, add it to the context.
-		if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
-			timeoutSeconds, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				level.Error(logger).Log("msg", "Failed to parse timeout from Prometheus header", "err", err)
-			} else {
-				if *timeoutOffset >= timeoutSeconds {
-					// Ignore timeout offset if it doesn't leave time to scrape.
-					level.Error(logger).Log("msg", "Timeout offset should be lower than prometheus scrape timeout", "offset", *timeoutOffset, "prometheus_scrape_timeout", timeoutSeconds)
-				} else {
-					// Subtract timeout offset from timeout.
-					timeoutSeconds -= *timeoutOffset
-				}
-				// Create new timeout context with request context as parent.
-				var cancel context.CancelFunc
-				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds*float64(time.Second)))
-				defer cancel()
-				// Overwrite request with timeout context.
-				r = r.WithContext(ctx)
-			}
+		filteredScrapers, err := filterScrapers(enabledScrapers, allScrapers, q["collect[]"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		filteredScrapers := filterScrapers(scrapers, collect)
+		ctx, cancel := scrapeContext(r, logger)
+		defer cancel()
+		r = r.WithContext(ctx)
 
 		registry := prometheus.NewRegistry()
 
@@ -192,21 +344,15 @@ func newHandler(scrapers []collector.Scraper, logger log.Logger) http.HandlerFun
 	}
 }
 
+// shutdownTimeout bounds the time running scrapes get to finish on shutdown
+// before the collectors are closed.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Generate ON/OFF flags for all scrapers.
-	scraperFlags := map[collector.Scraper]*bool{}
-	for scraper, enabledByDefault := range scrapers {
-		defaultOn := "false"
-		if enabledByDefault {
-			defaultOn = "true"
-		}
-
-		f := kingpin.Flag(
-			"collect."+scraper.Name(),
-			scraper.Help(),
-		).Default(defaultOn).Bool()
-
-		scraperFlags[scraper] = f
+	scraperFlags, err := addScraperFlags(kingpin.CommandLine, scrapers)
+	if err != nil {
+		kingpin.Fatalf("%s", err)
 	}
 
 	// Parse flags.
@@ -216,25 +362,111 @@ func main() {
 	kingpin.HelpFlag.Short('h')
 	kingpin.Parse()
 	logger := promlog.New(promlogConfig)
+	collector.SetNamespace(*metricsNamespace)
+	if err := collector.SetConstLabels(*metricsConstLabels); err != nil {
+		level.Error(logger).Log("msg", "Error setting constant labels", "err", err)
+		os.Exit(1)
+	}
+	if *metricsRelabelConfig != "" {
+		configs, err := collector.LoadRelabelConfigs(*metricsRelabelConfig)
+		if err == nil {
+			err = collector.SetRelabelConfigs(configs)
+		}
+		if err != nil {
+			level.Error(logger).Log("msg", "Error loading relabel config", "err", err)
+			os.Exit(1)
+		}
+	}
+	if *metricsHelpConfig != "" {
+		help, err := collector.LoadMetricHelp(*metricsHelpConfig)
+		if err == nil {
+			err = collector.SetMetricHelp(help)
+		}
+		if err != nil {
+			level.Error(logger).Log("msg", "Error loading help config", "err", err)
+			os.Exit(1)
+		}
+	}
+	if err := setHostgroupMap(*exporterHostgroupMap); err != nil {
+		level.Error(logger).Log("msg", "Error loading hostgroup map", "err", err)
+		os.Exit(1)
+	}
+
+	for _, m := range []struct {
+		pattern string
+		enabled bool
+	}{
+		{*collectorsEnableMatching, true},
+		{*collectorsDisableMatching, false},
+	} {
+		if m.pattern == "" {
+			continue
+		}
+		matched, err := setScrapersMatching(scraperFlags, m.pattern, m.enabled)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error selecting collectors", "err", err)
+			os.Exit(1)
+		}
+		level.Info(logger).Log("msg", "Selected collectors by pattern", "pattern", m.pattern, "enabled", m.enabled, "changed", matched)
+	}
+
+	if *configDump {
+		out, err := dumpConfig(scraperFlags, kingpin.CommandLine.Model().Flags)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error dumping collector configuration", "err", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(out)
+		os.Exit(0)
+	}
+
+	if *configCheck {
+		enabled := []collector.Scraper{}
+		for scraper, on := range scraperFlags {
+			if *on {
+				enabled = append(enabled, scraper)
+			}
+		}
+		collector.SortScrapers(enabled)
+		errs := collector.ValidateConfiguration(enabled)
+		if *configCheckFormat == "json" {
+			out, err := collector.ConfigErrorsJSON(errs)
+			if err != nil {
+				level.Error(logger).Log("msg", "Error serializing configuration errors", "err", err)
+				os.Exit(1)
+			}
+			os.Stdout.Write(append(out, '\n'))
+		} else {
+			for _, err := range errs {
+				level.Error(logger).Log("msg", "Invalid collector configuration", "flag", configErrorFlag(err), "err", err)
+			}
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		level.Info(logger).Log("msg", "Collector configuration is valid")
+		os.Exit(0)
+	}
 
 	level.Info(logger).Log("msg", "Starting mysqld_exporter", "version", version.Info())
 	level.Info(logger).Log("msg", "Build context", "build_context", version.BuildContext())
 
-	var err error
-	if err = c.ReloadConfig(*configMycnf, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
+	target, err := mysqldTarget(*mysqldAddress, mysqldAddressSet, *mysqldSocket)
+	if err != nil {
+		level.Error(logger).Log("msg", "Invalid connection flags", "err", err)
+		os.Exit(1)
+	}
+	if err = c.ReloadConfig(*configMycnf, target, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
 		level.Info(logger).Log("msg", "Error parsing host config", "file", *configMycnf, "err", err)
 		os.Exit(1)
 	}
 
 	// Register only scrapers enabled by flag.
-	enabledScrapers := []collector.Scraper{}
-	for scraper, enabled := range scraperFlags {
-		if *enabled {
-			level.Info(logger).Log("msg", "Scraper enabled", "scraper", scraper.Name())
-			enabledScrapers = append(enabledScrapers, scraper)
-		}
+	enabledScrapers, allScrapers := splitScrapers(scraperFlags)
+	for _, scraper := range enabledScrapers {
+		level.Info(logger).Log("msg", "Scraper enabled", "scraper", scraper.Name())
 	}
-	handlerFunc := newHandler(enabledScrapers, logger)
+	handlerFunc := newHandler(enabledScrapers, allScrapers, logger)
 	http.Handle(*metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc))
 	if *metricsPath != "/" && *metricsPath != "" {
 		landingConfig := web.LandingConfig{
@@ -255,17 +487,33 @@ func main() {
 		}
 		http.Handle("/", landingPage)
 	}
-	http.HandleFunc("/probe", handleProbe(enabledScrapers, logger))
+	http.HandleFunc("/probe", handleProbe(enabledScrapers, allScrapers, logger))
+	http.HandleFunc("/scrapers", handleScrapers(scraperFlags, logger))
+	http.HandleFunc("/-/ready", handleReady(logger))
 	http.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
-		if err = c.ReloadConfig(*configMycnf, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
+		if err = c.ReloadConfig(*configMycnf, target, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
 			level.Warn(logger).Log("msg", "Error reloading host config", "file", *configMycnf, "error", err)
 			return
 		}
 		_, _ = w.Write([]byte(`ok`))
 	})
 	srv := &http.Server{}
-	if err := web.ListenAndServe(srv, toolkitFlags, logger); err != nil {
+	go func() {
+		term := make(chan os.Signal, 1)
+		signal.Notify(term, os.Interrupt, syscall.SIGTERM)
+		<-term
+		level.Info(logger).Log("msg", "Shutting down, waiting for running scrapes", "timeout", shutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			level.Warn(logger).Log("msg", "Error shutting down HTTP server", "err", err)
+		}
+	}()
+	if err := web.ListenAndServe(srv, toolkitFlags, logger); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		level.Error(logger).Log("msg", "Error starting HTTP server", "err", err)
 		os.Exit(1)
 	}
+	if err := collector.Close(); err != nil {
+		level.Warn(logger).Log("msg", "Error closing collectors", "err", err)
+	}
 }
@@ -19,6 +19,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
@@ -29,6 +30,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
 	"github.com/google/go-cmp/cmp"
 	"github.com/prometheus/mysqld_exporter/collector"
 )
@@ -261,46 +264,118 @@ func getBody(urlToGet string) ([]byte, error) {
 
 func Test_filterScrapers(t *testing.T) {
 	type args struct {
-		scrapers      []collector.Scraper
+		enabled       []collector.Scraper
+		all           []collector.Scraper
 		collectParams []string
 	}
+	all := []collector.Scraper{
+		collector.ScrapeGlobalStatus{},
+		collector.ScrapeGlobalVariables{},
+		collector.ScrapeHeartbeat{},
+	}
 	tests := []struct {
-		name string
-		args args
-		want []collector.Scraper
+		name    string
+		args    args
+		want    []collector.Scraper
+		wantErr string
 	}{
+		{"no_params_uses_enabled",
+			args{
+				[]collector.Scraper{collector.ScrapeGlobalStatus{}},
+				all,
+				nil,
+			},
+			[]collector.Scraper{collector.ScrapeGlobalStatus{}},
+			""},
 		{"args_appears_in_collector",
 			args{
 				[]collector.Scraper{collector.ScrapeGlobalStatus{}},
+				all,
 				[]string{collector.ScrapeGlobalStatus{}.Name()},
 			},
 			[]collector.Scraper{
 				collector.ScrapeGlobalStatus{},
-			}},
-		{"args_absent_in_collector",
-			args{
-				[]collector.Scraper{collector.ScrapeGlobalStatus{}},
-				[]string{collector.ScrapeGlobalVariables{}.Name()},
 			},
-			[]collector.Scraper{collector.ScrapeGlobalStatus{}}},
-		{"respect_params",
+			""},
+		{"params_override_enabled",
 			args{
-				[]collector.Scraper{
-					collector.ScrapeGlobalStatus{},
-					collector.ScrapeGlobalVariables{},
-				},
-				[]string{collector.ScrapeGlobalStatus{}.Name()},
+				[]collector.Scraper{collector.ScrapeGlobalStatus{}},
+				all,
+				[]string{collector.ScrapeHeartbeat{}.Name(), collector.ScrapeGlobalVariables{}.Name()},
 			},
 			[]collector.Scraper{
-				collector.ScrapeGlobalStatus{},
+				collector.ScrapeGlobalVariables{},
+				collector.ScrapeHeartbeat{},
+			},
+			""},
+		{"unknown_params",
+			args{
+				[]collector.Scraper{collector.ScrapeGlobalStatus{}},
+				all,
+				[]string{collector.ScrapeGlobalStatus{}.Name(), "nope"},
 			},
-		},
+			nil,
+			"unknown collectors: nope; valid collectors: global_status, global_variables, heartbeat"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := filterScrapers(tt.args.scrapers, tt.args.collectParams); !reflect.DeepEqual(got, tt.want) {
+			got, err := filterScrapers(tt.args.enabled, tt.args.all, tt.args.collectParams)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("filterScrapers() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filterScrapers() unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("filterScrapers() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestMysqldTarget(t *testing.T) {
+	target, err := mysqldTarget("localhost:3306", false, "")
+	if err != nil || target != "localhost:3306" {
+		t.Fatalf("expected localhost:3306, got %q, %v", target, err)
+	}
+	target, err = mysqldTarget("localhost:3306", false, "/var/run/mysqld/mysqld.sock")
+	if err != nil || target != "unix:///var/run/mysqld/mysqld.sock" {
+		t.Fatalf("expected unix:///var/run/mysqld/mysqld.sock, got %q, %v", target, err)
+	}
+	if _, err := mysqldTarget("db:3306", true, "/var/run/mysqld/mysqld.sock"); err == nil {
+		t.Fatal("expected an error for both an address and a socket")
+	}
+}
+
+func TestScrapeContext(t *testing.T) {
+	if _, err := kingpin.CommandLine.Parse([]string{"--timeout-offset=0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	defer kingpin.CommandLine.Parse([]string{})
+	logger := log.NewNopLogger()
+
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	r.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "10")
+	ctx, cancel := scrapeContext(r, logger)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected a deadline with a scrape timeout header")
+	}
+	if left := time.Until(deadline); left > 9500*time.Millisecond || left < 9*time.Second {
+		t.Errorf("expected the timeout minus the offset, got %s", left)
+	}
+
+	for _, header := range []string{"", "soon"} {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		r.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", header)
+		ctx, cancel := scrapeContext(r, logger)
+		if _, ok := ctx.Deadline(); ok {
+			t.Errorf("expected no deadline for header %q", header)
+		}
+		cancel()
+	}
+}
@@ -22,11 +22,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
 )
 
-func handleProbe(scrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
+func handleProbe(enabledScrapers, allScrapers []collector.Scraper, logger log.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
 		params := r.URL.Query()
 		target := params.Get("target")
 		if target == "" {
@@ -35,13 +35,17 @@ func handleProbe(scrapers []collector.Scraper, logger log.Logger) http.HandlerFu
 		}
 		collectParams := r.URL.Query()["collect[]"]
 
+		cfg := c.GetConfig()
 		authModule := params.Get("auth_module")
+		var (
+			cfgsection config.MySqlConfig
+			ok         bool
+		)
 		if authModule == "" {
-			authModule = "client"
+			authModule, cfgsection, ok = cfg.DefaultSection()
+		} else {
+			cfgsection, ok = cfg.Sections[authModule]
 		}
-
-		cfg := c.GetConfig()
-		cfgsection, ok := cfg.Sections[authModule]
 		if !ok {
 			level.Error(logger).Log("msg", fmt.Sprintf("Could not find section [%s] from config file", authModule))
 			http.Error(w, fmt.Sprintf("Could not find config section [%s]", authModule), http.StatusBadRequest)
@@ -54,7 +58,15 @@ func handleProbe(scrapers []collector.Scraper, logger log.Logger) http.HandlerFu
 			return
 		}
 
-		filteredScrapers := filterScrapers(scrapers, collectParams)
+		filteredScrapers, err := filterScrapers(enabledScrapers, allScrapers, collectParams)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := scrapeContext(r, logger)
+		defer cancel()
+		r = r.WithContext(ctx)
 
 		registry := prometheus.NewRegistry()
 		registry.MustRegister(collector.New(ctx, dsn, filteredScrapers, logger))
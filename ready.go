@@ -0,0 +1,59 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// handleReady returns 200 when the MySQL server configured in the default
+// section answers queries and 503 otherwise.
+func handleReady(logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := c.GetConfig()
+		name, cfgsection, ok := cfg.DefaultSection()
+		if !ok {
+			level.Error(logger).Log("msg", fmt.Sprintf("Failed to parse section [%s] from config file", name))
+			http.Error(w, fmt.Sprintf("Could not find config section [%s]", name), http.StatusServiceUnavailable)
+			return
+		}
+		dsn, err := cfgsection.FormDSN("")
+		if err != nil {
+			level.Error(logger).Log("msg", fmt.Sprintf("Failed to form dsn from section [%s]", name), "err", err)
+			http.Error(w, fmt.Sprintf("Error forming dsn from config section [%s]", name), http.StatusServiceUnavailable)
+			return
+		}
+
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			level.Error(logger).Log("msg", "Error opening connection to database", "err", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer db.Close()
+
+		if err := collector.PingDatabase(r.Context(), db); err != nil {
+			level.Warn(logger).Log("msg", "Readiness check failed", "err", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`ok`))
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"gopkg.in/yaml.v2"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// secretArgs are substrings of arg names whose values are redacted when the
+// configuration is dumped.
+var secretArgs = []string{"password", "secret", "token"}
+
+// scraperMetadata describes a scraper and its current configuration.
+type scraperMetadata struct {
+	Name    string            `json:"name"`
+	Help    string            `json:"help"`
+	Version float64           `json:"version"`
+	Enabled bool              `json:"enabled"`
+	Args    map[string]string `json:"args,omitempty"`
+}
+
+// scraperArgFlags returns the flags below the "collect.<name>." prefix of a
+// scraper, leaving out the flags enabling other scrapers such as
+// collect.slave_status.relay_log_space.
+func scraperArgFlags(name string, scraperFlags map[collector.Scraper]*bool, flags []*kingpin.FlagModel) []*kingpin.FlagModel {
+	enableFlags := make(map[string]bool, len(scraperFlags))
+	for scraper := range scraperFlags {
+		enableFlags["collect."+scraper.Name()] = true
+	}
+	prefix := "collect." + name + "."
+	var args []*kingpin.FlagModel
+	for _, f := range flags {
+		if strings.HasPrefix(f.Name, prefix) && !enableFlags[f.Name] {
+			args = append(args, f)
+		}
+	}
+	return args
+}
+
+// scrapersMetadata lists all scrapers sorted by name. The args of a scraper
+// are the flags below its "collect.<name>." prefix.
+func scrapersMetadata(scraperFlags map[collector.Scraper]*bool, flags []*kingpin.FlagModel) []scraperMetadata {
+	metadata := make([]scraperMetadata, 0, len(scraperFlags))
+	for scraper, enabled := range scraperFlags {
+		m := scraperMetadata{
+			Name:    scraper.Name(),
+			Help:    scraper.Help(),
+			Version: scraper.Version(),
+			Enabled: *enabled,
+		}
+		prefix := "collect." + scraper.Name() + "."
+		for _, f := range scraperArgFlags(scraper.Name(), scraperFlags, flags) {
+			if m.Args == nil {
+				m.Args = map[string]string{}
+			}
+			m.Args[strings.TrimPrefix(f.Name, prefix)] = f.Value.String()
+		}
+		metadata = append(metadata, m)
+	}
+	sort.Slice(metadata, func(i, j int) bool {
+		return metadata[i].Name < metadata[j].Name
+	})
+	return metadata
+}
+
+// scrapersMetadataJSON serializes the metadata of all scrapers.
+func scrapersMetadataJSON(scraperFlags map[collector.Scraper]*bool, flags []*kingpin.FlagModel) ([]byte, error) {
+	return json.Marshal(scrapersMetadata(scraperFlags, flags))
+}
+
+func handleScrapers(scraperFlags map[collector.Scraper]*bool, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := scrapersMetadataJSON(scraperFlags, kingpin.CommandLine.Model().Flags)
+		if err != nil {
+			level.Error(logger).Log("msg", "Failed to serialize scrapers metadata", "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+}
+
+// scraperConfig is the reproducible configuration of a scraper.
+type scraperConfig struct {
+	Name    string            `yaml:"name"`
+	Enabled bool              `yaml:"enabled"`
+	Args    map[string]string `yaml:"args,omitempty"`
+}
+
+// dumpConfig serializes the enabled state and args of all scrapers to YAML,
+// redacting args that look like secrets.
+func dumpConfig(scraperFlags map[collector.Scraper]*bool, flags []*kingpin.FlagModel) ([]byte, error) {
+	metadata := scrapersMetadata(scraperFlags, flags)
+	configs := make([]scraperConfig, 0, len(metadata))
+	for _, m := range metadata {
+		for name := range m.Args {
+			for _, secret := range secretArgs {
+				if strings.Contains(strings.ToLower(name), secret) {
+					m.Args[name] = "<secret>"
+				}
+			}
+		}
+		configs = append(configs, scraperConfig{Name: m.Name, Enabled: m.Enabled, Args: m.Args})
+	}
+	return yaml.Marshal(configs)
+}
+
+// validateScrapers reports all scrapers sharing a name at once. Scrapers are
+// identified by name in flags and collect[] parameters, so names must be
+// unique.
+func validateScrapers(scrapers []collector.Scraper) error {
+	counts := map[string]int{}
+	for _, scraper := range scrapers {
+		counts[scraper.Name()]++
+	}
+	var conflicts []string
+	for name, count := range counts {
+		if count > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%d scrapers)", name, count))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Errorf("conflicting scraper names: %s", strings.Join(conflicts, ", "))
+}
+
+// addScraperFlags adds the collect.<name> flags enabling each scraper to app.
+// Unlike kingpin, which fails on duplicate flags while parsing, it reports
+// conflicting scrapers and flags as an error.
+func addScraperFlags(app *kingpin.Application, scrapers map[collector.Scraper]bool) (map[collector.Scraper]*bool, error) {
+	all := make([]collector.Scraper, 0, len(scrapers))
+	for scraper := range scrapers {
+		all = append(all, scraper)
+	}
+	if err := validateScrapers(all); err != nil {
+		return nil, err
+	}
+	for _, scraper := range all {
+		if app.GetFlag("collect."+scraper.Name()) != nil {
+			return nil, fmt.Errorf("scraper %s conflicts with flag collect.%s", scraper.Name(), scraper.Name())
+		}
+	}
+
+	scraperFlags := make(map[collector.Scraper]*bool, len(scrapers))
+	for scraper, enabledByDefault := range scrapers {
+		defaultOn := "false"
+		if enabledByDefault {
+			defaultOn = "true"
+		}
+		help := scraper.Help()
+		if st, ok := scraper.(collector.Stabilizer); ok && st.Stability() == collector.StabilityExperimental {
+			help += " (experimental)"
+		}
+		setByUser := new(bool)
+		scraperFlags[scraper] = app.Flag(
+			"collect."+scraper.Name(),
+			help,
+		).Default(defaultOn).IsSetByUser(setByUser).Bool()
+		scraperFlagsSetByUser[scraperFlags[scraper]] = setByUser
+	}
+	return scraperFlags, nil
+}
+
+// scraperFlagsSetByUser tells for the flags returned by addScraperFlags
+// whether they were set on the command line.
+var scraperFlagsSetByUser = map[*bool]*bool{}
+
+// splitScrapers returns the enabled and all scrapers of the flags returned by
+// addScraperFlags, each sorted for scraping. As the flags belong to the app
+// they were added to, each app configures its own set of scrapers.
+func splitScrapers(scraperFlags map[collector.Scraper]*bool) (enabled, all []collector.Scraper) {
+	enabled = []collector.Scraper{}
+	all = make([]collector.Scraper, 0, len(scraperFlags))
+	for scraper, on := range scraperFlags {
+		if *on {
+			enabled = append(enabled, scraper)
+		}
+		all = append(all, scraper)
+	}
+	collector.SortScrapers(enabled)
+	collector.SortScrapers(all)
+	return enabled, all
+}
+
+// setScrapersMatching enables or disables all scrapers whose name fully
+// matches pattern and returns the number of scrapers changed. Scrapers whose
+// collect.<name> flag was set on the command line keep its value, so that an
+// explicit flag takes precedence over a pattern.
+func setScrapersMatching(scraperFlags map[collector.Scraper]*bool, pattern string, enabled bool) (int, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return 0, fmt.Errorf("invalid scraper pattern %q: %w", pattern, err)
+	}
+	matched := 0
+	for scraper, on := range scraperFlags {
+		if set := scraperFlagsSetByUser[on]; set != nil && *set {
+			continue
+		}
+		if re.MatchString(scraper.Name()) {
+			*on = enabled
+			matched++
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,318 @@
+// Copyright 2023 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+func TestScrapersMetadataJSON(t *testing.T) {
+	app := kingpin.New("test", "")
+	app.Flag("collect.heartbeat.database", "").Default("heartbeat").String()
+	app.Flag("collect.heartbeat.utc", "").Bool()
+	app.Flag("collect.global_variables.session_buffers", "").Bool()
+	if _, err := app.Parse([]string{"--collect.heartbeat.database=hb"}); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled, disabled := true, false
+	scraperFlags := map[collector.Scraper]*bool{
+		collector.ScrapeHeartbeat{}:       &disabled,
+		collector.ScrapeGlobalStatus{}:    &enabled,
+		collector.ScrapeGlobalVariables{}: &enabled,
+		collector.ScrapeSessionBuffers{}:  &disabled,
+	}
+
+	got, err := scrapersMetadataJSON(scraperFlags, app.Model().Flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[` +
+		`{"name":"global_status","help":"Collect from SHOW GLOBAL STATUS","version":5.1,"enabled":true},` +
+		`{"name":"global_variables","help":"Collect from SHOW GLOBAL VARIABLES","version":5.1,"enabled":true},` +
+		`{"name":"global_variables.session_buffers","help":"Collect the sizes of per-session sort, join and temporary table buffers","version":5.1,"enabled":false},` +
+		`{"name":"heartbeat","help":"Collect from heartbeat","version":5.1,"enabled":false,"args":{"database":"hb","utc":"false"}}` +
+		`]`
+	if diff := cmp.Diff(expected, string(got)); diff != "" {
+		t.Fatalf("expected != got \n%v\n", diff)
+	}
+}
+
+func TestDumpConfig(t *testing.T) {
+	app := kingpin.New("test", "")
+	app.Flag("collect.heartbeat.database", "").Default("heartbeat").String()
+	app.Flag("collect.heartbeat.table", "").Default("heartbeat").String()
+	app.Flag("collect.heartbeat.utc", "").Bool()
+	app.Flag("collect.heartbeat.password", "").String()
+	if _, err := app.Parse([]string{"--collect.heartbeat.table=hb", "--collect.heartbeat.utc", "--collect.heartbeat.password=hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := true
+	scraperFlags := map[collector.Scraper]*bool{
+		collector.ScrapeHeartbeat{}: &enabled,
+	}
+
+	out, err := dumpConfig(scraperFlags, app.Model().Flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []scraperConfig
+	if err := yaml.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	expected := []scraperConfig{
+		{
+			Name:    "heartbeat",
+			Enabled: true,
+			Args: map[string]string{
+				"database": "heartbeat",
+				"table":    "hb",
+				"utc":      "true",
+				"password": "<secret>",
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("expected != got \n%v\n", diff)
+	}
+}
+
+// namedScraper is a heartbeat scraper with a different name.
+type namedScraper struct {
+	collector.ScrapeHeartbeat
+	name string
+}
+
+func (s namedScraper) Name() string {
+	return s.name
+}
+
+func TestValidateScrapers(t *testing.T) {
+	if err := validateScrapers([]collector.Scraper{
+		collector.ScrapeGlobalStatus{},
+		collector.ScrapeHeartbeat{},
+	}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := validateScrapers([]collector.Scraper{
+		collector.ScrapeGlobalStatus{},
+		collector.ScrapeHeartbeat{},
+		namedScraper{name: "heartbeat"},
+		namedScraper{name: "global_status"},
+		namedScraper{name: "global_status"},
+		namedScraper{name: "slave_hosts"},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "conflicting scraper names: global_status (3 scrapers), heartbeat (2 scrapers)"
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Fatalf("expected != got \n%v\n", diff)
+	}
+}
+
+func TestAddScraperFlags(t *testing.T) {
+	app := kingpin.New("test", "")
+	scraperFlags, err := addScraperFlags(app, map[collector.Scraper]bool{
+		collector.ScrapeGlobalStatus{}: true,
+		collector.ScrapeHeartbeat{}:    false,
+		collector.ScrapeUptime{}:       false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Parse([]string{"--no-collect.global_status", "--collect.heartbeat"}); err != nil {
+		t.Fatal(err)
+	}
+	if *scraperFlags[collector.ScrapeGlobalStatus{}] || !*scraperFlags[collector.ScrapeHeartbeat{}] {
+		t.Fatalf("unexpected flag values %v", scraperFlags)
+	}
+	if help := app.GetFlag("collect.heartbeat").Model().Help; help != (collector.ScrapeHeartbeat{}).Help() {
+		t.Fatalf("unexpected help %q for a stable scraper", help)
+	}
+	if help := app.GetFlag("collect.global_status.uptime").Model().Help; !strings.HasSuffix(help, " (experimental)") {
+		t.Fatalf("expected help %q of an experimental scraper to say so", help)
+	}
+
+	// Scrapers without args have no flags below their prefix.
+	metadata := scrapersMetadata(scraperFlags, app.Model().Flags)
+	for _, m := range metadata {
+		if m.Args != nil {
+			t.Fatalf("expected no args for %s, got %v", m.Name, m.Args)
+		}
+	}
+	if args := scraperArgFlags("heartbeat", scraperFlags, nil); args != nil {
+		t.Fatalf("expected no args, got %v", args)
+	}
+
+	app = kingpin.New("test", "")
+	app.Flag("collect.heartbeat", "").Bool()
+	if _, err := addScraperFlags(app, map[collector.Scraper]bool{collector.ScrapeHeartbeat{}: false}); err == nil {
+		t.Fatal("expected an error for a conflicting flag")
+	}
+}
+
+func TestSplitScrapersIndependentApps(t *testing.T) {
+	scrapers := map[collector.Scraper]bool{
+		collector.ScrapeGlobalStatus{}: true,
+		collector.ScrapeHeartbeat{}:    false,
+	}
+	first, second := kingpin.New("first", ""), kingpin.New("second", "")
+	firstFlags, err := addScraperFlags(first, scrapers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondFlags, err := addScraperFlags(second, scrapers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Parse([]string{"--no-collect.global_status", "--collect.heartbeat"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(scrapers []collector.Scraper) []string {
+		names := []string{}
+		for _, scraper := range scrapers {
+			names = append(names, scraper.Name())
+		}
+		return names
+	}
+	for _, tt := range []struct {
+		scraperFlags map[collector.Scraper]*bool
+		enabled      []string
+	}{
+		{firstFlags, []string{"global_status"}},
+		{secondFlags, []string{"heartbeat"}},
+	} {
+		enabled, all := splitScrapers(tt.scraperFlags)
+		if diff := cmp.Diff(tt.enabled, names(enabled)); diff != "" {
+			t.Fatalf("expected != got \n%v\n", diff)
+		}
+		if len(all) != len(scrapers) {
+			t.Fatalf("expected %d scrapers, got %d", len(scrapers), len(all))
+		}
+	}
+}
+
+func TestSetScrapersMatching(t *testing.T) {
+	app := kingpin.New("test", "")
+	scraperFlags, err := addScraperFlags(app, map[collector.Scraper]bool{
+		collector.ScrapePerfMemoryEvents{}: false,
+		collector.ScrapePerfTableIOWaits{}: false,
+		collector.ScrapePerfFileEvents{}:   false,
+		collector.ScrapeGlobalStatus{}:     true,
+		collector.ScrapeReplicaHost{}:      false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		pattern string
+		enabled bool
+		matched int
+	}{
+		{`perf_schema\..*`, true, 3},
+		{`perf_schema\.memory_events`, false, 1},
+		// Patterns match whole names only.
+		{`global`, false, 0},
+	} {
+		matched, err := setScrapersMatching(scraperFlags, tt.pattern, tt.enabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != tt.matched {
+			t.Fatalf("expected %q to match %d scrapers, got %d", tt.pattern, tt.matched, matched)
+		}
+	}
+
+	enabled, _ := splitScrapers(scraperFlags)
+	got := map[string]bool{}
+	for _, scraper := range enabled {
+		got[scraper.Name()] = true
+	}
+	expected := map[string]bool{
+		"global_status":            true,
+		"perf_schema.tableiowaits": true,
+		"perf_schema.file_events":  true,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("expected != got \n%v\n", diff)
+	}
+
+	if _, err := setScrapersMatching(scraperFlags, "perf_(", true); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestSetScrapersMatchingKeepsExplicitFlags(t *testing.T) {
+	app := kingpin.New("test", "")
+	scraperFlags, err := addScraperFlags(app, map[collector.Scraper]bool{
+		collector.ScrapePerfMemoryEvents{}: false,
+		collector.ScrapePerfTableIOWaits{}: false,
+		collector.ScrapePerfFileEvents{}:   false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Parse([]string{"--no-collect.perf_schema.memory_events", "--collect.perf_schema.file_events"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		pattern string
+		enabled bool
+		changed int
+	}{
+		{`perf_schema\..*`, true, 1},
+		{`perf_schema\.file_events`, false, 0},
+	} {
+		changed, err := setScrapersMatching(scraperFlags, tt.pattern, tt.enabled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != tt.changed {
+			t.Fatalf("expected %q to change %d scrapers, got %d", tt.pattern, tt.changed, changed)
+		}
+	}
+
+	enabled, _ := splitScrapers(scraperFlags)
+	got := map[string]bool{}
+	for _, scraper := range enabled {
+		got[scraper.Name()] = true
+	}
+	expected := map[string]bool{
+		"perf_schema.tableiowaits": true,
+		"perf_schema.file_events":  true,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("expected != got \n%v\n", diff)
+	}
+}